@@ -0,0 +1,75 @@
+package main
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func listJobs(ctx context.Context, args []string) error {
+	fs := newFlagSet("jobs list")
+	var filter entity.JobFilter
+	fs.StringVar(&filter.Kind, "kind", "", "job kind")
+	status := fs.String("status", "", "pending, running, done or dead")
+	fs.IntVar(&filter.Limit, "limit", 50, "maximum number of jobs")
+	fs.Parse(args)
+	filter.Status = entity.JobStatus(*status)
+
+	return withServices(func(services *service.Service) error {
+		jobs, err := services.Jobs.ListJobs(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tKIND\tSTATUS\tATTEMPTS\tRUN AT\tLAST ERROR")
+		for _, job := range jobs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%s\t%s\n",
+				job.ID, job.Kind, job.Status, job.Attempts, job.MaxAttempts,
+				job.RunAt.Format(time.DateTime), job.LastError)
+		}
+		return w.Flush()
+	})
+}
+
+func jobStats(ctx context.Context, args []string) error {
+	fs := newFlagSet("jobs stats")
+	fs.Parse(args)
+
+	return withServices(func(services *service.Service) error {
+		counts, err := services.Jobs.CountJobs(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "KIND\tSTATUS\tCOUNT")
+		for _, c := range counts {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", c.Kind, c.Status, c.Count)
+		}
+		return w.Flush()
+	})
+}
+
+func retryJob(ctx context.Context, args []string) error {
+	fs := newFlagSet("jobs retry")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errors.New("pass the IDs of the dead jobs to retry")
+	}
+
+	return withServices(func(services *service.Service) error {
+		for _, id := range fs.Args() {
+			if err := services.Jobs.RequeueJob(ctx, id); err != nil {
+				return fmt.Errorf("job %s: %w", id, err)
+			}
+			fmt.Printf("requeued %s\n", id)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,114 @@
+// Command lafctl runs maintenance tasks against the same databases and
+// configuration as the server.
+package main
+
+import (
+	"LostAndFound/internal/auth"
+	"LostAndFound/internal/bootstrap"
+	"LostAndFound/internal/service"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `Usage: lafctl <command> [flags]
+
+Commands:
+  migrate up|down|status     apply, revert or show database migrations
+  admin create               create an administrator
+  admin promote|demote       grant or revoke administrator rights
+  user ban|unban             ban a user or lift the ban
+  search reindex             hash unindexed images and drop search caches
+  files purge                delete stored files nothing refers to
+  files policy               make public uploads readable in the bucket policy
+  export                     export users and cards as JSON lines
+  jobs list|stats|retry      inspect the background job queue
+
+Run "lafctl <command> -h" for the flags of a command.
+`
+
+type command struct {
+	name string
+	run  func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"migrate up", migrateUp},
+	{"migrate down", migrateDown},
+	{"migrate status", migrateStatus},
+	{"admin create", createAdmin},
+	{"admin promote", setAdmin(true)},
+	{"admin demote", setAdmin(false)},
+	{"user ban", banUser(true)},
+	{"user unban", banUser(false)},
+	{"search reindex", reindexSearch},
+	{"files purge", purgeFiles},
+	{"files policy", setBucketPolicy},
+	{"export", exportData},
+	{"jobs list", listJobs},
+	{"jobs stats", jobStats},
+	{"jobs retry", retryJob},
+}
+
+func main() {
+	// The .env file is optional here: the tool is also run where the
+	// environment is set up by other means.
+	_ = godotenv.Load(".env")
+
+	cmd, args := findCommand(os.Args[1:])
+	if cmd == nil {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.run(ctx, args); err != nil {
+		slog.Error(cmd.name+" failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// findCommand matches the longest command name at the start of args.
+func findCommand(args []string) (*command, []string) {
+	for n := min(len(args), 2); n > 0; n-- {
+		name := args[0]
+		if n == 2 {
+			name += " " + args[1]
+		}
+		for i := range commands {
+			if commands[i].name == name {
+				return &commands[i], args[n:]
+			}
+		}
+	}
+	return nil, nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("lafctl "+name, flag.ExitOnError)
+}
+
+// withServices loads the configuration and the services the way the server
+// does and calls fn with them.
+func withServices(fn func(services *service.Service) error) error {
+	app, err := bootstrap.Load()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	tokenManager, err := auth.NewTokenManager(app.Deps.CacheRepo)
+	if err != nil {
+		return fmt.Errorf("failed to initialize token manager: %w", err)
+	}
+
+	return fn(service.NewService(app.Deps, tokenManager, app.Config))
+}
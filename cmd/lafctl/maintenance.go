@@ -0,0 +1,96 @@
+package main
+
+import (
+	mys3 "LostAndFound/internal/adapters/s3"
+	sc "LostAndFound/internal/config/storage_config"
+	"LostAndFound/internal/service"
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+func reindexSearch(ctx context.Context, args []string) error {
+	fs := newFlagSet("search reindex")
+	fs.Parse(args)
+
+	return withServices(func(services *service.Service) error {
+		cards, images, err := services.Cards.ReindexCards(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reindexed %d cards, hashed %d images\n", cards, images)
+		return nil
+	})
+}
+
+func purgeFiles(ctx context.Context, args []string) error {
+	fs := newFlagSet("files purge")
+	minAge := fs.Duration("min-age", 24*time.Hour, "keep files younger than this, they may belong to a card being created")
+	dryRun := fs.Bool("dry-run", false, "only list the files")
+	fs.Parse(args)
+
+	return withServices(func(services *service.Service) error {
+		keys, err := services.Files.PurgeOrphanedFiles(ctx, *minAge, *dryRun)
+		for _, key := range keys {
+			fmt.Println(key)
+		}
+		if err != nil {
+			return err
+		}
+
+		verb := "deleted"
+		if *dryRun {
+			verb = "would delete"
+		}
+		fmt.Fprintf(os.Stderr, "%s %d files\n", verb, len(keys))
+		return nil
+	})
+}
+
+// setBucketPolicy adds the public read statement to the bucket policy. It is
+// run with credentials allowed to change the policy, which the server does
+// not need otherwise.
+func setBucketPolicy(ctx context.Context, args []string) error {
+	fs := newFlagSet("files policy")
+	fs.Parse(args)
+
+	cfg, err := sc.MustLoadStorageConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load storage config: %w", err)
+	}
+	client, err := mys3.NewS3Client(cfg.S3)
+	if err != nil {
+		return fmt.Errorf("failed to connect to s3: %w", err)
+	}
+	if err = mys3.SetPublicReadPolicy(ctx, client, cfg.S3.Bucket); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "public read policy is set on %s\n", cfg.S3.Bucket)
+	return nil
+}
+
+func exportData(ctx context.Context, args []string) error {
+	fs := newFlagSet("export")
+	out := fs.String("o", "", "output file, stdout by default")
+	fs.Parse(args)
+
+	return withServices(func(services *service.Service) error {
+		w := os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		buf := bufio.NewWriter(w)
+		if err := services.Admin.ExportData(ctx, buf); err != nil {
+			return err
+		}
+		return buf.Flush()
+	})
+}
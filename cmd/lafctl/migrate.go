@@ -0,0 +1,101 @@
+package main
+
+import (
+	"LostAndFound/internal/adapters/postgres"
+	storage_config "LostAndFound/internal/config/storage_config"
+	"LostAndFound/migrations"
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// migrationsDirFlag adds the -dir flag. Migrations only need the database,
+// so they do not load the rest of the configuration.
+func migrationsDirFlag(flags *flag.FlagSet) *string {
+	return flags.String("dir", "", "directory with the migration scripts instead of the embedded ones")
+}
+
+func withMigrator(dir string, fn func(m *postgres.Migrator) error) error {
+	cfg, err := storage_config.MustLoadStorageConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := postgres.NewStorage(cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	defer db.Close()
+
+	var source fs.FS = migrations.FS
+	if dir != "" {
+		source = os.DirFS(dir)
+	}
+
+	m, err := postgres.NewMigrator(db, source)
+	if err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func migrateUp(ctx context.Context, args []string) error {
+	flags := newFlagSet("migrate up")
+	dir := migrationsDirFlag(flags)
+	flags.Parse(args)
+
+	return withMigrator(*dir, func(m *postgres.Migrator) error {
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Printf("applied %06d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return err
+	})
+}
+
+func migrateDown(ctx context.Context, args []string) error {
+	flags := newFlagSet("migrate down")
+	dir := migrationsDirFlag(flags)
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+	flags.Parse(args)
+
+	if *steps <= 0 {
+		return fmt.Errorf("-steps must be positive")
+	}
+
+	return withMigrator(*dir, func(m *postgres.Migrator) error {
+		reverted, err := m.Down(ctx, *steps)
+		for _, mig := range reverted {
+			fmt.Printf("reverted %06d_%s\n", mig.Version, mig.Name)
+		}
+		return err
+	})
+}
+
+func migrateStatus(ctx context.Context, args []string) error {
+	flags := newFlagSet("migrate status")
+	dir := migrationsDirFlag(flags)
+	flags.Parse(args)
+
+	return withMigrator(*dir, func(m *postgres.Migrator) error {
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("version: %d", status.Version)
+		if status.Dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Printf("\nlatest:  %d\n", status.Latest)
+		for _, mig := range status.Pending {
+			fmt.Printf("pending %06d_%s\n", mig.Version, mig.Name)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	v "LostAndFound/internal/common/validation"
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/delivery/http/mapper"
+	"LostAndFound/internal/service"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func createAdmin(ctx context.Context, args []string) error {
+	fs := newFlagSet("admin create")
+	var req dto.UserRegisterRequest
+	fs.StringVar(&req.Email, "email", "", "email")
+	fs.StringVar(&req.Password, "password", "", "password")
+	fs.StringVar(&req.Name, "name", "", "first name")
+	fs.StringVar(&req.Surname, "surname", "", "last name")
+	fs.StringVar(&req.Phone, "phone", "", "phone")
+	fs.StringVar(&req.Telegram, "telegram", "", "telegram username")
+	fs.Parse(args)
+
+	// The same rules as for registration through the API.
+	if err := validator.New().Struct(req); err != nil {
+		return errors.New(v.FormatValidationError(err))
+	}
+
+	return withServices(func(services *service.Service) error {
+		user := mapper.ToUserEntity(req)
+		if err := services.Admin.CreateAdmin(ctx, user); err != nil {
+			return err
+		}
+		fmt.Printf("created administrator %s (%s)\n", user.Email, user.ID)
+		return nil
+	})
+}
+
+func setAdmin(isAdmin bool) func(ctx context.Context, args []string) error {
+	return func(ctx context.Context, args []string) error {
+		name := "admin demote"
+		if isAdmin {
+			name = "admin promote"
+		}
+		fs := newFlagSet(name)
+		email := fs.String("email", "", "email of the user")
+		fs.Parse(args)
+
+		if *email == "" {
+			return errors.New("-email is required")
+		}
+
+		return withServices(func(services *service.Service) error {
+			if err := services.Admin.SetAdmin(ctx, *email, isAdmin); err != nil {
+				return err
+			}
+			fmt.Println("done, the role changes with the next login")
+			return nil
+		})
+	}
+}
+
+func banUser(ban bool) func(ctx context.Context, args []string) error {
+	return func(ctx context.Context, args []string) error {
+		name := "user unban"
+		if ban {
+			name = "user ban"
+		}
+		fs := newFlagSet(name)
+		email := fs.String("email", "", "email of the user")
+		fs.Parse(args)
+
+		if *email == "" {
+			return errors.New("-email is required")
+		}
+
+		return withServices(func(services *service.Service) error {
+			if ban {
+				return services.Admin.BanUser(ctx, *email)
+			}
+			return services.Admin.UnbanUser(ctx, *email)
+		})
+	}
+}
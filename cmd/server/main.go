@@ -1,15 +1,12 @@
 package main
 
 import (
-	"LostAndFound/internal/adapters/postgres"
-	myredis "LostAndFound/internal/adapters/redis"
-	mys3 "LostAndFound/internal/adapters/s3"
+	"LostAndFound/internal/adapters/telegram"
 	"LostAndFound/internal/auth"
 	"LostAndFound/internal/bootstrap"
-	server_config "LostAndFound/internal/config/server_config"
-	storage_config "LostAndFound/internal/config/storage_config"
 	router "LostAndFound/internal/delivery/http"
 	"LostAndFound/internal/delivery/http/handler"
+	tgbot "LostAndFound/internal/delivery/telegram"
 	"LostAndFound/internal/service"
 	"context"
 	"errors"
@@ -40,85 +37,91 @@ func init() {
 // @name Authorization
 func main() {
 
-	serverCfg, err := server_config.MustLoadServerConfig()
+	app, err := bootstrap.Load()
 	if err != nil {
-		slog.Error("Error loading server config")
+		slog.Error("failed to start", "error", err)
 		os.Exit(1)
 	}
 
-	storageCfg, err := storage_config.MustLoadStorageConfig()
-	if err != nil {
-		slog.Error("Error loading storage config")
-		os.Exit(1)
-	}
-
-	postgresDb, err := postgres.NewStorage(storageCfg.Postgres)
-	if err != nil {
-		slog.Error("failed to connect to postgres", err)
-		os.Exit(1)
-	}
+	slog.Info("connected to database")
 
-	redis, err := myredis.NewRedis(storageCfg.Redis)
-	if err != nil {
-		slog.Error("failed to connect to redis", err)
+	if err = app.PrepareSchema(context.Background()); err != nil {
+		slog.Error("database schema is not ready", "error", err)
 		os.Exit(1)
 	}
 
-	s3, err := mys3.NewS3Client(storageCfg.S3)
-	if err != nil {
-		slog.Error("failed to connect to s3", err)
-		os.Exit(1)
-	}
-
-	slog.Info("connected to database")
-
 	defer func() {
-		err = postgresDb.Close()
-		if err != nil {
-			slog.Error("got error when closing the DB connection", err)
+		if err = app.Close(); err != nil {
+			slog.Error("got error when closing connections", "error", err)
 			os.Exit(1)
 		}
 	}()
 
-	repos := bootstrap.Init(postgresDb, redis, s3, storageCfg.S3)
-
-	tokenManager, err := auth.NewTokenManager(repos.CacheRepo)
+	tokenManager, err := auth.NewTokenManager(app.Deps.CacheRepo)
 	if err != nil {
-		slog.Error("failed to initialize token manager", err)
+		slog.Error("failed to initialize token manager", "error", err)
 		os.Exit(1)
 	}
 
-	services := service.NewService(repos, tokenManager)
+	services := service.NewService(app.Deps, tokenManager, app.Config)
 
 	handlers := handler.NewHandler(services, tokenManager)
 
 	slog.Info("starting server")
 
 	server := &http.Server{
-		Addr:    serverCfg.Address,
-		Handler: router.NewRouter(handlers, redis),
+		Addr:    app.Config.Address,
+		Handler: router.NewRouter(handlers, app.Redis, app.Config.Idempotency),
 	}
 
 	quit := make(chan os.Signal, 1)
 
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+
+	backgroundDone := make(chan struct{})
+	if app.Config.Jobs.Embedded {
+		go func() {
+			services.RunBackground(schedulerCtx)
+			close(backgroundDone)
+		}()
+	} else {
+		close(backgroundDone)
+	}
+	go services.Feed.Run(schedulerCtx)
+
+	if tgCfg := app.Config.Notifications.Telegram; tgCfg.BotToken != "" && tgCfg.Polling {
+		bot := tgbot.NewBot(telegram.NewClient(tgCfg), services)
+		go bot.Run(schedulerCtx)
+	}
+
 	go func() {
 		slog.Info("starting server...")
 		if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.Error("listen and serve error", err)
+			slog.Error("listen and serve error", "error", err)
 		}
 	}()
 
 	<-quit
 	slog.Info("shutting down server...")
 
+	stopScheduler()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 
 	defer cancel()
 
 	if err = server.Shutdown(ctx); err != nil {
-		slog.Error("server forced to shutdown", err)
+		slog.Error("server forced to shutdown", "error", err)
+	}
+
+	// Jobs cut off here are claimed again once their lease expires.
+	select {
+	case <-backgroundDone:
+	case <-ctx.Done():
+		slog.Warn("background jobs did not finish before shutdown")
 	}
 
 	slog.Info("server exiting")
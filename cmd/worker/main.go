@@ -0,0 +1,65 @@
+package main
+
+import (
+	"LostAndFound/internal/auth"
+	"LostAndFound/internal/bootstrap"
+	"LostAndFound/internal/service"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	err := godotenv.Load(".env")
+	if err != nil {
+		slog.Error("Error loading .env file")
+		os.Exit(1)
+	}
+}
+
+// The worker runs the background processing of the server: jobs, the
+// notification and webhook dispatchers and the outbox relay. Run it with
+// jobs.embedded turned off on the servers.
+func main() {
+
+	app, err := bootstrap.Load()
+	if err != nil {
+		slog.Error("failed to start", "error", err)
+		os.Exit(1)
+	}
+
+	if err = app.PrepareSchema(context.Background()); err != nil {
+		slog.Error("database schema is not ready", "error", err)
+		os.Exit(1)
+	}
+
+	defer func() {
+		if err = app.Close(); err != nil {
+			slog.Error("got error when closing connections", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	tokenManager, err := auth.NewTokenManager(app.Deps.CacheRepo)
+	if err != nil {
+		slog.Error("failed to initialize token manager", "error", err)
+		os.Exit(1)
+	}
+
+	services := service.NewService(app.Deps, tokenManager, app.Config)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("starting worker", "workers", app.Config.Jobs.Workers)
+
+	// Unlike the server, the worker has nothing else to shut down, so it waits
+	// for the jobs in progress without a deadline.
+	services.RunBackground(ctx)
+
+	slog.Info("worker exiting")
+}
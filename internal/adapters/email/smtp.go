@@ -0,0 +1,107 @@
+package email
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/config/server_config"
+	"LostAndFound/internal/domain/entity"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// Sender delivers notifications as plain text emails over SMTP.
+type Sender struct {
+	cfg server_config.EmailConfig
+}
+
+func (s *Sender) Channel() entity.NotificationChannel {
+	return entity.ChannelEmail
+}
+
+func (s *Sender) Send(ctx context.Context, recipient *entity.Recipient, n *entity.Notification) error {
+	if recipient.Email == "" {
+		return fmt.Errorf("%w: user has no email", e.ErrRecipientUnreachable)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err = client.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("smtp MAIL failed: %w", err)
+	}
+	if err = client.Rcpt(recipient.Email); err != nil {
+		// 5xx replies mean the mailbox is rejected for good.
+		var reply *textproto.Error
+		if errors.As(err, &reply) && reply.Code >= 500 {
+			return fmt.Errorf("%w: %v", e.ErrRecipientUnreachable, err)
+		}
+		return fmt.Errorf("smtp RCPT failed: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err = w.Write(s.message(recipient.Email, n)); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (s *Sender) message(to string, n *entity.Notification) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(n.Title)
+	b.WriteString("\r\n\r\n")
+	b.WriteString(n.Body)
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+func NewSender(cfg server_config.EmailConfig) *Sender {
+	return &Sender{cfg: cfg}
+}
@@ -6,6 +6,9 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,7 +17,7 @@ type CardRepository struct {
 	db *sql.DB
 }
 
-func (l *CardRepository) Create(ctx context.Context, card *entity.Card) error {
+func (l *CardRepository) Create(ctx context.Context, card *entity.Card, events ...*entity.DomainEvent) error {
 	tx, err := l.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -22,36 +25,34 @@ func (l *CardRepository) Create(ctx context.Context, card *entity.Card) error {
 	defer tx.Rollback()
 
 	insertCardQuery := `
-		INSERT INTO cards (id, title, description, owner_id, preview_url, location, city, street, status)
-		VALUES ($1, $2, $3, $4, $5, ST_SetSRID(ST_MakePoint($6, $7), 4326), $8, $9, $10)
+		INSERT INTO cards (id, title, description, owner_id, preview_url, location, city, street, status, category, place_id)
+		VALUES ($1, $2, $3, $4, $5, ST_SetSRID(ST_MakePoint($6::float8, $7::float8), 4326), $8, $9, $10, $11, ` + containingPlaceSQL("$6", "$7") + `)
+		RETURNING COALESCE(place_id::text, '')
 	`
 
-	_, err = tx.ExecContext(ctx, insertCardQuery,
+	err = tx.QueryRowContext(ctx, insertCardQuery,
 		card.ID,
 		card.Title,
 		card.Description,
 		card.Owner.ID,
 		card.PreviewURL,
-		card.Longitude,
-		card.Latitude,
+		nullLongitude(card.Location),
+		nullLatitude(card.Location),
 		card.City,
 		card.Street,
 		card.Status,
-	)
+		card.Category,
+	).Scan(&card.PlaceID)
 	if err != nil {
 		return fmt.Errorf("failed to insert card: %w", err)
 	}
 
-	insertImageQuery := `
-		INSERT INTO card_images (id, card_id, url)
-		VALUES ($1, $2, $3)
-	`
+	if err = insertImages(ctx, tx, card); err != nil {
+		return err
+	}
 
-	for _, url := range card.Images {
-		_, err = tx.ExecContext(ctx, insertImageQuery, uuid.New().String(), card.ID, url)
-		if err != nil {
-			return fmt.Errorf("failed to insert image: %w", err)
-		}
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return err
 	}
 
 	return tx.Commit()
@@ -64,62 +65,74 @@ func (l *CardRepository) GetByID(ctx context.Context, id string) (*entity.Card,
 	}
 	defer tx.Rollback()
 
+	card, err := selectCard(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return card, tx.Commit()
+}
+
+// selectCard reads the card with its owner, place and images in tx.
+func selectCard(ctx context.Context, tx *sql.Tx, id string) (*entity.Card, error) {
 	query := `
 	SELECT 
-		l.id, l.title, l.description, l.city, l.street, l.status, l.created_at,
+		l.id, l.title, l.description, l.city, l.street, l.status, l.category,
+		l.preview_url, l.created_at, l.deleted_at, l.version,
 		ST_Y(l.location::geometry),
 		ST_X(l.location::geometry),
-		u.id, u.name, u.surname, u.phone, u.telegram
+		u.id, u.name, u.surname, u.phone, u.telegram, u.avatar_thumb_url,
+		COALESCE(p.id::text, ''), COALESCE(p.name, '')
 	FROM cards l
 	JOIN users u ON l.owner_id = u.id
+	LEFT JOIN places p ON p.id = l.place_id
 	WHERE l.id = $1;
 	`
 
 	var card entity.Card
+	var lat, lon sql.NullFloat64
 	var owner entity.Owner
 
-	if err = tx.QueryRowContext(ctx, query, id).Scan(
+	if err := tx.QueryRowContext(ctx, query, id).Scan(
 		&card.ID,
 		&card.Title,
 		&card.Description,
 		&card.City,
 		&card.Street,
 		&card.Status,
+		&card.Category,
+		&card.PreviewURL,
 		&card.CreatedAt,
-		&card.Latitude,
-		&card.Longitude,
+		&card.DeletedAt,
+		&card.Version,
+		&lat,
+		&lon,
 		&owner.ID,
 		&owner.Name,
 		&owner.Surname,
 		&owner.Phone,
 		&owner.Telegram,
+		&owner.AvatarURL,
+		&card.PlaceID,
+		&card.PlaceName,
 	); err != nil {
 		slog.Error(err.Error())
 		return nil, err
 	}
+	card.Location = scanLocation(lat, lon)
 
 	card.Owner = owner
 	card.OwnerID = owner.ID
 
-	imgQuery := `SELECT url FROM card_images WHERE card_id = $1`
-	rows, err := tx.QueryContext(ctx, imgQuery, id)
+	images, err := selectImages(ctx, tx, id)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	card.Images = images
 
-	for rows.Next() {
-		var url string
-		if err = rows.Scan(&url); err != nil {
-			return nil, err
-		}
-		card.Images = append(card.Images, url)
-	}
-
-	return &card, tx.Commit()
+	return &card, nil
 }
 
-func (l *CardRepository) FindAll(ctx context.Context, status string) ([]*entity.Card, error) {
+func (l *CardRepository) FindAll(ctx context.Context, filter entity.CardFilter) ([]*entity.Card, error) {
 	tx, err := l.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -128,18 +141,20 @@ func (l *CardRepository) FindAll(ctx context.Context, status string) ([]*entity.
 
 	query := `
 		SELECT 
-			l.id, l.title, l.description, l.city, l.street, l.status, 
-			l.preview_url, l.created_at,
+			l.id, l.title, l.description, l.city, l.street, l.status, l.category,
+			l.preview_url, l.created_at, l.deleted_at, l.version,
 			ST_Y(l.location::geometry),
 			ST_X(l.location::geometry),
-			u.id, u.name, u.surname, u.phone, u.telegram
+			u.id, u.name, u.surname, u.phone, u.telegram, u.avatar_thumb_url,
+			COALESCE(p.id::text, ''), COALESCE(p.name, '')
 		FROM cards l
 		JOIN users u ON l.owner_id = u.id
+		LEFT JOIN places p ON p.id = l.place_id
+		WHERE TRUE
 	`
-	var args []interface{}
-	if status != "" {
-		query += " WHERE l.status = $1 ORDER BY l.created_at DESC"
-		args = append(args, status)
+	query, args := appendCardFilter(query, nil, filter)
+	if filter.Deleted {
+		query += " ORDER BY l.deleted_at DESC"
 	} else {
 		query += " ORDER BY l.created_at DESC"
 	}
@@ -153,6 +168,7 @@ func (l *CardRepository) FindAll(ctx context.Context, status string) ([]*entity.
 	var cards []*entity.Card
 	for rows.Next() {
 		var card entity.Card
+		var lat, lon sql.NullFloat64
 		var owner entity.Owner
 
 		err = rows.Scan(
@@ -162,19 +178,26 @@ func (l *CardRepository) FindAll(ctx context.Context, status string) ([]*entity.
 			&card.City,
 			&card.Street,
 			&card.Status,
+			&card.Category,
 			&card.PreviewURL,
 			&card.CreatedAt,
-			&card.Latitude,
-			&card.Longitude,
+			&card.DeletedAt,
+			&card.Version,
+			&lat,
+			&lon,
 			&owner.ID,
 			&owner.Name,
 			&owner.Surname,
 			&owner.Phone,
 			&owner.Telegram,
+			&owner.AvatarURL,
+			&card.PlaceID,
+			&card.PlaceName,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning card row: %w", err)
 		}
+		card.Location = scanLocation(lat, lon)
 
 		card.Owner = owner
 		cards = append(cards, &card)
@@ -183,7 +206,9 @@ func (l *CardRepository) FindAll(ctx context.Context, status string) ([]*entity.
 	return cards, tx.Commit()
 }
 
-func (l *CardRepository) Update(ctx context.Context, card *entity.Card) error {
+// Update saves the card if it still has the version it was read with and
+// moves it to the next version. A changed version gives sql.ErrNoRows.
+func (l *CardRepository) Update(ctx context.Context, card *entity.Card, revision *entity.CardRevision, events ...*entity.DomainEvent) error {
 	tx, err := l.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -197,48 +222,118 @@ func (l *CardRepository) Update(ctx context.Context, card *entity.Card) error {
 			city = $3, 
 			street = $4,
 			status = $5,
-			preview_url = $6,
-			location = ST_SetSRID(ST_MakePoint($7, $8), 4326)
-		WHERE id = $9;
+			category = $6,
+			preview_url = $7,
+			location = ST_SetSRID(ST_MakePoint($8::float8, $9::float8), 4326),
+			place_id = ` + containingPlaceSQL("$8", "$9") + `,
+			version = version + 1
+		WHERE id = $10 AND deleted_at IS NULL AND version = $11
+		RETURNING COALESCE(place_id::text, ''), version;
 	`
-	if _, err = tx.ExecContext(ctx, query,
+	if err = tx.QueryRowContext(ctx, query,
 		card.Title,
 		card.Description,
 		card.City,
 		card.Street,
 		card.Status,
+		card.Category,
 		card.PreviewURL,
-		card.Longitude,
-		card.Latitude,
+		nullLongitude(card.Location),
+		nullLatitude(card.Location),
 		card.ID,
-	); err != nil {
+		card.Version,
+	).Scan(&card.PlaceID, &card.Version); err != nil {
 		return fmt.Errorf("failed to update card: %w", err)
 	}
 
-	if len(card.Images) > 0 {
+	if slices.Contains(revision.Fields, entity.CardFieldImages) {
 		delQuery := `DELETE FROM card_images WHERE card_id = $1;`
 		if _, err = tx.ExecContext(ctx, delQuery, card.ID); err != nil {
 			return fmt.Errorf("failed to delete card images: %w", err)
 		}
 
-		insertQuery := `INSERT INTO card_images (id, card_id, url) VALUES ($1, $2, $3);`
-		for _, img := range card.Images {
-			if _, err = tx.ExecContext(ctx, insertQuery, uuid.New().String(), card.ID, img); err != nil {
-				return fmt.Errorf("failed to insert card image: %w", err)
-			}
+		if err = insertImages(ctx, tx, card); err != nil {
+			return err
 		}
 	}
 
+	if err = insertRevision(ctx, tx, revision); err != nil {
+		return err
+	}
+
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete moves the card to the trash if it still has the given version. Its
+// images and files stay until the card is purged.
+func (l *CardRepository) Delete(ctx context.Context, id string, version int, events ...*entity.DomainEvent) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE cards SET deleted_at = NOW(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL AND version = $2`
+	res, err := tx.ExecContext(ctx, query, id, version)
+	if err != nil {
+		return fmt.Errorf("failed to delete card: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
-func (l *CardRepository) Delete(ctx context.Context, id string) error {
+// Restore takes the card out of the trash if it was deleted after
+// deletedAfter.
+func (l *CardRepository) Restore(ctx context.Context, id string, deletedAfter time.Time, events ...*entity.DomainEvent) error {
 	tx, err := l.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	query := `UPDATE cards SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at > $2`
+	res, err := tx.ExecContext(ctx, query, id, deletedAfter)
+	if err != nil {
+		return fmt.Errorf("failed to restore card: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Purge removes a card deleted before deletedBefore for good, together with
+// its images.
+func (l *CardRepository) Purge(ctx context.Context, id string, deletedBefore time.Time, events ...*entity.DomainEvent) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The row lock keeps a concurrent restore from bringing back a card whose
+	// images are already gone.
+	lockQuery := `SELECT id FROM cards WHERE id = $1 AND deleted_at < $2 FOR UPDATE`
+	if err = tx.QueryRowContext(ctx, lockQuery, id, deletedBefore).Scan(&id); err != nil {
+		return err
+	}
+
 	deleteImagesQuery := `DELETE FROM card_images WHERE card_id = $1`
 	if _, err = tx.ExecContext(ctx, deleteImagesQuery, id); err != nil {
 		return fmt.Errorf("failed to delete card images: %w", err)
@@ -249,10 +344,18 @@ func (l *CardRepository) Delete(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to delete card: %w", err)
 	}
 
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
-func (l *CardRepository) FindNearLocation(ctx context.Context, lat, lon, radius float64, status string) ([]*entity.Card, error) {
+func (l *CardRepository) FindNearLocation(ctx context.Context, filter entity.CardFilter) ([]*entity.Card, error) {
+	if filter.Area == nil {
+		return nil, fmt.Errorf("nearby search needs an area")
+	}
+
 	tx, err := l.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -261,21 +364,19 @@ func (l *CardRepository) FindNearLocation(ctx context.Context, lat, lon, radius
 
 	query := `
 		SELECT 
-			l.id, l.title, l.description, l.preview_url, l.status, l.created_at, l.city, l.street,
+			l.id, l.title, l.description, l.preview_url, l.status, l.category, l.created_at, l.city, l.street,
 			ST_Distance(l.location, ST_MakePoint($1, $2)::geography) as distance_m,
-			u.id, u.name, u.surname, u.telegram
+			u.id, u.name, u.surname, u.telegram, u.avatar_thumb_url,
+			COALESCE(p.id::text, ''), COALESCE(p.name, '')
 		FROM cards l
 		JOIN users u ON l.owner_id = u.id
-		WHERE ST_DWithin(l.location, ST_MakePoint($1, $2)::geography, $3)
+		LEFT JOIN places p ON p.id = l.place_id
+		WHERE TRUE
 	`
 
 	var args []interface{}
-	args = append(args, lon, lat, radius)
-
-	if status != "" {
-		query += " AND l.status = $4"
-		args = append(args, status)
-	}
+	args = append(args, filter.Area.Center.Longitude, filter.Area.Center.Latitude)
+	query, args = appendCardFilter(query, args, filter)
 
 	query += " ORDER BY distance_m ASC"
 
@@ -296,6 +397,7 @@ func (l *CardRepository) FindNearLocation(ctx context.Context, lat, lon, radius
 			&card.Description,
 			&card.PreviewURL,
 			&card.Status,
+			&card.Category,
 			&card.CreatedAt,
 			&card.City,
 			&card.Street,
@@ -304,6 +406,9 @@ func (l *CardRepository) FindNearLocation(ctx context.Context, lat, lon, radius
 			&owner.Name,
 			&owner.Surname,
 			&owner.Telegram,
+			&owner.AvatarURL,
+			&card.PlaceID,
+			&card.PlaceName,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning nearby card row: %w", err)
@@ -316,6 +421,542 @@ func (l *CardRepository) FindNearLocation(ctx context.Context, lat, lon, radius
 	return cards, tx.Commit()
 }
 
+func (l *CardRepository) FindInBounds(ctx context.Context, bbox entity.BoundingBox, filter entity.CardFilter, limit int) ([]*entity.Card, error) {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT
+			l.id, l.title, l.status, l.category, l.preview_url, l.created_at,
+			ST_Y(l.location::geometry),
+			ST_X(l.location::geometry),
+			l.owner_id
+		FROM cards l
+		WHERE l.location::geometry && ST_MakeEnvelope($1, $2, $3, $4, 4326)
+	`
+	args := []any{bbox.MinLon, bbox.MinLat, bbox.MaxLon, bbox.MaxLat}
+	query, args = appendCardFilter(query, args, filter)
+
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY l.created_at DESC LIMIT $%d", len(args))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying cards in bounds: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []*entity.Card
+	for rows.Next() {
+		var card entity.Card
+		var lat, lon sql.NullFloat64
+		if err = rows.Scan(
+			&card.ID,
+			&card.Title,
+			&card.Status,
+			&card.Category,
+			&card.PreviewURL,
+			&card.CreatedAt,
+			&lat,
+			&lon,
+			&card.OwnerID,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning card row: %w", err)
+		}
+		card.Location = scanLocation(lat, lon)
+		card.Owner.ID = card.OwnerID
+		cards = append(cards, &card)
+	}
+
+	return cards, tx.Commit()
+}
+
+func (l *CardRepository) ClusterInBounds(ctx context.Context, bbox entity.BoundingBox, filter entity.CardFilter, cellSize float64, limit int) ([]entity.MapCluster, error) {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT
+			ST_Y(ST_Centroid(ST_Collect(l.location::geometry))),
+			ST_X(ST_Centroid(ST_Collect(l.location::geometry))),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE l.status = 'lost'),
+			COUNT(*) FILTER (WHERE l.status = 'found'),
+			CASE WHEN COUNT(*) = 1 THEN MIN(l.id::text) ELSE '' END
+		FROM cards l
+		WHERE l.location::geometry && ST_MakeEnvelope($1, $2, $3, $4, 4326)
+	`
+	args := []any{bbox.MinLon, bbox.MinLat, bbox.MaxLon, bbox.MaxLat}
+	query, args = appendCardFilter(query, args, filter)
+
+	args = append(args, cellSize, limit)
+	query += fmt.Sprintf(" GROUP BY ST_SnapToGrid(l.location::geometry, $%d) ORDER BY COUNT(*) DESC LIMIT $%d", len(args)-1, len(args))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error clustering cards: %w", err)
+	}
+	defer rows.Close()
+
+	var clusters []entity.MapCluster
+	for rows.Next() {
+		var c entity.MapCluster
+		var lost, found int
+		if err = rows.Scan(&c.Latitude, &c.Longitude, &c.Count, &lost, &found, &c.CardID); err != nil {
+			return nil, fmt.Errorf("error scanning cluster row: %w", err)
+		}
+		c.StatusCounts = map[entity.CardStatus]int{
+			entity.StatusLost:  lost,
+			entity.StatusFound: found,
+		}
+		clusters = append(clusters, c)
+	}
+
+	return clusters, tx.Commit()
+}
+
+// RenderTile encodes the cards inside the tile as a Mapbox Vector Tile with a
+// single "cards" layer. Locations are matched as geometry: as geography, the
+// envelope of a tile touching the antimeridian degenerates and matches
+// nothing.
+func (l *CardRepository) RenderTile(ctx context.Context, tile entity.Tile) ([]byte, error) {
+	query := `
+		WITH bounds AS (
+			SELECT ST_TileEnvelope($1, $2, $3) AS geom
+		),
+		features AS (
+			SELECT
+				ST_AsMVTGeom(ST_Transform(l.location::geometry, 3857), bounds.geom) AS geom,
+				l.id::text AS id,
+				l.status,
+				l.category
+			FROM cards l, bounds
+			WHERE l.location::geometry && ST_Transform(bounds.geom, 4326)
+			  AND l.deleted_at IS NULL
+		)
+		SELECT COALESCE(ST_AsMVT(features.*, 'cards', 4096, 'geom'), ''::bytea) FROM features
+	`
+
+	var data []byte
+	if err := l.db.QueryRowContext(ctx, query, tile.Z, tile.X, tile.Y).Scan(&data); err != nil {
+		return nil, fmt.Errorf("failed to render tile: %w", err)
+	}
+	return data, nil
+}
+
+// likeEscaper escapes the LIKE wildcards in user input.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// appendCardFilter adds the filter conditions to a query that already has a
+// WHERE clause, numbering the placeholders after the existing arguments.
+// Cards in the trash are left out unless the filter asks for them.
+func appendCardFilter(query string, args []any, filter entity.CardFilter) (string, []any) {
+	if filter.Deleted {
+		query += " AND l.deleted_at IS NOT NULL"
+		if !filter.DeletedBefore.IsZero() {
+			args = append(args, filter.DeletedBefore)
+			query += fmt.Sprintf(" AND l.deleted_at < $%d", len(args))
+		}
+		if !filter.DeletedAfter.IsZero() {
+			args = append(args, filter.DeletedAfter)
+			query += fmt.Sprintf(" AND l.deleted_at > $%d", len(args))
+		}
+	} else {
+		query += " AND l.deleted_at IS NULL"
+	}
+	if filter.OwnerID != "" {
+		args = append(args, filter.OwnerID)
+		query += fmt.Sprintf(" AND l.owner_id = $%d", len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND l.status = $%d", len(args))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		query += fmt.Sprintf(" AND l.category = $%d", len(args))
+	}
+	if filter.Text != "" {
+		args = append(args, "%"+likeEscaper.Replace(filter.Text)+"%")
+		query += fmt.Sprintf(" AND (l.title ILIKE $%[1]d OR l.description ILIKE $%[1]d)", len(args))
+	}
+	if filter.Area != nil {
+		args = append(args, filter.Area.Center.Longitude, filter.Area.Center.Latitude, filter.Area.Radius.Meters())
+		query += fmt.Sprintf(" AND ST_DWithin(l.location, ST_MakePoint($%d, $%d)::geography, $%d)", len(args)-2, len(args)-1, len(args))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		args = append(args, filter.CreatedFrom)
+		query += fmt.Sprintf(" AND l.created_at >= $%d", len(args))
+	}
+	if !filter.CreatedTo.IsZero() {
+		args = append(args, filter.CreatedTo)
+		query += fmt.Sprintf(" AND l.created_at < $%d", len(args))
+	}
+	if filter.PlaceID != "" {
+		args = append(args, filter.PlaceID)
+		query += fmt.Sprintf(` AND l.place_id IN (
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM places WHERE id = $%d
+				UNION ALL
+				SELECT p.id FROM places p JOIN subtree s ON p.parent_id = s.id
+			)
+			SELECT id FROM subtree
+		)`, len(args))
+	}
+	return query, args
+}
+
+// nullLatitude and nullLongitude pass a card's point to a query, NULL when
+// the location is unknown.
+func nullLatitude(l *entity.Location) sql.NullFloat64 {
+	if l == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: l.Latitude, Valid: true}
+}
+
+func nullLongitude(l *entity.Location) sql.NullFloat64 {
+	if l == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: l.Longitude, Valid: true}
+}
+
+func scanLocation(lat, lon sql.NullFloat64) *entity.Location {
+	if !lat.Valid || !lon.Valid {
+		return nil
+	}
+	return &entity.Location{Latitude: lat.Float64, Longitude: lon.Float64}
+}
+
+// containingPlaceSQL selects the smallest place containing the point given by
+// the longitude and latitude placeholders.
+func containingPlaceSQL(lon, lat string) string {
+	return fmt.Sprintf(`(
+		SELECT p.id FROM places p
+		WHERE ST_Contains(p.geom::geometry, ST_SetSRID(ST_MakePoint(%s::float8, %s::float8), 4326))
+		ORDER BY ST_Area(p.geom)
+		LIMIT 1
+	)`, lon, lat)
+}
+
+func (l *CardRepository) FindSimilarImages(ctx context.Context, cardID string, maxDistance int) ([]*entity.SimilarCard, error) {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT * FROM (
+			SELECT DISTINCT ON (l.id)
+				l.id, l.title, l.description, l.city, l.street, l.status, l.category,
+				l.preview_url, l.created_at,
+				ST_Y(l.location::geometry),
+				ST_X(l.location::geometry),
+				u.id, u.name, u.surname, u.phone, u.telegram, u.avatar_thumb_url,
+				ci.url,
+				bit_count((ci.phash # si.phash)::bit(64)) AS distance
+			FROM card_images si
+			JOIN card_images ci ON ci.card_id <> si.card_id AND ci.phash IS NOT NULL
+			JOIN cards l ON l.id = ci.card_id
+			JOIN users u ON l.owner_id = u.id
+			WHERE si.card_id = $1
+			  AND si.phash IS NOT NULL
+			  AND l.deleted_at IS NULL
+			  AND bit_count((ci.phash # si.phash)::bit(64)) <= $2
+			ORDER BY l.id, distance
+		) similar
+		ORDER BY distance, created_at DESC
+	`
+
+	rows, err := tx.QueryContext(ctx, query, cardID, maxDistance)
+	if err != nil {
+		return nil, fmt.Errorf("error querying similar images: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*entity.SimilarCard
+	for rows.Next() {
+		var card entity.Card
+		var lat, lon sql.NullFloat64
+		var owner entity.Owner
+		var similar entity.SimilarCard
+
+		err = rows.Scan(
+			&card.ID,
+			&card.Title,
+			&card.Description,
+			&card.City,
+			&card.Street,
+			&card.Status,
+			&card.Category,
+			&card.PreviewURL,
+			&card.CreatedAt,
+			&lat,
+			&lon,
+			&owner.ID,
+			&owner.Name,
+			&owner.Surname,
+			&owner.Phone,
+			&owner.Telegram,
+			&owner.AvatarURL,
+			&similar.ImageURL,
+			&similar.Distance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning similar image row: %w", err)
+		}
+		card.Location = scanLocation(lat, lon)
+
+		card.Owner = owner
+		card.OwnerID = owner.ID
+		similar.Card = &card
+		result = append(result, &similar)
+	}
+
+	return result, tx.Commit()
+}
+
+func (l *CardRepository) ReorderImages(ctx context.Context, cardID string, imageIDs []string, events ...*entity.DomainEvent) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE card_images SET position = $1 WHERE id = $2 AND card_id = $3`
+	for position, imageID := range imageIDs {
+		res, err := tx.ExecContext(ctx, query, position, imageID, cardID)
+		if err != nil {
+			return fmt.Errorf("failed to reorder card images: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+	}
+
+	if err = bumpVersion(ctx, tx, cardID); err != nil {
+		return err
+	}
+
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (l *CardRepository) UpdateImage(ctx context.Context, cardID string, img entity.CardImage, events ...*entity.DomainEvent) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if img.IsCover {
+		resetQuery := `UPDATE card_images SET is_cover = FALSE WHERE card_id = $1 AND id <> $2`
+		if _, err = tx.ExecContext(ctx, resetQuery, cardID, img.ID); err != nil {
+			return fmt.Errorf("failed to reset card cover: %w", err)
+		}
+	}
+
+	query := `UPDATE card_images SET caption = $1, is_cover = $2 WHERE id = $3 AND card_id = $4`
+	res, err := tx.ExecContext(ctx, query, img.Caption, img.IsCover, img.ID, cardID)
+	if err != nil {
+		return fmt.Errorf("failed to update card image: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = syncCover(ctx, tx, cardID); err != nil {
+		return err
+	}
+
+	if err = bumpVersion(ctx, tx, cardID); err != nil {
+		return err
+	}
+
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (l *CardRepository) DeleteImage(ctx context.Context, cardID, imageID string, events ...*entity.DomainEvent) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM card_images WHERE id = $1 AND card_id = $2`
+	res, err := tx.ExecContext(ctx, query, imageID, cardID)
+	if err != nil {
+		return fmt.Errorf("failed to delete card image: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	compactQuery := `
+		UPDATE card_images ci
+		SET position = numbered.position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) - 1 AS position
+			FROM card_images
+			WHERE card_id = $1
+		) numbered
+		WHERE numbered.id = ci.id
+	`
+	if _, err = tx.ExecContext(ctx, compactQuery, cardID); err != nil {
+		return fmt.Errorf("failed to renumber card images: %w", err)
+	}
+
+	if err = syncCover(ctx, tx, cardID); err != nil {
+		return err
+	}
+
+	if err = bumpVersion(ctx, tx, cardID); err != nil {
+		return err
+	}
+
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (l *CardRepository) UpdateImageDetails(ctx context.Context, img entity.CardImage) error {
+	var hash sql.NullInt64
+	if img.Hash != nil {
+		hash = sql.NullInt64{Int64: int64(*img.Hash), Valid: true}
+	}
+
+	query := `UPDATE card_images SET phash = $1, width = $2, height = $3 WHERE id = $4`
+	if _, err := l.db.ExecContext(ctx, query, hash, img.Width, img.Height, img.ID); err != nil {
+		return fmt.Errorf("failed to update image details: %w", err)
+	}
+	return nil
+}
+
+func insertImages(ctx context.Context, tx *sql.Tx, card *entity.Card) error {
+	query := `
+		INSERT INTO card_images (id, card_id, url, phash, position, caption, width, height, is_cover)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	for i := range card.Images {
+		img := &card.Images[i]
+		if img.ID == "" {
+			img.ID = uuid.New().String()
+		}
+
+		var hash sql.NullInt64
+		if img.Hash != nil {
+			hash = sql.NullInt64{Int64: int64(*img.Hash), Valid: true}
+		}
+
+		_, err := tx.ExecContext(ctx, query,
+			img.ID,
+			card.ID,
+			img.URL,
+			hash,
+			img.Position,
+			img.Caption,
+			img.Width,
+			img.Height,
+			img.IsCover,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert image: %w", err)
+		}
+	}
+	return nil
+}
+
+func selectImages(ctx context.Context, tx *sql.Tx, cardID string) ([]entity.CardImage, error) {
+	query := `
+		SELECT id, url, phash, position, caption, width, height, is_cover
+		FROM card_images
+		WHERE card_id = $1
+		ORDER BY position, id
+	`
+	rows, err := tx.QueryContext(ctx, query, cardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []entity.CardImage
+	for rows.Next() {
+		var img entity.CardImage
+		var hash sql.NullInt64
+		if err = rows.Scan(
+			&img.ID,
+			&img.URL,
+			&hash,
+			&img.Position,
+			&img.Caption,
+			&img.Width,
+			&img.Height,
+			&img.IsCover,
+		); err != nil {
+			return nil, err
+		}
+		if hash.Valid {
+			h := uint64(hash.Int64)
+			img.Hash = &h
+		}
+		images = append(images, img)
+	}
+	return images, rows.Err()
+}
+
+// syncCover makes sure a card with images has exactly one cover, falling
+// back to the first image, and copies the cover URL into cards.preview_url.
+func syncCover(ctx context.Context, tx *sql.Tx, cardID string) error {
+	coverQuery := `
+		UPDATE card_images SET is_cover = TRUE
+		WHERE id = (
+			SELECT id FROM card_images WHERE card_id = $1 ORDER BY position, id LIMIT 1
+		)
+		AND NOT EXISTS (SELECT 1 FROM card_images WHERE card_id = $1 AND is_cover)
+	`
+	if _, err := tx.ExecContext(ctx, coverQuery, cardID); err != nil {
+		return fmt.Errorf("failed to set card cover: %w", err)
+	}
+
+	previewQuery := `
+		UPDATE cards SET preview_url = COALESCE(
+			(SELECT url FROM card_images WHERE card_id = $1 AND is_cover), ''
+		)
+		WHERE id = $1
+	`
+	if _, err := tx.ExecContext(ctx, previewQuery, cardID); err != nil {
+		return fmt.Errorf("failed to update card preview: %w", err)
+	}
+	return nil
+}
+
+// bumpVersion moves the card to the next version after a change made
+// outside of Update.
+func bumpVersion(ctx context.Context, tx *sql.Tx, cardID string) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE cards SET version = version + 1 WHERE id = $1`, cardID); err != nil {
+		return fmt.Errorf("failed to update card version: %w", err)
+	}
+	return nil
+}
+
 func NewCardRepo(db *sql.DB) *CardRepository {
 	return &CardRepository{db: db}
 }
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// FindRevisions lists the revisions of a card, oldest first.
+func (l *CardRepository) FindRevisions(ctx context.Context, cardID string) ([]*entity.CardRevision, error) {
+	query := `
+		SELECT id, card_id, number, author_id, fields, before, after, reverted_to, created_at
+		FROM card_revisions
+		WHERE card_id = $1
+		ORDER BY number
+	`
+
+	rows, err := l.db.QueryContext(ctx, query, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying card revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*entity.CardRevision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating card revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+func scanRevision(rows *sql.Rows) (*entity.CardRevision, error) {
+	var rev entity.CardRevision
+	var fields []string
+	var before, after []byte
+	var revertedTo sql.NullInt64
+
+	if err := rows.Scan(
+		&rev.ID,
+		&rev.CardID,
+		&rev.Number,
+		&rev.AuthorID,
+		pq.Array(&fields),
+		&before,
+		&after,
+		&revertedTo,
+		&rev.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("error scanning card revision: %w", err)
+	}
+
+	for _, f := range fields {
+		rev.Fields = append(rev.Fields, entity.CardField(f))
+	}
+	if err := json.Unmarshal(before, &rev.Before); err != nil {
+		return nil, fmt.Errorf("failed to decode card revision: %w", err)
+	}
+	if err := json.Unmarshal(after, &rev.After); err != nil {
+		return nil, fmt.Errorf("failed to decode card revision: %w", err)
+	}
+	if revertedTo.Valid {
+		n := int(revertedTo.Int64)
+		rev.RevertedTo = &n
+	}
+	return &rev, nil
+}
+
+// insertRevision stores the revision with the next number of the card. The
+// caller's update of the card row serializes concurrent revisions.
+func insertRevision(ctx context.Context, tx *sql.Tx, rev *entity.CardRevision) error {
+	before, err := json.Marshal(rev.Before)
+	if err != nil {
+		return fmt.Errorf("failed to encode card revision: %w", err)
+	}
+	after, err := json.Marshal(rev.After)
+	if err != nil {
+		return fmt.Errorf("failed to encode card revision: %w", err)
+	}
+
+	fields := make([]string, 0, len(rev.Fields))
+	for _, f := range rev.Fields {
+		fields = append(fields, string(f))
+	}
+
+	var revertedTo sql.NullInt64
+	if rev.RevertedTo != nil {
+		revertedTo = sql.NullInt64{Int64: int64(*rev.RevertedTo), Valid: true}
+	}
+
+	query := `
+		INSERT INTO card_revisions (id, card_id, number, author_id, fields, before, after, reverted_to)
+		SELECT $1, $2, COALESCE(MAX(number), 0) + 1, $3, $4, $5, $6, $7
+		FROM card_revisions
+		WHERE card_id = $2
+		RETURNING number, created_at
+	`
+	if err = tx.QueryRowContext(ctx, query,
+		rev.ID,
+		rev.CardID,
+		rev.AuthorID,
+		pq.Array(fields),
+		string(before),
+		string(after),
+		revertedTo,
+	).Scan(&rev.Number, &rev.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert card revision: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type FileRefRepository struct {
+	db *sql.DB
+}
+
+func (f *FileRefRepository) FindAll(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT url FROM card_images
+		UNION SELECT preview_url FROM cards WHERE preview_url <> ''
+		UNION SELECT avatar_url FROM users WHERE avatar_url <> ''
+		UNION SELECT avatar_thumb_url FROM users WHERE avatar_thumb_url <> ''
+		UNION SELECT jsonb_path_query(before, '$.Images[*].URL') #>> '{}' FROM card_revisions
+		UNION SELECT jsonb_path_query(after, '$.Images[*].URL') #>> '{}' FROM card_revisions
+	`
+
+	rows, err := f.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query file references: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []string
+	for rows.Next() {
+		var ref string
+		if err = rows.Scan(&ref); err != nil {
+			return nil, fmt.Errorf("error scanning file reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating file references: %w", err)
+	}
+	return refs, nil
+}
+
+func NewFileRefRepo(db *sql.DB) *FileRefRepository {
+	return &FileRefRepository{db: db}
+}
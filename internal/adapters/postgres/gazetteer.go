@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// gazetteerBatchSize is the number of addresses inserted per statement
+// during an import.
+const gazetteerBatchSize = 5000
+
+type GazetteerRepository struct {
+	db *sql.DB
+}
+
+func (g *GazetteerRepository) Reverse(ctx context.Context, point entity.Location, radius entity.Distance) (*entity.Address, error) {
+	query := `
+		SELECT city, street, house_number, ST_Y(location::geometry), ST_X(location::geometry)
+		FROM gazetteer
+		WHERE ST_DWithin(location, ST_MakePoint($1, $2)::geography, $3)
+		ORDER BY location <-> ST_MakePoint($1, $2)::geography
+		LIMIT 1
+	`
+	return g.scanAddress(g.db.QueryRowContext(ctx, query, point.Longitude, point.Latitude, radius.Meters()))
+}
+
+func (g *GazetteerRepository) Forward(ctx context.Context, city, street string) (*entity.Address, error) {
+	if street == "" {
+		// Without a street the city is resolved to the centroid of its entries.
+		query := `
+			SELECT min(city), '', '',
+				ST_Y(ST_Centroid(ST_Collect(location::geometry))),
+				ST_X(ST_Centroid(ST_Collect(location::geometry)))
+			FROM gazetteer
+			WHERE lower(city) = lower($1)
+			HAVING count(*) > 0
+		`
+		return g.scanAddress(g.db.QueryRowContext(ctx, query, city))
+	}
+
+	query := `
+		SELECT city, street, house_number, ST_Y(location::geometry), ST_X(location::geometry)
+		FROM gazetteer
+		WHERE lower(city) = lower($1)
+			AND lower(street || ' ' || house_number) % lower($2)
+		ORDER BY similarity(lower(street || ' ' || house_number), lower($2)) DESC
+		LIMIT 1
+	`
+	return g.scanAddress(g.db.QueryRowContext(ctx, query, city, street))
+}
+
+func (g *GazetteerRepository) Import(ctx context.Context, addresses []entity.Address) error {
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `TRUNCATE gazetteer`); err != nil {
+		return fmt.Errorf("failed to clear gazetteer: %w", err)
+	}
+
+	query := `
+		INSERT INTO gazetteer (city, street, house_number, location)
+		SELECT city, street, house_number, ST_SetSRID(ST_MakePoint(lon, lat), 4326)::geography
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::float8[], $5::float8[])
+			AS a(city, street, house_number, lat, lon)
+	`
+	for start := 0; start < len(addresses); start += gazetteerBatchSize {
+		batch := addresses[start:min(start+gazetteerBatchSize, len(addresses))]
+
+		cities := make([]string, len(batch))
+		streets := make([]string, len(batch))
+		houses := make([]string, len(batch))
+		lats := make([]float64, len(batch))
+		lons := make([]float64, len(batch))
+		for i, a := range batch {
+			cities[i], streets[i], houses[i] = a.City, a.Street, a.HouseNumber
+			lats[i], lons[i] = a.Latitude, a.Longitude
+		}
+
+		if _, err = tx.ExecContext(ctx, query,
+			pq.Array(cities),
+			pq.Array(streets),
+			pq.Array(houses),
+			pq.Array(lats),
+			pq.Array(lons),
+		); err != nil {
+			return fmt.Errorf("failed to insert gazetteer entries: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (g *GazetteerRepository) scanAddress(row *sql.Row) (*entity.Address, error) {
+	var a entity.Address
+	if err := row.Scan(&a.City, &a.Street, &a.HouseNumber, &a.Latitude, &a.Longitude); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query gazetteer: %w", err)
+	}
+	return &a, nil
+}
+
+func NewGazetteerRepo(db *sql.DB) *GazetteerRepository {
+	return &GazetteerRepository{db: db}
+}
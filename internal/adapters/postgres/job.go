@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const selectJobColumns = `
+	id, kind, COALESCE(key, ''), payload, status, attempts, max_attempts,
+	last_error, run_at, created_at, finished_at
+`
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func (j *JobRepository) Enqueue(ctx context.Context, job *entity.Job) (bool, error) {
+	query := `
+		INSERT INTO jobs (id, kind, key, payload, max_attempts, run_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (key) DO NOTHING
+	`
+
+	payload := string(job.Payload)
+	if payload == "" {
+		payload = "null"
+	}
+
+	res, err := j.db.ExecContext(ctx, query,
+		job.ID,
+		job.Kind,
+		nullString(job.Key),
+		payload,
+		job.MaxAttempts,
+		job.RunAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (j *JobRepository) Claim(ctx context.Context, kinds []string, now, leaseUntil time.Time, limit int) ([]*entity.Job, error) {
+	query := `
+		UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_until = $3
+		WHERE id IN (
+			SELECT id
+			FROM jobs
+			WHERE kind = ANY($1) AND (
+				(status = 'pending' AND run_at <= $2) OR
+				(status = 'running' AND locked_until <= $2)
+			)
+			ORDER BY run_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + selectJobColumns
+
+	rows, err := j.db.QueryContext(ctx, query, pq.Array(kinds), now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanJobs(rows)
+}
+
+func (j *JobRepository) Complete(ctx context.Context, id string) error {
+	query := `UPDATE jobs SET status = 'done', last_error = '', locked_until = NULL, finished_at = NOW() WHERE id = $1`
+
+	if _, err := j.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+	return nil
+}
+
+func (j *JobRepository) Retry(ctx context.Context, job *entity.Job, runAt time.Time) error {
+	query := `UPDATE jobs SET status = 'pending', last_error = $1, run_at = $2, locked_until = NULL WHERE id = $3`
+
+	if _, err := j.db.ExecContext(ctx, query, job.LastError, runAt, job.ID); err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+	return nil
+}
+
+func (j *JobRepository) Bury(ctx context.Context, job *entity.Job) error {
+	query := `UPDATE jobs SET status = 'dead', last_error = $1, locked_until = NULL, finished_at = NOW() WHERE id = $2`
+
+	if _, err := j.db.ExecContext(ctx, query, job.LastError, job.ID); err != nil {
+		return fmt.Errorf("failed to bury job: %w", err)
+	}
+	return nil
+}
+
+func (j *JobRepository) DeleteFinished(ctx context.Context, before time.Time) (int64, error) {
+	res, err := j.db.ExecContext(ctx, `DELETE FROM jobs WHERE status = 'done' AND finished_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (j *JobRepository) FindAll(ctx context.Context, filter entity.JobFilter) ([]*entity.Job, error) {
+	query := `SELECT ` + selectJobColumns + ` FROM jobs WHERE TRUE`
+	var args []any
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		query += fmt.Sprintf(" AND kind = $%d", len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", len(args))
+
+	rows, err := j.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanJobs(rows)
+}
+
+func (j *JobRepository) Count(ctx context.Context) ([]entity.JobCount, error) {
+	rows, err := j.db.QueryContext(ctx, `SELECT kind, status, COUNT(*) FROM jobs GROUP BY kind, status ORDER BY kind, status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []entity.JobCount
+	for rows.Next() {
+		var c entity.JobCount
+		if err = rows.Scan(&c.Kind, &c.Status, &c.Count); err != nil {
+			return nil, fmt.Errorf("error scanning job count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job counts: %w", err)
+	}
+	return counts, nil
+}
+
+func (j *JobRepository) Requeue(ctx context.Context, id string) error {
+	query := `
+		UPDATE jobs SET status = 'pending', attempts = 0, run_at = NOW(), finished_at = NULL
+		WHERE id = $1 AND status = 'dead'
+	`
+	res, err := j.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to requeue job: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func scanJobs(rows *sql.Rows) ([]*entity.Job, error) {
+	var jobs []*entity.Job
+	for rows.Next() {
+		var job entity.Job
+		if err := rows.Scan(
+			&job.ID,
+			&job.Kind,
+			&job.Key,
+			&job.Payload,
+			&job.Status,
+			&job.Attempts,
+			&job.MaxAttempts,
+			&job.LastError,
+			&job.RunAt,
+			&job.CreatedAt,
+			&job.FinishedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning job: %w", err)
+		}
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func NewJobRepo(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
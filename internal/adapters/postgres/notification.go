@@ -0,0 +1,432 @@
+package postgres
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const selectNotificationColumns = `
+	n.id, n.user_id, n.kind, n.title, n.body, COALESCE(n.card_id::text, ''), n.created_at, n.read_at
+`
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func (n *NotificationRepository) Create(ctx context.Context, notifications ...*entity.Notification) error {
+	tx, err := n.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notifications (id, user_id, kind, title, body, card_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+	deliveryQuery := `
+		INSERT INTO notification_deliveries (notification_id, channel, status, next_attempt_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, notification := range notifications {
+		if err = tx.QueryRowContext(ctx, query,
+			notification.ID,
+			notification.UserID,
+			notification.Kind,
+			notification.Title,
+			notification.Body,
+			nullString(notification.CardID),
+		).Scan(&notification.CreatedAt); err != nil {
+			return fmt.Errorf("failed to insert notification: %w", err)
+		}
+
+		for _, delivery := range notification.Deliveries {
+			if _, err = tx.ExecContext(ctx, deliveryQuery,
+				notification.ID,
+				delivery.Channel,
+				delivery.Status,
+				delivery.NextAttemptAt,
+			); err != nil {
+				return fmt.Errorf("failed to insert notification delivery: %w", err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (n *NotificationRepository) FindByUser(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*entity.Notification, error) {
+	query := `
+		SELECT ` + selectNotificationColumns + `
+		FROM notifications n
+		WHERE n.user_id = $1 AND (NOT $2 OR n.read_at IS NULL)
+		ORDER BY n.created_at DESC, n.id
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := n.db.QueryContext(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*entity.Notification
+	byID := make(map[string]*entity.Notification)
+	for rows.Next() {
+		notification := &entity.Notification{}
+		if err = rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.Kind,
+			&notification.Title,
+			&notification.Body,
+			&notification.CardID,
+			&notification.CreatedAt,
+			&notification.ReadAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+		byID[notification.ID] = notification
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+	if len(notifications) == 0 {
+		return notifications, nil
+	}
+
+	ids := make([]string, 0, len(notifications))
+	for _, notification := range notifications {
+		ids = append(ids, notification.ID)
+	}
+
+	deliveryQuery := `
+		SELECT notification_id, channel, status, attempts, last_error, next_attempt_at, sent_at
+		FROM notification_deliveries
+		WHERE notification_id = ANY($1::uuid[])
+		ORDER BY channel
+	`
+	deliveryRows, err := n.db.QueryContext(ctx, deliveryQuery, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("error querying notification deliveries: %w", err)
+	}
+	defer deliveryRows.Close()
+
+	for deliveryRows.Next() {
+		var delivery entity.NotificationDelivery
+		if err = deliveryRows.Scan(
+			&delivery.NotificationID,
+			&delivery.Channel,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.LastError,
+			&delivery.NextAttemptAt,
+			&delivery.SentAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning notification delivery: %w", err)
+		}
+		notification := byID[delivery.NotificationID]
+		notification.Deliveries = append(notification.Deliveries, delivery)
+	}
+	if err = deliveryRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification deliveries: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (n *NotificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+	if err := n.db.QueryRowContext(ctx, `SELECT count(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (n *NotificationRepository) MarkRead(ctx context.Context, userID, id string) error {
+	query := `
+		UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`
+	res, err := n.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (n *NotificationRepository) MarkAllRead(ctx context.Context, userID string) error {
+	query := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+	if _, err := n.db.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	return nil
+}
+
+func (n *NotificationRepository) FindPreferences(ctx context.Context, userIDs ...string) ([]*entity.NotificationPreferences, error) {
+	query := `
+		SELECT user_id, channels, quiet_start, quiet_end, time_zone
+		FROM notification_preferences
+		WHERE user_id = ANY($1::uuid[])
+	`
+
+	rows, err := n.db.QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error querying notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*entity.NotificationPreferences
+	for rows.Next() {
+		var (
+			prefs      entity.NotificationPreferences
+			channels   []string
+			start, end sql.NullInt16
+			timeZone   sql.NullString
+		)
+		if err = rows.Scan(&prefs.UserID, pq.Array(&channels), &start, &end, &timeZone); err != nil {
+			return nil, fmt.Errorf("error scanning notification preferences: %w", err)
+		}
+		prefs.Channels = toChannels(channels)
+		prefs.QuietHours = scanQuietHours(start, end, timeZone)
+		result = append(result, &prefs)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification preferences: %w", err)
+	}
+	return result, nil
+}
+
+func (n *NotificationRepository) SavePreferences(ctx context.Context, prefs *entity.NotificationPreferences) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, channels, quiet_start, quiet_end, time_zone)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			channels = EXCLUDED.channels,
+			quiet_start = EXCLUDED.quiet_start,
+			quiet_end = EXCLUDED.quiet_end,
+			time_zone = EXCLUDED.time_zone,
+			updated_at = NOW()
+	`
+
+	channels := make([]string, 0, len(prefs.Channels))
+	for _, channel := range prefs.Channels {
+		channels = append(channels, string(channel))
+	}
+
+	var start, end sql.NullInt16
+	var timeZone sql.NullString
+	if q := prefs.QuietHours; q != nil {
+		start = sql.NullInt16{Int16: int16(q.Start), Valid: true}
+		end = sql.NullInt16{Int16: int16(q.End), Valid: true}
+		timeZone = sql.NullString{String: q.TimeZone, Valid: true}
+	}
+
+	if _, err := n.db.ExecContext(ctx, query, prefs.UserID, pq.Array(channels), start, end, timeZone); err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+func (n *NotificationRepository) SavePushSubscription(ctx context.Context, sub *entity.PushSubscription) error {
+	query := `
+		INSERT INTO push_subscriptions (id, user_id, endpoint, p256dh, auth)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (endpoint) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth
+		RETURNING id, created_at
+	`
+	if err := n.db.QueryRowContext(ctx, query,
+		sub.ID,
+		sub.UserID,
+		sub.Endpoint,
+		sub.P256dh,
+		sub.Auth,
+	).Scan(&sub.ID, &sub.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save push subscription: %w", err)
+	}
+	return nil
+}
+
+func (n *NotificationRepository) DeletePushSubscription(ctx context.Context, userID, id string) error {
+	res, err := n.db.ExecContext(ctx, `DELETE FROM push_subscriptions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (n *NotificationRepository) ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.Notification, error) {
+	query := `
+		UPDATE notification_deliveries d SET next_attempt_at = $2
+		FROM notifications n
+		WHERE n.id = d.notification_id AND (d.notification_id, d.channel) IN (
+			SELECT notification_id, channel
+			FROM notification_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + selectNotificationColumns + `,
+			d.channel, d.status, d.attempts, d.last_error, d.next_attempt_at, d.sent_at
+	`
+
+	rows, err := n.db.QueryContext(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*entity.Notification
+	for rows.Next() {
+		var notification entity.Notification
+		var delivery entity.NotificationDelivery
+		if err = rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.Kind,
+			&notification.Title,
+			&notification.Body,
+			&notification.CardID,
+			&notification.CreatedAt,
+			&notification.ReadAt,
+			&delivery.Channel,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.LastError,
+			&delivery.NextAttemptAt,
+			&delivery.SentAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning notification delivery: %w", err)
+		}
+		delivery.NotificationID = notification.ID
+		notification.Deliveries = []entity.NotificationDelivery{delivery}
+		notifications = append(notifications, &notification)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification deliveries: %w", err)
+	}
+	return notifications, nil
+}
+
+func (n *NotificationRepository) UpdateDelivery(ctx context.Context, delivery *entity.NotificationDelivery) error {
+	query := `
+		UPDATE notification_deliveries SET
+			status = $1,
+			attempts = $2,
+			last_error = $3,
+			next_attempt_at = $4,
+			sent_at = $5
+		WHERE notification_id = $6 AND channel = $7
+	`
+	if _, err := n.db.ExecContext(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.SentAt,
+		delivery.NotificationID,
+		delivery.Channel,
+	); err != nil {
+		return fmt.Errorf("failed to update notification delivery: %w", err)
+	}
+	return nil
+}
+
+func (n *NotificationRepository) GetRecipient(ctx context.Context, userID string) (*entity.Recipient, error) {
+	query := `
+		SELECT u.id, u.email, COALESCE(u.telegram_chat_id, 0), p.quiet_start, p.quiet_end, p.time_zone
+		FROM users u
+		LEFT JOIN notification_preferences p ON p.user_id = u.id
+		WHERE u.id = $1
+	`
+
+	var (
+		recipient  entity.Recipient
+		start, end sql.NullInt16
+		timeZone   sql.NullString
+	)
+	if err := n.db.QueryRowContext(ctx, query, userID).Scan(
+		&recipient.UserID,
+		&recipient.Email,
+		&recipient.TelegramChatID,
+		&start,
+		&end,
+		&timeZone,
+	); err != nil {
+		return nil, err
+	}
+	recipient.QuietHours = scanQuietHours(start, end, timeZone)
+
+	subQuery := `
+		SELECT id, user_id, endpoint, p256dh, auth, created_at
+		FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	rows, err := n.db.QueryContext(ctx, subQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sub entity.PushSubscription
+		if err = rows.Scan(&sub.ID, &sub.UserID, &sub.Endpoint, &sub.P256dh, &sub.Auth, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning push subscription: %w", err)
+		}
+		recipient.PushSubscriptions = append(recipient.PushSubscriptions, sub)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating push subscriptions: %w", err)
+	}
+
+	return &recipient, nil
+}
+
+func toChannels(channels []string) []entity.NotificationChannel {
+	result := make([]entity.NotificationChannel, 0, len(channels))
+	for _, channel := range channels {
+		result = append(result, entity.NotificationChannel(channel))
+	}
+	return result
+}
+
+func scanQuietHours(start, end sql.NullInt16, timeZone sql.NullString) *entity.QuietHours {
+	if !start.Valid || !end.Valid {
+		return nil
+	}
+	return &entity.QuietHours{
+		Start:    int(start.Int16),
+		End:      int(end.Int16),
+		TimeZone: timeZone.String,
+	}
+}
+
+func NewNotificationRepo(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// outboxPayload holds the parts of a domain event stored as JSON.
+type outboxPayload struct {
+	Card         *entity.Card `json:"card,omitempty"`
+	PreviousCard *entity.Card `json:"previous_card,omitempty"`
+	DeletedFiles []string     `json:"deleted_files,omitempty"`
+}
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func (o *OutboxRepository) ClaimEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.DomainEvent, error) {
+	query := `
+		UPDATE outbox_events SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id
+			FROM outbox_events
+			WHERE next_attempt_at <= $1
+			ORDER BY occurred_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, type, aggregate_id, payload, occurred_at, attempts, last_error
+	`
+
+	rows, err := o.db.QueryContext(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*entity.DomainEvent
+	for rows.Next() {
+		var event entity.DomainEvent
+		var data []byte
+		if err = rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.AggregateID,
+			&data,
+			&event.OccurredAt,
+			&event.Attempts,
+			&event.LastError,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning outbox event: %w", err)
+		}
+
+		var payload outboxPayload
+		if err = json.Unmarshal(data, &payload); err != nil {
+			return nil, fmt.Errorf("failed to decode outbox event %s: %w", event.ID, err)
+		}
+		event.Card = payload.Card
+		event.PreviousCard = payload.PreviousCard
+		event.DeletedFiles = payload.DeletedFiles
+		events = append(events, &event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox events: %w", err)
+	}
+
+	// UPDATE ... RETURNING does not keep the order of the subquery.
+	slices.SortFunc(events, func(a, b *entity.DomainEvent) int {
+		return a.OccurredAt.Compare(b.OccurredAt)
+	})
+	return events, nil
+}
+
+func (o *OutboxRepository) Complete(ctx context.Context, id string) error {
+	if _, err := o.db.ExecContext(ctx, `DELETE FROM outbox_events WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to complete outbox event: %w", err)
+	}
+	return nil
+}
+
+func (o *OutboxRepository) Retry(ctx context.Context, event *entity.DomainEvent, nextAttemptAt time.Time) error {
+	query := `UPDATE outbox_events SET attempts = $1, last_error = $2, next_attempt_at = $3 WHERE id = $4`
+
+	if _, err := o.db.ExecContext(ctx, query, event.Attempts, event.LastError, nextAttemptAt, event.ID); err != nil {
+		return fmt.Errorf("failed to reschedule outbox event: %w", err)
+	}
+	return nil
+}
+
+// insertEvents writes the events to the outbox within the transaction of the
+// change they describe.
+func insertEvents(ctx context.Context, tx *sql.Tx, events []*entity.DomainEvent) error {
+	query := `
+		INSERT INTO outbox_events (id, type, aggregate_id, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	for _, event := range events {
+		data, err := json.Marshal(outboxPayload{
+			Card:         event.Card,
+			PreviousCard: event.PreviousCard,
+			DeletedFiles: event.DeletedFiles,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode outbox event: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, query, event.ID, event.Type, event.AggregateID, string(data), event.OccurredAt); err != nil {
+			return fmt.Errorf("failed to insert outbox event: %w", err)
+		}
+	}
+	return nil
+}
+
+func NewOutboxRepo(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
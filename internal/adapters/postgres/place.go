@@ -0,0 +1,271 @@
+package postgres
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// reassignCardsQuery attaches every card inside the given GeoJSON area to the
+// smallest place containing it, which is the most specific one. Cards whose
+// place changes move to their next version.
+const reassignCardsQuery = `
+	WITH target AS (
+		SELECT c.id, (
+			SELECT p.id FROM places p
+			WHERE ST_Contains(p.geom::geometry, c.location::geometry)
+			ORDER BY ST_Area(p.geom)
+			LIMIT 1
+		) AS place_id
+		FROM cards c
+		WHERE ST_Intersects(c.location, ST_SetSRID(ST_GeomFromGeoJSON($1), 4326)::geography)
+	)
+	UPDATE cards c
+	SET place_id = target.place_id, version = c.version + 1
+	FROM target
+	WHERE c.id = target.id AND c.place_id IS DISTINCT FROM target.place_id
+	RETURNING c.id
+`
+
+type PlaceRepository struct {
+	db *sql.DB
+}
+
+func (p *PlaceRepository) Create(ctx context.Context, places ...*entity.Place) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO places (id, parent_id, kind, name, geom)
+		VALUES ($1, $2, $3, $4, ST_Multi(ST_SetSRID(ST_GeomFromGeoJSON($5), 4326))::geography)
+		RETURNING created_at
+	`
+
+	for _, place := range places {
+		if err = tx.QueryRowContext(ctx, query,
+			place.ID,
+			nullString(place.ParentID),
+			place.Kind,
+			place.Name,
+			string(place.Geometry),
+		).Scan(&place.CreatedAt); err != nil {
+			return fmt.Errorf("failed to insert place %q: %w", place.Name, err)
+		}
+	}
+
+	var moved []string
+	for _, place := range places {
+		ids, err := reassignCards(ctx, tx, string(place.Geometry))
+		if err != nil {
+			return fmt.Errorf("failed to assign cards to place: %w", err)
+		}
+		moved = append(moved, ids...)
+	}
+	if err = insertCardsUpdated(ctx, tx, moved); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (p *PlaceRepository) GetByID(ctx context.Context, id string) (*entity.Place, error) {
+	query := `
+		SELECT id, COALESCE(parent_id::text, ''), kind, name, ST_AsGeoJSON(geom), created_at
+		FROM places
+		WHERE id = $1
+	`
+
+	var place entity.Place
+	var geometry string
+	if err := p.db.QueryRowContext(ctx, query, id).Scan(
+		&place.ID,
+		&place.ParentID,
+		&place.Kind,
+		&place.Name,
+		&geometry,
+		&place.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	place.Geometry = []byte(geometry)
+
+	return &place, nil
+}
+
+func (p *PlaceRepository) FindAll(ctx context.Context, withGeometry bool) ([]*entity.Place, error) {
+	query := `
+		SELECT id, COALESCE(parent_id::text, ''), kind, name,
+			CASE WHEN $1 THEN ST_AsGeoJSON(geom) ELSE '' END,
+			created_at
+		FROM places
+		ORDER BY name
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, withGeometry)
+	if err != nil {
+		return nil, fmt.Errorf("error querying places: %w", err)
+	}
+	defer rows.Close()
+
+	var places []*entity.Place
+	for rows.Next() {
+		var place entity.Place
+		var geometry string
+		if err = rows.Scan(
+			&place.ID,
+			&place.ParentID,
+			&place.Kind,
+			&place.Name,
+			&geometry,
+			&place.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning place row: %w", err)
+		}
+		if geometry != "" {
+			place.Geometry = []byte(geometry)
+		}
+		places = append(places, &place)
+	}
+
+	return places, rows.Err()
+}
+
+func (p *PlaceRepository) Update(ctx context.Context, place *entity.Place) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldGeometry string
+	if err = tx.QueryRowContext(ctx, `SELECT ST_AsGeoJSON(geom) FROM places WHERE id = $1 FOR UPDATE`, place.ID).Scan(&oldGeometry); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE places SET
+			parent_id = $1,
+			kind = $2,
+			name = $3,
+			geom = ST_Multi(ST_SetSRID(ST_GeomFromGeoJSON($4), 4326))::geography
+		WHERE id = $5
+	`
+	if _, err = tx.ExecContext(ctx, query,
+		nullString(place.ParentID),
+		place.Kind,
+		place.Name,
+		string(place.Geometry),
+		place.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update place: %w", err)
+	}
+
+	var moved []string
+	for _, geometry := range []string{oldGeometry, string(place.Geometry)} {
+		ids, err := reassignCards(ctx, tx, geometry)
+		if err != nil {
+			return fmt.Errorf("failed to reassign cards: %w", err)
+		}
+		moved = append(moved, ids...)
+	}
+	if err = insertCardsUpdated(ctx, tx, moved); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (p *PlaceRepository) Delete(ctx context.Context, id string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Detaching the cards here rather than through ON DELETE SET NULL moves
+	// them to their next version.
+	detached, err := queryIDs(ctx, tx, `UPDATE cards SET place_id = NULL, version = version + 1 WHERE place_id = $1 RETURNING id`, id)
+	if err != nil {
+		return fmt.Errorf("failed to detach cards: %w", err)
+	}
+
+	var geometry string
+	if err = tx.QueryRowContext(ctx, `DELETE FROM places WHERE id = $1 RETURNING ST_AsGeoJSON(geom)`, id).Scan(&geometry); err != nil {
+		return err
+	}
+
+	reassigned, err := reassignCards(ctx, tx, geometry)
+	if err != nil {
+		return fmt.Errorf("failed to reassign cards: %w", err)
+	}
+	if err = insertCardsUpdated(ctx, tx, append(detached, reassigned...)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func reassignCards(ctx context.Context, tx *sql.Tx, geometry string) ([]string, error) {
+	return queryIDs(ctx, tx, reassignCardsQuery, geometry)
+}
+
+// insertCardsUpdated records a card.updated event for each of the cards, so
+// that their cached copies and map tiles are dropped and subscribers learn
+// about the new place. Cards in the trash are left out.
+func insertCardsUpdated(ctx context.Context, tx *sql.Tx, ids []string) error {
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	events := make([]*entity.DomainEvent, 0, len(ids))
+	for _, id := range ids {
+		card, err := selectCard(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("failed to read reassigned card: %w", err)
+		}
+		if card.DeletedAt != nil {
+			continue
+		}
+		events = append(events, &entity.DomainEvent{
+			ID:          uuid.NewString(),
+			Type:        entity.EventCardUpdated,
+			AggregateID: card.ID,
+			OccurredAt:  time.Now(),
+			Card:        card,
+		})
+	}
+	return insertEvents(ctx, tx, events)
+}
+
+func queryIDs(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func NewPlaceRepo(db *sql.DB) *PlaceRepository {
+	return &PlaceRepository{db: db}
+}
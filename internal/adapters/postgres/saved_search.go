@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// savedSearchQuery is how a card filter is stored in saved_searches.query.
+type savedSearchQuery struct {
+	Status      string     `json:"status,omitempty"`
+	Category    string     `json:"category,omitempty"`
+	PlaceID     string     `json:"place_id,omitempty"`
+	Text        string     `json:"q,omitempty"`
+	Latitude    *float64   `json:"lat,omitempty"`
+	Longitude   *float64   `json:"lon,omitempty"`
+	RadiusM     float64    `json:"radius_m,omitempty"`
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+}
+
+type SavedSearchRepository struct {
+	db *sql.DB
+}
+
+func (s *SavedSearchRepository) Create(ctx context.Context, search *entity.SavedSearch) error {
+	query, err := encodeSavedSearchQuery(search.Filter)
+	if err != nil {
+		return err
+	}
+
+	insertQuery := `
+		INSERT INTO saved_searches (id, user_id, name, query, frequency, last_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+	if err = s.db.QueryRowContext(ctx, insertQuery,
+		search.ID,
+		search.UserID,
+		search.Name,
+		query,
+		search.Frequency,
+		search.LastRunAt,
+	).Scan(&search.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert saved search: %w", err)
+	}
+	return nil
+}
+
+func (s *SavedSearchRepository) FindByUser(ctx context.Context, userID string) ([]*entity.SavedSearch, error) {
+	query := `
+		SELECT id, user_id, name, query, frequency, last_run_at, created_at
+		FROM saved_searches
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	return s.query(ctx, query, userID)
+}
+
+func (s *SavedSearchRepository) CountByUser(ctx context.Context, userID string) (int, error) {
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM saved_searches WHERE user_id = $1`, userID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count saved searches: %w", err)
+	}
+	return n, nil
+}
+
+func (s *SavedSearchRepository) Update(ctx context.Context, search *entity.SavedSearch) error {
+	query, err := encodeSavedSearchQuery(search.Filter)
+	if err != nil {
+		return err
+	}
+
+	updateQuery := `
+		UPDATE saved_searches SET
+			name = $1,
+			query = $2,
+			frequency = $3
+		WHERE id = $4 AND user_id = $5
+		RETURNING last_run_at, created_at
+	`
+	return s.db.QueryRowContext(ctx, updateQuery,
+		search.Name,
+		query,
+		search.Frequency,
+		search.ID,
+		search.UserID,
+	).Scan(&search.LastRunAt, &search.CreatedAt)
+}
+
+func (s *SavedSearchRepository) Delete(ctx context.Context, userID, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM saved_searches WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *SavedSearchRepository) FindDue(ctx context.Context, frequency entity.DigestFrequency, before time.Time, limit int) ([]*entity.SavedSearch, error) {
+	query := `
+		SELECT id, user_id, name, query, frequency, last_run_at, created_at
+		FROM saved_searches
+		WHERE frequency = $1 AND last_run_at < $2
+		ORDER BY last_run_at
+		LIMIT $3
+	`
+	return s.query(ctx, query, frequency, before, limit)
+}
+
+func (s *SavedSearchRepository) MarkRun(ctx context.Context, id string, runAt time.Time) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE saved_searches SET last_run_at = $1 WHERE id = $2`, runAt, id); err != nil {
+		return fmt.Errorf("failed to update saved search run time: %w", err)
+	}
+	return nil
+}
+
+func (s *SavedSearchRepository) query(ctx context.Context, query string, args ...any) ([]*entity.SavedSearch, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	var searches []*entity.SavedSearch
+	for rows.Next() {
+		var search entity.SavedSearch
+		var raw []byte
+		if err = rows.Scan(
+			&search.ID,
+			&search.UserID,
+			&search.Name,
+			&raw,
+			&search.Frequency,
+			&search.LastRunAt,
+			&search.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning saved search row: %w", err)
+		}
+		if search.Filter, err = decodeSavedSearchQuery(raw); err != nil {
+			return nil, err
+		}
+		searches = append(searches, &search)
+	}
+	return searches, rows.Err()
+}
+
+func encodeSavedSearchQuery(filter entity.CardFilter) ([]byte, error) {
+	q := savedSearchQuery{
+		Status:   string(filter.Status),
+		Category: string(filter.Category),
+		PlaceID:  filter.PlaceID,
+		Text:     filter.Text,
+	}
+	if filter.Area != nil {
+		q.Latitude = &filter.Area.Center.Latitude
+		q.Longitude = &filter.Area.Center.Longitude
+		q.RadiusM = filter.Area.Radius.Meters()
+	}
+	if !filter.CreatedFrom.IsZero() {
+		q.CreatedFrom = &filter.CreatedFrom
+	}
+	if !filter.CreatedTo.IsZero() {
+		q.CreatedTo = &filter.CreatedTo
+	}
+
+	data, err := json.Marshal(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode saved search query: %w", err)
+	}
+	return data, nil
+}
+
+func decodeSavedSearchQuery(data []byte) (entity.CardFilter, error) {
+	var q savedSearchQuery
+	if err := json.Unmarshal(data, &q); err != nil {
+		return entity.CardFilter{}, fmt.Errorf("failed to decode saved search query: %w", err)
+	}
+
+	filter := entity.CardFilter{
+		Status:   entity.CardStatus(q.Status),
+		Category: entity.CardCategory(q.Category),
+		PlaceID:  q.PlaceID,
+		Text:     q.Text,
+	}
+	if q.Latitude != nil && q.Longitude != nil {
+		filter.Area = &entity.CircleArea{
+			Center: entity.Location{Latitude: *q.Latitude, Longitude: *q.Longitude},
+			Radius: entity.Distance(q.RadiusM),
+		}
+	}
+	if q.CreatedFrom != nil {
+		filter.CreatedFrom = *q.CreatedFrom
+	}
+	if q.CreatedTo != nil {
+		filter.CreatedTo = *q.CreatedTo
+	}
+	return filter, nil
+}
+
+func NewSavedSearchRepo(db *sql.DB) *SavedSearchRepository {
+	return &SavedSearchRepository{db: db}
+}
@@ -4,8 +4,10 @@ import (
 	"LostAndFound/internal/domain/entity"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 )
 
 type UserRepository struct {
@@ -19,7 +21,7 @@ func (u UserRepository) FindByEmail(ctx context.Context, email string) (*entity.
 	}
 	defer tx.Rollback()
 
-	query := `SELECT id, email, password_hash, name, surname, phone, telegram, is_admin, created_at FROM users WHERE email = $1`
+	query := `SELECT id, email, password_hash, name, surname, phone, telegram, is_admin, created_at, avatar_url, avatar_thumb_url, banned_at, version FROM users WHERE email = $1`
 	row := tx.QueryRowContext(ctx, query, email)
 	if err = row.Err(); err != nil {
 		return nil, fmt.Errorf("failed finding user by email: %w", err)
@@ -36,6 +38,10 @@ func (u UserRepository) FindByEmail(ctx context.Context, email string) (*entity.
 		&user.Telegram,
 		&user.IsAdmin,
 		&user.CreatedAt,
+		&user.AvatarURL,
+		&user.AvatarThumbURL,
+		&user.BannedAt,
+		&user.Version,
 	); err != nil {
 		return nil, fmt.Errorf("failed finding user by email: %w", err)
 	}
@@ -50,7 +56,7 @@ func (u UserRepository) FindByID(ctx context.Context, id string) (*entity.User,
 	}
 	defer tx.Rollback()
 
-	query := `SELECT id, email, password_hash, name, surname, phone, telegram, is_admin, created_at FROM users WHERE id = $1`
+	query := `SELECT id, email, password_hash, name, surname, phone, telegram, is_admin, created_at, avatar_url, avatar_thumb_url, banned_at, version FROM users WHERE id = $1`
 	row := tx.QueryRowContext(ctx, query, id)
 	if err = row.Err(); err != nil {
 		return nil, fmt.Errorf("failed finding user by id: %w", err)
@@ -67,6 +73,10 @@ func (u UserRepository) FindByID(ctx context.Context, id string) (*entity.User,
 		&user.Telegram,
 		&user.IsAdmin,
 		&user.CreatedAt,
+		&user.AvatarURL,
+		&user.AvatarThumbURL,
+		&user.BannedAt,
+		&user.Version,
 	); err != nil {
 		return nil, fmt.Errorf("failed finding user by id: %w", err)
 	}
@@ -97,7 +107,9 @@ func (u UserRepository) Create(ctx context.Context, user *entity.User) error {
 	return tx.Commit()
 }
 
-func (u UserRepository) Update(ctx context.Context, updated *entity.User) error {
+// Update saves the user if it still has the version it was read with and
+// moves it to the next version. A changed version gives sql.ErrNoRows.
+func (u UserRepository) Update(ctx context.Context, updated *entity.User, events ...*entity.DomainEvent) error {
 	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
 		slog.Error(err.Error())
@@ -105,12 +117,19 @@ func (u UserRepository) Update(ctx context.Context, updated *entity.User) error
 	}
 	defer tx.Rollback()
 
-	query := `UPDATE users SET email = $1, password_hash = $2, name = $3, surname = $4, phone = $5, telegram = $6 WHERE id = $7`
+	query := `UPDATE users SET email = $1, password_hash = $2, name = $3, surname = $4, phone = $5, telegram = $6, avatar_url = $7, avatar_thumb_url = $8, version = version + 1
+	          WHERE id = $9 AND version = $10
+	          RETURNING version`
 
-	if _, err = tx.ExecContext(ctx, query, updated.Email, updated.Password, updated.Name, updated.Surname, updated.Phone, updated.Telegram, updated.ID); err != nil {
-		slog.Error(err.Error())
+	if err = tx.QueryRowContext(ctx, query, updated.Email, updated.Password, updated.Name, updated.Surname, updated.Phone, updated.Telegram, updated.AvatarURL, updated.AvatarThumbURL, updated.ID, updated.Version).Scan(&updated.Version); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Error(err.Error())
+		}
 		return fmt.Errorf("failed updating user: %w", err)
 	}
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
@@ -131,6 +150,116 @@ func (u UserRepository) Delete(ctx context.Context, id string) error {
 	return tx.Commit()
 }
 
+func (u UserRepository) FindByTelegramChatID(ctx context.Context, chatID int64) (*entity.User, error) {
+	query := `SELECT id, email, password_hash, name, surname, phone, telegram, is_admin, created_at, avatar_url, avatar_thumb_url, banned_at, version FROM users WHERE telegram_chat_id = $1`
+
+	var user entity.User
+	if err := u.db.QueryRowContext(ctx, query, chatID).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Password,
+		&user.Name,
+		&user.Surname,
+		&user.Phone,
+		&user.Telegram,
+		&user.IsAdmin,
+		&user.CreatedAt,
+		&user.AvatarURL,
+		&user.AvatarThumbURL,
+		&user.BannedAt,
+		&user.Version,
+	); err != nil {
+		return nil, fmt.Errorf("failed finding user by telegram chat: %w", err)
+	}
+	return &user, nil
+}
+
+func (u UserRepository) SetTelegramChatID(ctx context.Context, userID string, chatID int64) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if chatID != 0 {
+		if _, err = tx.ExecContext(ctx, `UPDATE users SET telegram_chat_id = NULL WHERE telegram_chat_id = $1 AND id <> $2`, chatID, userID); err != nil {
+			return fmt.Errorf("failed unlinking telegram chat: %w", err)
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE users SET telegram_chat_id = $1 WHERE id = $2`, sql.NullInt64{Int64: chatID, Valid: chatID != 0}, userID)
+	if err != nil {
+		return fmt.Errorf("failed linking telegram chat: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return tx.Commit()
+}
+
+func (u UserRepository) FindAll(ctx context.Context) ([]*entity.User, error) {
+	query := `SELECT id, email, password_hash, name, surname, phone, telegram, is_admin, created_at, avatar_url, avatar_thumb_url, banned_at, version FROM users ORDER BY created_at`
+
+	rows, err := u.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed finding users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*entity.User
+	for rows.Next() {
+		var user entity.User
+		if err = rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.Password,
+			&user.Name,
+			&user.Surname,
+			&user.Phone,
+			&user.Telegram,
+			&user.IsAdmin,
+			&user.CreatedAt,
+			&user.AvatarURL,
+			&user.AvatarThumbURL,
+			&user.BannedAt,
+			&user.Version,
+		); err != nil {
+			return nil, fmt.Errorf("failed scanning user: %w", err)
+		}
+		users = append(users, &user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed iterating users: %w", err)
+	}
+	return users, nil
+}
+
+func (u UserRepository) SetAdmin(ctx context.Context, id string, isAdmin bool) error {
+	res, err := u.db.ExecContext(ctx, `UPDATE users SET is_admin = $1 WHERE id = $2`, isAdmin, id)
+	if err != nil {
+		return fmt.Errorf("failed updating user role: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (u UserRepository) SetBanned(ctx context.Context, id string, bannedAt *time.Time) error {
+	res, err := u.db.ExecContext(ctx, `UPDATE users SET banned_at = $1 WHERE id = $2`, bannedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed updating user ban: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 func NewUserRepo(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
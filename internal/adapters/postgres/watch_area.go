@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+const selectWatchAreaColumns = `
+	w.id, w.user_id, w.name,
+	ST_Y(w.center::geometry), ST_X(w.center::geometry), COALESCE(w.radius_m, 0),
+	COALESCE(w.place_id::text, ''), COALESCE(p.name, ''),
+	COALESCE(w.category, ''), COALESCE(w.status, ''), w.created_at
+`
+
+type WatchAreaRepository struct {
+	db *sql.DB
+}
+
+func (w *WatchAreaRepository) Create(ctx context.Context, area *entity.WatchArea) error {
+	query := `
+		INSERT INTO watch_areas (id, user_id, name, center, radius_m, place_id, category, status)
+		VALUES ($1, $2, $3, ST_SetSRID(ST_MakePoint($4::float8, $5::float8), 4326), $6, $7, $8, $9)
+		RETURNING created_at
+	`
+
+	if err := w.db.QueryRowContext(ctx, query,
+		area.ID,
+		area.UserID,
+		area.Name,
+		nullLongitude(area.Center),
+		nullLatitude(area.Center),
+		nullRadius(area),
+		nullString(area.PlaceID),
+		nullString(string(area.Category)),
+		nullString(string(area.Status)),
+	).Scan(&area.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert watch area: %w", err)
+	}
+	return nil
+}
+
+func (w *WatchAreaRepository) FindByUser(ctx context.Context, userID string) ([]*entity.WatchArea, error) {
+	query := `
+		SELECT ` + selectWatchAreaColumns + `
+		FROM watch_areas w
+		LEFT JOIN places p ON p.id = w.place_id
+		WHERE w.user_id = $1
+		ORDER BY w.created_at
+	`
+
+	rows, err := w.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying watch areas: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWatchAreas(rows)
+}
+
+func (w *WatchAreaRepository) CountByUser(ctx context.Context, userID string) (int, error) {
+	var n int
+	if err := w.db.QueryRowContext(ctx, `SELECT count(*) FROM watch_areas WHERE user_id = $1`, userID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count watch areas: %w", err)
+	}
+	return n, nil
+}
+
+func (w *WatchAreaRepository) Update(ctx context.Context, area *entity.WatchArea) error {
+	query := `
+		UPDATE watch_areas SET
+			name = $1,
+			center = ST_SetSRID(ST_MakePoint($2::float8, $3::float8), 4326),
+			radius_m = $4,
+			place_id = $5,
+			category = $6,
+			status = $7
+		WHERE id = $8 AND user_id = $9
+		RETURNING created_at
+	`
+
+	return w.db.QueryRowContext(ctx, query,
+		area.Name,
+		nullLongitude(area.Center),
+		nullLatitude(area.Center),
+		nullRadius(area),
+		nullString(area.PlaceID),
+		nullString(string(area.Category)),
+		nullString(string(area.Status)),
+		area.ID,
+		area.UserID,
+	).Scan(&area.CreatedAt)
+}
+
+func (w *WatchAreaRepository) Delete(ctx context.Context, userID, id string) error {
+	res, err := w.db.ExecContext(ctx, `DELETE FROM watch_areas WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete watch area: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (w *WatchAreaRepository) FindMatching(ctx context.Context, card *entity.Card) ([]*entity.WatchArea, error) {
+	if card.Location == nil {
+		return nil, nil
+	}
+
+	query := `
+		SELECT DISTINCT ON (w.user_id) ` + selectWatchAreaColumns + `
+		FROM watch_areas w
+		LEFT JOIN places p ON p.id = w.place_id
+		WHERE w.user_id <> $3
+			AND (w.category IS NULL OR w.category = $4)
+			AND (w.status IS NULL OR w.status = $5)
+			AND (
+				ST_DWithin(w.center, ST_MakePoint($1, $2)::geography, w.radius_m)
+				OR ST_Covers(p.geom, ST_MakePoint($1, $2)::geography)
+			)
+		ORDER BY w.user_id, w.created_at
+	`
+
+	rows, err := w.db.QueryContext(ctx, query,
+		card.Location.Longitude,
+		card.Location.Latitude,
+		card.Owner.ID,
+		card.Category,
+		card.Status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying matching watch areas: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWatchAreas(rows)
+}
+
+func scanWatchAreas(rows *sql.Rows) ([]*entity.WatchArea, error) {
+	var areas []*entity.WatchArea
+	for rows.Next() {
+		var area entity.WatchArea
+		var lat, lon sql.NullFloat64
+		var radius float64
+		if err := rows.Scan(
+			&area.ID,
+			&area.UserID,
+			&area.Name,
+			&lat,
+			&lon,
+			&radius,
+			&area.PlaceID,
+			&area.PlaceName,
+			&area.Category,
+			&area.Status,
+			&area.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning watch area row: %w", err)
+		}
+		area.Center = scanLocation(lat, lon)
+		area.Radius = entity.Distance(radius)
+		areas = append(areas, &area)
+	}
+	return areas, rows.Err()
+}
+
+func nullRadius(area *entity.WatchArea) sql.NullFloat64 {
+	if area.Center == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: area.Radius.Meters(), Valid: true}
+}
+
+func NewWatchAreaRepo(db *sql.DB) *WatchAreaRepository {
+	return &WatchAreaRepository{db: db}
+}
@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const selectWebhookColumns = `id, url, secret, events, description, active, created_at`
+
+const selectWebhookDeliveryColumns = `
+	id, webhook_id, event_id, event_type, payload, status, attempts,
+	response_status, last_error, next_attempt_at, delivered_at, created_at
+`
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func (w *WebhookRepository) Create(ctx context.Context, webhook *entity.Webhook) error {
+	query := `
+		INSERT INTO webhooks (id, url, secret, events, description, active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+
+	if err := w.db.QueryRowContext(ctx, query,
+		webhook.ID,
+		webhook.URL,
+		webhook.Secret,
+		pq.Array(fromEventTypes(webhook.Events)),
+		webhook.Description,
+		webhook.Active,
+	).Scan(&webhook.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert webhook: %w", err)
+	}
+	return nil
+}
+
+func (w *WebhookRepository) FindAll(ctx context.Context) ([]*entity.Webhook, error) {
+	rows, err := w.db.QueryContext(ctx, `SELECT `+selectWebhookColumns+` FROM webhooks ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*entity.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (w *WebhookRepository) GetByID(ctx context.Context, id string) (*entity.Webhook, error) {
+	row := w.db.QueryRowContext(ctx, `SELECT `+selectWebhookColumns+` FROM webhooks WHERE id = $1`, id)
+	return scanWebhook(row)
+}
+
+func (w *WebhookRepository) Update(ctx context.Context, webhook *entity.Webhook) error {
+	query := `
+		UPDATE webhooks SET
+			url = $1,
+			events = $2,
+			description = $3,
+			active = $4
+		WHERE id = $5
+		RETURNING secret, created_at
+	`
+
+	return w.db.QueryRowContext(ctx, query,
+		webhook.URL,
+		pq.Array(fromEventTypes(webhook.Events)),
+		webhook.Description,
+		webhook.Active,
+		webhook.ID,
+	).Scan(&webhook.Secret, &webhook.CreatedAt)
+}
+
+func (w *WebhookRepository) Delete(ctx context.Context, id string) error {
+	res, err := w.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (w *WebhookRepository) Enqueue(ctx context.Context, eventID string, eventType entity.CardEventType, payload []byte) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $3
+		FROM webhooks
+		WHERE active AND (cardinality(events) = 0 OR $2 = ANY(events))
+		ON CONFLICT (webhook_id, event_id) WHERE replay_of IS NULL DO NOTHING
+	`
+
+	if _, err := w.db.ExecContext(ctx, query, eventID, eventType, string(payload)); err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+func (w *WebhookRepository) Replay(ctx context.Context, webhookID, deliveryID string) (*entity.WebhookDelivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, replay_of)
+		SELECT webhook_id, event_id, event_type, payload, id
+		FROM webhook_deliveries
+		WHERE id = $1 AND webhook_id = $2
+		RETURNING ` + selectWebhookDeliveryColumns
+
+	return scanWebhookDelivery(w.db.QueryRowContext(ctx, query, deliveryID, webhookID))
+}
+
+func (w *WebhookRepository) FindDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*entity.WebhookDelivery, error) {
+	query := `
+		SELECT ` + selectWebhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := w.db.QueryContext(ctx, query, webhookID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (w *WebhookRepository) ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + selectWebhookDeliveryColumns
+
+	rows, err := w.db.QueryContext(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (w *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries SET
+			status = $1,
+			attempts = $2,
+			response_status = $3,
+			last_error = $4,
+			next_attempt_at = $5,
+			delivered_at = $6
+		WHERE id = $7
+	`
+
+	if _, err := w.db.ExecContext(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.DeliveredAt,
+		delivery.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func scanWebhook(row interface{ Scan(...any) error }) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+	var events []string
+	if err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		pq.Array(&events),
+		&webhook.Description,
+		&webhook.Active,
+		&webhook.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		webhook.Events = append(webhook.Events, entity.CardEventType(event))
+	}
+	return &webhook, nil
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]*entity.WebhookDelivery, error) {
+	var deliveries []*entity.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanWebhookDelivery(row interface{ Scan(...any) error }) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	if err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.ResponseStatus,
+		&delivery.LastError,
+		&delivery.NextAttemptAt,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func fromEventTypes(events []entity.CardEventType) []string {
+	result := make([]string, 0, len(events))
+	for _, event := range events {
+		result = append(result, string(event))
+	}
+	return result
+}
+
+func NewWebhookRepo(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
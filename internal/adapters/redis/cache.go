@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -62,6 +63,22 @@ func (c *CacheRepository) IsTokenBlacklisted(ctx context.Context, token string)
 	return exists == 1, nil
 }
 
+func (c *CacheRepository) BlockUser(ctx context.Context, userID string, ttl time.Duration) error {
+	return c.client.Set(ctx, "blocked:"+userID, "1", ttl).Err()
+}
+
+func (c *CacheRepository) UnblockUser(ctx context.Context, userID string) error {
+	return c.client.Del(ctx, "blocked:"+userID).Err()
+}
+
+func (c *CacheRepository) IsUserBlocked(ctx context.Context, userID string) (bool, error) {
+	exists, err := c.client.Exists(ctx, "blocked:"+userID).Result()
+	if err != nil {
+		return false, err
+	}
+	return exists == 1, nil
+}
+
 func (c *CacheRepository) SaveCard(ctx context.Context, card *entity.Card) error {
 	data, err := json.Marshal(card)
 	if err != nil {
@@ -89,6 +106,72 @@ func (c *CacheRepository) DeleteCard(ctx context.Context, id string) error {
 	return c.client.Del(ctx, "card:"+id).Err()
 }
 
+func (c *CacheRepository) SaveTile(ctx context.Context, tile entity.Tile, data []byte) error {
+	return c.client.Set(ctx, tileKey(tile), data, time.Hour).Err()
+}
+
+func (c *CacheRepository) GetTile(ctx context.Context, tile entity.Tile) ([]byte, bool, error) {
+	data, err := c.client.Get(ctx, tileKey(tile)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (c *CacheRepository) DeleteTiles(ctx context.Context, tiles []entity.Tile) error {
+	if len(tiles) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(tiles))
+	for _, t := range tiles {
+		keys = append(keys, tileKey(t))
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+func (c *CacheRepository) SaveTelegramLinkCode(ctx context.Context, code, userID string, ttl time.Duration) error {
+	return c.client.Set(ctx, "telegram:link:"+code, userID, ttl).Err()
+}
+
+func (c *CacheRepository) TakeTelegramLinkCode(ctx context.Context, code string) (string, error) {
+	userID, err := c.client.GetDel(ctx, "telegram:link:"+code).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return userID, err
+}
+
+func (c *CacheRepository) SaveStreamTicket(ctx context.Context, ticket *entity.StreamTicket, ttl time.Duration) error {
+	data, err := json.Marshal(ticket)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, "stream:ticket:"+ticket.Ticket, data, ttl).Err()
+}
+
+func (c *CacheRepository) TakeStreamTicket(ctx context.Context, ticket string) (*entity.StreamTicket, error) {
+	data, err := c.client.GetDel(ctx, "stream:ticket:"+ticket).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var t entity.StreamTicket
+	if err = json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	t.Ticket = ticket
+	return &t, nil
+}
+
+func tileKey(t entity.Tile) string {
+	return fmt.Sprintf("tile:cards:%d:%d:%d", t.Z, t.X, t.Y)
+}
+
 func NewCacheRepo(client *redis.Client) *CacheRepository {
 	return &CacheRepository{client: client}
 }
@@ -0,0 +1,70 @@
+package myredis
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const cardEventsChannel = "cards:events"
+
+// CardEventBus broadcasts card events over Redis pub/sub. Delivery is at most
+// once: instances that are not subscribed at the moment miss the event.
+type CardEventBus struct {
+	client *redis.Client
+}
+
+func (b *CardEventBus) Publish(ctx context.Context, event *entity.CardEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode card event: %w", err)
+	}
+	return b.client.Publish(ctx, cardEventsChannel, data).Err()
+}
+
+func (b *CardEventBus) Subscribe(ctx context.Context) (<-chan *entity.CardEvent, error) {
+	pubsub := b.client.Subscribe(ctx, cardEventsChannel)
+	// Receive waits for the subscription to be confirmed, so no event
+	// published after Subscribe returns is missed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to card events: %w", err)
+	}
+
+	events := make(chan *entity.CardEvent)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event entity.CardEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					slog.Error("failed to decode card event", "error", err)
+					continue
+				}
+				select {
+				case events <- &event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+func NewCardEventBus(client *redis.Client) *CardEventBus {
+	return &CardEventBus{client: client}
+}
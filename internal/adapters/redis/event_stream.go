@@ -0,0 +1,48 @@
+package myredis
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	eventStreamKey = "events:domain"
+	// eventStreamMaxLen caps the stream; older entries are trimmed
+	// approximately, which is cheap for Redis.
+	eventStreamMaxLen = 100000
+)
+
+// EventStream appends domain events to a Redis stream. An event may be
+// appended more than once, so readers should drop repeated ids.
+type EventStream struct {
+	client *redis.Client
+}
+
+func (s *EventStream) Append(ctx context.Context, event *entity.DomainEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode domain event: %w", err)
+	}
+
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: eventStreamKey,
+		MaxLen: eventStreamMaxLen,
+		Approx: true,
+		Values: map[string]any{
+			"id":           event.ID,
+			"type":         string(event.Type),
+			"aggregate_id": event.AggregateID,
+			"occurred_at":  event.OccurredAt.UTC().Format(time.RFC3339Nano),
+			"payload":      data,
+		},
+	}).Err()
+}
+
+func NewEventStream(client *redis.Client) *EventStream {
+	return &EventStream{client: client}
+}
@@ -0,0 +1,28 @@
+package myredis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const lockKeyPrefix = "lock:"
+
+// Locker takes locks with SET NX, which is enough for a single Redis node.
+type Locker struct {
+	client *redis.Client
+}
+
+func (l *Locker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ok, err := l.client.SetNX(ctx, lockKeyPrefix+key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to take lock %s: %w", key, err)
+	}
+	return ok, nil
+}
+
+func NewLocker(client *redis.Client) *Locker {
+	return &Locker{client: client}
+}
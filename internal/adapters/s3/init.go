@@ -3,10 +3,14 @@ package mys3
 import (
 	s "LostAndFound/internal/config/storage_config"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -41,5 +45,70 @@ func NewS3Client(cfg s.S3Config) (*s3.S3, error) {
 		}
 	}
 
+	// Without the statement public files are not served, but everything
+	// else works, so a missing right to change the policy is not fatal.
+	if cfg.ManagePolicy {
+		if err = SetPublicReadPolicy(ctx, client, cfg.Bucket); err != nil {
+			slog.Warn("failed to set public read policy, run lafctl files policy", "bucket", cfg.Bucket, "error", err)
+		}
+	}
+
 	return client, nil
 }
+
+// publicReadStatementID identifies the statement SetPublicReadPolicy adds.
+const publicReadStatementID = "PublicReadUploads"
+
+// SetPublicReadPolicy makes the public upload prefix anonymously readable.
+// Private files live outside of it and are served through presigned GET
+// URLs. The statement is added to the bucket policy once; the rest of the
+// policy, and the statement itself when it is already there, is kept as is.
+func SetPublicReadPolicy(ctx context.Context, client *s3.S3, bucket string) error {
+	policy := map[string]any{"Version": "2012-10-17"}
+	out, err := client.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String(bucket)})
+	var aerr awserr.Error
+	switch {
+	case errors.As(err, &aerr) && aerr.Code() == "NoSuchBucketPolicy":
+	case err != nil:
+		return fmt.Errorf("failed to get bucket policy: %w", err)
+	default:
+		if err = json.Unmarshal([]byte(aws.StringValue(out.Policy)), &policy); err != nil {
+			return fmt.Errorf("failed to parse bucket policy: %w", err)
+		}
+	}
+
+	// A policy with a single statement may hold it as an object.
+	var statements []any
+	switch st := policy["Statement"].(type) {
+	case []any:
+		statements = st
+	case map[string]any:
+		statements = []any{st}
+	}
+	for _, st := range statements {
+		if st, ok := st.(map[string]any); ok && st["Sid"] == publicReadStatementID {
+			return nil
+		}
+	}
+
+	policy["Statement"] = append(statements, map[string]any{
+		"Sid":       publicReadStatementID,
+		"Effect":    "Allow",
+		"Principal": map[string]any{"AWS": []string{"*"}},
+		"Action":    []string{"s3:GetObject"},
+		"Resource":  []string{fmt.Sprintf("arn:aws:s3:::%s/users/*", bucket)},
+	})
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to build bucket policy: %w", err)
+	}
+
+	_, err = client.PutBucketPolicyWithContext(ctx, &s3.PutBucketPolicyInput{
+		Bucket: aws.String(bucket),
+		Policy: aws.String(string(data)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set bucket policy: %w", err)
+	}
+	return nil
+}
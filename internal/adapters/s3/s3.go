@@ -3,20 +3,24 @@ package mys3
 import (
 	e "LostAndFound/internal/common/errors"
 	storage_config "LostAndFound/internal/config/storage_config"
+	"LostAndFound/internal/domain/entity"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
 type FileRepository struct {
-	client  *s3.S3
-	bucket  string
-	baseURL string
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	baseURL  string
 }
 
 func (f FileRepository) GeneratePresignedPutURL(key string, contentType string, expires time.Duration) (string, error) {
@@ -34,6 +38,50 @@ func (f FileRepository) GeneratePresignedPutURL(key string, contentType string,
 	return urlStr, nil
 }
 
+func (f FileRepository) GeneratePresignedGetURL(key string, expires time.Duration) (string, error) {
+	req, _ := f.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(f.bucket),
+		Key:    aws.String(key),
+	})
+
+	urlStr, err := req.Presign(expires)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	return urlStr, nil
+}
+
+func (f FileRepository) GetFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := f.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(f.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, e.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	return out.Body, nil
+}
+
+// Put streams body into the bucket. Bodies of unknown length are sent as a
+// multipart upload, so the whole file never has to be held in memory.
+func (f FileRepository) Put(ctx context.Context, key, contentType string, body io.Reader) error {
+	_, err := f.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(f.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		Body:        body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put file: %w", err)
+	}
+	return nil
+}
+
 func (f FileRepository) DeleteFile(ctx context.Context, key string) error {
 	_, err := f.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(f.bucket),
@@ -66,6 +114,27 @@ func (f FileRepository) FileExists(ctx context.Context, key string) (bool, error
 	return true, nil
 }
 
+func (f FileRepository) ListFiles(ctx context.Context, prefix string) ([]entity.StoredFile, error) {
+	var files []entity.StoredFile
+	err := f.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(f.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			files = append(files, entity.StoredFile{
+				Key:          aws.StringValue(obj.Key),
+				Size:         aws.Int64Value(obj.Size),
+				LastModified: aws.TimeValue(obj.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return files, nil
+}
+
 func (f FileRepository) GetBaseURL() string {
 	return f.baseURL
 }
@@ -76,8 +145,9 @@ func (f FileRepository) GetBucket() string {
 
 func NewFileStorage(client *s3.S3, config storage_config.S3Config) *FileRepository {
 	return &FileRepository{
-		client:  client,
-		bucket:  config.Bucket,
-		baseURL: config.Endpoint + "/" + config.Bucket,
+		client:   client,
+		uploader: s3manager.NewUploaderWithClient(client),
+		bucket:   config.Bucket,
+		baseURL:  config.Endpoint + "/" + config.Bucket,
 	}
 }
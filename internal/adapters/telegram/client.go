@@ -0,0 +1,135 @@
+package telegram
+
+import (
+	"LostAndFound/internal/config/server_config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pollTimeout is how long getUpdates waits for new updates.
+const pollTimeout = 30 * time.Second
+
+// APIError is an error reply of the Bot API.
+type APIError struct {
+	Code        int
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram api error %d: %s", e.Code, e.Description)
+}
+
+// Client is a minimal Telegram Bot API client. The API URL is configurable,
+// so it can be pointed at a local Bot API server or a fake one in tests.
+type Client struct {
+	apiURL string
+	token  string
+	http   *http.Client
+}
+
+// Call invokes a Bot API method with JSON parameters and decodes its result
+// into result unless it is nil.
+func (c *Client) Call(ctx context.Context, method string, params, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s parameters: %w", method, err)
+	}
+
+	url := c.apiURL + "/bot" + c.token + "/" + method
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if !reply.OK {
+		return &APIError{Code: reply.ErrorCode, Description: reply.Description}
+	}
+	if result != nil {
+		if err = json.Unmarshal(reply.Result, result); err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// SendMessage sends a text message; markup is an optional reply keyboard.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string, markup any) error {
+	params := map[string]any{
+		"chat_id": chatID,
+		"text":    text,
+	}
+	if markup != nil {
+		params["reply_markup"] = markup
+	}
+	return c.Call(ctx, "sendMessage", params, nil)
+}
+
+// GetUpdates long-polls for messages after offset.
+func (c *Client) GetUpdates(ctx context.Context, offset int64) ([]Update, error) {
+	var updates []Update
+	err := c.Call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(pollTimeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+func (c *Client) GetFile(ctx context.Context, fileID string) (*File, error) {
+	var file File
+	if err := c.Call(ctx, "getFile", map[string]any{"file_id": fileID}, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// DownloadFile opens the contents of a file returned by GetFile. The caller
+// closes the reader.
+func (c *Client) DownloadFile(ctx context.Context, file *File) (io.ReadCloser, error) {
+	url := c.apiURL + "/file/bot" + c.token + "/" + file.FilePath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("file download failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("file download failed: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func NewClient(cfg server_config.TelegramConfig) *Client {
+	return &Client{
+		apiURL: strings.TrimRight(cfg.APIURL, "/"),
+		token:  cfg.BotToken,
+		// Long polling keeps requests open for pollTimeout.
+		http: &http.Client{Timeout: pollTimeout + 30*time.Second},
+	}
+}
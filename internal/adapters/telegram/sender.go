@@ -0,0 +1,42 @@
+package telegram
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sender delivers notifications as bot messages to linked chats.
+type Sender struct {
+	client *Client
+}
+
+func (s *Sender) Channel() entity.NotificationChannel {
+	return entity.ChannelTelegram
+}
+
+func (s *Sender) Send(ctx context.Context, recipient *entity.Recipient, n *entity.Notification) error {
+	if recipient.TelegramChatID == 0 {
+		return fmt.Errorf("%w: telegram account is not linked", e.ErrRecipientUnreachable)
+	}
+
+	text := n.Title
+	if n.Body != "" {
+		text += "\n\n" + n.Body
+	}
+
+	err := s.client.SendMessage(ctx, recipient.TelegramChatID, text, nil)
+	var apiErr *APIError
+	// The user blocked the bot or deleted the chat.
+	if errors.As(err, &apiErr) && (apiErr.Code == http.StatusForbidden || apiErr.Code == http.StatusBadRequest) {
+		return fmt.Errorf("%w: %v", e.ErrRecipientUnreachable, err)
+	}
+	return err
+}
+
+func NewSender(client *Client) *Sender {
+	return &Sender{client: client}
+}
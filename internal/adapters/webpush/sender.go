@@ -0,0 +1,259 @@
+package webpush
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/config/server_config"
+	"LostAndFound/internal/domain/entity"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// recordSize is the aes128gcm record size; a notification always fits
+	// into a single record.
+	recordSize = 4096
+	// messageTTL is how long the push service keeps an undelivered message.
+	messageTTL = 24 * time.Hour
+)
+
+// Sender delivers notifications to browsers through the Web Push protocol
+// with VAPID authentication and aes128gcm payload encryption (RFC 8291).
+type Sender struct {
+	publicKey  string
+	privateKey *ecdsa.PrivateKey
+	subject    string
+	http       *http.Client
+}
+
+type payload struct {
+	NotificationID string `json:"notification_id"`
+	Kind           string `json:"kind"`
+	Title          string `json:"title"`
+	Body           string `json:"body"`
+	CardID         string `json:"card_id,omitempty"`
+}
+
+func (s *Sender) Channel() entity.NotificationChannel {
+	return entity.ChannelWebPush
+}
+
+// Send pushes the notification to every subscription of the recipient and
+// succeeds if at least one of them accepted it.
+func (s *Sender) Send(ctx context.Context, recipient *entity.Recipient, n *entity.Notification) error {
+	if len(recipient.PushSubscriptions) == 0 {
+		return fmt.Errorf("%w: no push subscriptions", e.ErrRecipientUnreachable)
+	}
+
+	message, err := json.Marshal(payload{
+		NotificationID: n.ID,
+		Kind:           string(n.Kind),
+		Title:          n.Title,
+		Body:           n.Body,
+		CardID:         n.CardID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode push payload: %w", err)
+	}
+
+	var errs []error
+	for _, sub := range recipient.PushSubscriptions {
+		if err = s.push(ctx, sub, message); err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+
+	err = errors.Join(errs...)
+	for _, sendErr := range errs {
+		if !errors.Is(sendErr, e.ErrRecipientUnreachable) {
+			return err
+		}
+	}
+	// Every subscription is gone, so retrying would not help.
+	return fmt.Errorf("%w: %v", e.ErrRecipientUnreachable, err)
+}
+
+func (s *Sender) push(ctx context.Context, sub entity.PushSubscription, message []byte) error {
+	body, err := encrypt(sub, message)
+	if err != nil {
+		return fmt.Errorf("%w: %v", e.ErrRecipientUnreachable, err)
+	}
+
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: invalid endpoint: %v", e.ErrRecipientUnreachable, err)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": s.subject,
+	}).SignedString(s.privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign vapid token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, s.publicKey))
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(int(messageTTL.Seconds())))
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("push request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return fmt.Errorf("%w: subscription expired", e.ErrRecipientUnreachable)
+	default:
+		return fmt.Errorf("push service responded with %s", resp.Status)
+	}
+}
+
+// encrypt builds an aes128gcm body for the subscription as described in
+// RFC 8291 and RFC 8188.
+func encrypt(sub entity.PushSubscription, message []byte) ([]byte, error) {
+	uaPublicBytes, err := decodeKey(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeKey(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublicBytes...), asPublic...)
+	ikm, err := expand(sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := expand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single record ends with the 0x02 delimiter.
+	plaintext := append(append([]byte{}, message...), 0x02)
+	if len(plaintext)+gcm.Overhead() > recordSize {
+		return nil, errors.New("payload is too large")
+	}
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func expand(secret, salt, info []byte, size int) ([]byte, error) {
+	out := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// decodeKey accepts both padded and unpadded base64url as browsers and
+// libraries differ in what they produce.
+func decodeKey(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// NewSender parses the VAPID key pair from the config.
+func NewSender(cfg server_config.WebPushConfig) (*Sender, error) {
+	raw, err := decodeKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+
+	public := key.PublicKey().Bytes()
+	if cfg.PublicKey != "" && strings.TrimRight(cfg.PublicKey, "=") != base64.RawURLEncoding.EncodeToString(public) {
+		return nil, errors.New("vapid public key does not match the private key")
+	}
+
+	return &Sender{
+		publicKey: base64.RawURLEncoding.EncodeToString(public),
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(public[1:33]),
+				Y:     new(big.Int).SetBytes(public[33:]),
+			},
+			D: new(big.Int).SetBytes(raw),
+		},
+		subject: cfg.Subject,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// PublicKey is the application server key browsers subscribe with.
+func (s *Sender) PublicKey() string {
+	return s.publicKey
+}
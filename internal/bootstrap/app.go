@@ -0,0 +1,114 @@
+package bootstrap
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+
+	"LostAndFound/internal/adapters/postgres"
+	cache "LostAndFound/internal/adapters/redis"
+	s3storage "LostAndFound/internal/adapters/s3"
+	"LostAndFound/internal/config/server_config"
+	sc "LostAndFound/internal/config/storage_config"
+	"LostAndFound/migrations"
+)
+
+// App is what every binary starts from: the server and storage
+// configuration, the connections and the dependencies built on them.
+type App struct {
+	Config        *server_config.Config
+	StorageConfig *sc.Config
+
+	DB    *sql.DB
+	Redis *redis.Client
+	Deps  *Deps
+}
+
+// Load reads the configuration the same way for the server, the worker and
+// the command-line tool and connects to the storages.
+func Load() (*App, error) {
+	serverCfg, err := server_config.MustLoadServerConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server config: %w", err)
+	}
+
+	storageCfg, err := sc.MustLoadStorageConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load storage config: %w", err)
+	}
+
+	db, err := postgres.NewStorage(storageCfg.Postgres)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	rd, err := cache.NewRedis(storageCfg.Redis)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	s3c, err := s3storage.NewS3Client(storageCfg.S3)
+	if err != nil {
+		db.Close()
+		rd.Close()
+		return nil, fmt.Errorf("failed to connect to s3: %w", err)
+	}
+
+	deps, err := Init(db, rd, s3c, storageCfg.S3, serverCfg.Notifications)
+	if err != nil {
+		db.Close()
+		rd.Close()
+		return nil, fmt.Errorf("failed to initialize dependencies: %w", err)
+	}
+
+	return &App{
+		Config:        serverCfg,
+		StorageConfig: storageCfg,
+		DB:            db,
+		Redis:         rd,
+		Deps:          deps,
+	}, nil
+}
+
+// PrepareSchema applies the pending migrations when auto_migrate is on and
+// refuses to go on with a schema older than the embedded migrations. A newer
+// schema is accepted, so that instances of the previous version keep running
+// during a rollout.
+func (a *App) PrepareSchema(ctx context.Context) error {
+	m, err := postgres.NewMigrator(a.DB, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	if a.Config.Database.AutoMigrate {
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			slog.Info("applied migration", "version", mig.Version, "name", mig.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("database schema version %d is dirty", status.Version)
+	}
+	if len(status.Pending) > 0 {
+		return fmt.Errorf("database schema version %d is behind %d, run lafctl migrate up or enable database.auto_migrate",
+			status.Version, status.Latest)
+	}
+	return nil
+}
+
+func (a *App) Close() error {
+	return errors.Join(a.DB.Close(), a.Redis.Close())
+}
@@ -2,13 +2,18 @@ package bootstrap
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/redis/go-redis/v9"
 
+	"LostAndFound/internal/adapters/email"
 	"LostAndFound/internal/adapters/postgres"
 	cache "LostAndFound/internal/adapters/redis"
 	s3storage "LostAndFound/internal/adapters/s3"
+	"LostAndFound/internal/adapters/telegram"
+	"LostAndFound/internal/adapters/webpush"
+	"LostAndFound/internal/config/server_config"
 	sc "LostAndFound/internal/config/storage_config"
 	"LostAndFound/internal/domain/repository"
 )
@@ -16,15 +21,61 @@ import (
 type Deps struct {
 	UserRepo  repository.UserRepo
 	CardRepo  repository.CardRepo
-	CacheRepo repository.CacheRepo
-	FileStore repository.FileStorage
+	PlaceRepo repository.PlaceRepo
+	Geocoder  repository.Geocoder
+
+	WatchAreaRepo    repository.WatchAreaRepo
+	NotificationRepo repository.NotificationRepo
+	SavedSearchRepo  repository.SavedSearchRepo
+	WebhookRepo      repository.WebhookRepo
+	OutboxRepo       repository.OutboxRepo
+	JobRepo          repository.JobRepo
+	CacheRepo        repository.CacheRepo
+	FileStore        repository.FileStorage
+	FileRefRepo      repository.FileRefRepo
+	CardEvents       repository.CardEventBus
+	EventStream      repository.EventStream
+	Locker           repository.Locker
+
+	NotificationSenders []repository.NotificationSender
+	PushPublicKey       string
 }
 
-func Init(pg *sql.DB, rd *redis.Client, s3c *s3.S3, cfg sc.S3Config) *Deps {
-	return &Deps{
+func Init(pg *sql.DB, rd *redis.Client, s3c *s3.S3, cfg sc.S3Config, notifyCfg server_config.NotificationsConfig) (*Deps, error) {
+	deps := &Deps{
 		UserRepo:  postgres.NewUserRepo(pg),
 		CardRepo:  postgres.NewCardRepo(pg),
-		CacheRepo: cache.NewCacheRepo(rd),
-		FileStore: s3storage.NewFileStorage(s3c, cfg),
+		PlaceRepo: postgres.NewPlaceRepo(pg),
+		Geocoder:  postgres.NewGazetteerRepo(pg),
+
+		WatchAreaRepo:    postgres.NewWatchAreaRepo(pg),
+		NotificationRepo: postgres.NewNotificationRepo(pg),
+		SavedSearchRepo:  postgres.NewSavedSearchRepo(pg),
+		WebhookRepo:      postgres.NewWebhookRepo(pg),
+		OutboxRepo:       postgres.NewOutboxRepo(pg),
+		JobRepo:          postgres.NewJobRepo(pg),
+		CacheRepo:        cache.NewCacheRepo(rd),
+		FileStore:        s3storage.NewFileStorage(s3c, cfg),
+		FileRefRepo:      postgres.NewFileRefRepo(pg),
+		CardEvents:       cache.NewCardEventBus(rd),
+		EventStream:      cache.NewEventStream(rd),
+		Locker:           cache.NewLocker(rd),
+	}
+
+	if notifyCfg.Email.Host != "" {
+		deps.NotificationSenders = append(deps.NotificationSenders, email.NewSender(notifyCfg.Email))
+	}
+	if notifyCfg.Telegram.BotToken != "" {
+		deps.NotificationSenders = append(deps.NotificationSenders, telegram.NewSender(telegram.NewClient(notifyCfg.Telegram)))
 	}
+	if notifyCfg.WebPush.PrivateKey != "" {
+		sender, err := webpush.NewSender(notifyCfg.WebPush)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure web push: %w", err)
+		}
+		deps.NotificationSenders = append(deps.NotificationSenders, sender)
+		deps.PushPublicKey = sender.PublicKey()
+	}
+
+	return deps, nil
 }
@@ -0,0 +1,180 @@
+// Package cron parses job schedules: the five-field crontab format
+// (minute, hour, day of month, month, day of week) with lists, ranges and
+// steps, the @hourly, @daily, @weekly and @monthly shortcuts, and
+// "@every <duration>" for fixed intervals.
+package cron
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the next activation strictly after the given time.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+var shortcuts = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+type field struct {
+	min, max int
+}
+
+var fields = [5]field{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week, 0 and 7 are Sunday
+}
+
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid interval %q", rest)
+		}
+		return every(d), nil
+	}
+	if expanded, ok := shortcuts[spec]; ok {
+		spec = expanded
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("schedule %q must have %d fields", spec, len(fields))
+	}
+
+	var s crontab
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", spec, err)
+		}
+		s.sets[i] = set
+	}
+	// Sunday may be written as 7.
+	if s.sets[4]&(1<<7) != 0 {
+		s.sets[4] |= 1
+	}
+	s.anyDay = parts[2] == "*"
+	s.anyWeekday = parts[4] == "*"
+	return &s, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %q", item)
+			}
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid value in %q", item)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value in %q", item)
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+		}
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("%q is out of range %d-%d", item, f.min, f.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+type crontab struct {
+	sets [5]uint64
+	// When both the day of month and the day of week are restricted, a day
+	// matching either of them is due, as in crontab(5).
+	anyDay, anyWeekday bool
+}
+
+func (s *crontab) has(i, v int) bool {
+	return s.sets[i]&(1<<v) != 0
+}
+
+func (s *crontab) dayMatches(t time.Time) bool {
+	day := s.has(2, t.Day())
+	weekday := s.has(4, int(t.Weekday()))
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+func (s *crontab) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// A schedule such as "0 0 30 2 *" never fires; give up after five years
+	// instead of looping forever.
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !s.has(3, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.has(1, t.Hour()) {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !s.has(0, t.Minute()) {
+			// Jump to the next allowed minute within the hour, if any.
+			rest := s.sets[0] >> (t.Minute() + 1) << (t.Minute() + 1)
+			if rest == 0 {
+				t = t.Truncate(time.Hour).Add(time.Hour)
+				continue
+			}
+			t = t.Add(time.Duration(bits.TrailingZeros64(rest)-t.Minute()) * time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// every fires at multiples of the interval since the zero time, so that all
+// instances agree on the activation times.
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	d := time.Duration(e)
+	return t.Truncate(d).Add(d)
+}
@@ -7,3 +7,22 @@ var ErrNoChanges = errors.New("no changes detected")
 var ErrPermissionDenied = errors.New("permission denied")
 var ErrFileNotFound = errors.New("file not found")
 var ErrUnauthorized = errors.New("you are not authorized")
+var ErrFileTooLarge = errors.New("file is too large")
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+var ErrInvalidImageOrder = errors.New("image order must list every card image exactly once")
+var ErrInvalidPlace = errors.New("invalid place")
+var ErrAddressNotFound = errors.New("address not found")
+var ErrInvalidGazetteer = errors.New("invalid gazetteer data")
+var ErrInvalidLocation = errors.New("coordinates are out of range")
+var ErrInvalidRadius = errors.New("invalid search radius")
+var ErrInvalidWatchArea = errors.New("invalid watch area")
+var ErrInvalidSavedSearch = errors.New("invalid saved search")
+var ErrInvalidPreferences = errors.New("invalid notification preferences")
+var ErrInvalidPushSubscription = errors.New("invalid push subscription")
+var ErrRecipientUnreachable = errors.New("recipient cannot be reached")
+var ErrInvalidLinkCode = errors.New("link code is invalid or expired")
+var ErrInvalidWebhook = errors.New("invalid webhook")
+var ErrUserBanned = errors.New("user is banned")
+var ErrVersionMismatch = errors.New("resource has been modified")
+var ErrInvalidPatch = errors.New("invalid merge patch")
+var ErrPublicDocumentImage = errors.New("document photos must be uploaded as private files")
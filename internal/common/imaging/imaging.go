@@ -0,0 +1,195 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	_ "image/gif"
+	_ "image/png"
+)
+
+const jpegQuality = 85
+
+// ErrTooLarge is returned by DecodeLimited for an image with more pixels than
+// allowed.
+var ErrTooLarge = errors.New("image is too large")
+
+func Decode(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// DecodeLimited decodes an image whose header declares at most maxPixels
+// pixels. The header is checked before anything is allocated, so a small
+// file that expands into a huge bitmap is rejected cheaply.
+func DecodeLimited(r io.Reader, maxPixels int64) (image.Image, error) {
+	var header bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image header: %w", err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+	return Decode(io.MultiReader(&header, r))
+}
+
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+}
+
+// Resize scales img to exactly width x height. Every destination pixel is the
+// average of the source pixels it covers, which keeps downscaling smooth.
+func Resize(img image.Image, width, height int) *image.RGBA {
+	src := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if src.Empty() || width <= 0 || height <= 0 {
+		return dst
+	}
+
+	for y := 0; y < height; y++ {
+		y0 := src.Min.Y + y*src.Dy()/height
+		y1 := max(src.Min.Y+(y+1)*src.Dy()/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := src.Min.X + x*src.Dx()/width
+			x1 := max(src.Min.X+(x+1)*src.Dx()/width, x0+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
+
+// Fit scales img down so that its longest side is at most maxSide pixels,
+// preserving the aspect ratio. Smaller images are returned unchanged.
+func Fit(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= maxSide && b.Dy() <= maxSide {
+		return img
+	}
+	if b.Dx() >= b.Dy() {
+		return Resize(img, maxSide, max(b.Dy()*maxSide/b.Dx(), 1))
+	}
+	return Resize(img, max(b.Dx()*maxSide/b.Dy(), 1), maxSide)
+}
+
+// Blur applies three passes of a box blur, which approximates a gaussian blur.
+func Blur(img image.Image, radius int) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			out.Set(x, y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	if radius <= 0 {
+		return out
+	}
+
+	tmp := image.NewRGBA(out.Rect)
+	for i := 0; i < 3; i++ {
+		boxBlur(out, tmp, radius, true)
+		boxBlur(tmp, out, radius, false)
+	}
+	return out
+}
+
+func boxBlur(src, dst *image.RGBA, radius int, horizontal bool) {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	lines, length := h, w
+	if !horizontal {
+		lines, length = w, h
+	}
+
+	offset := func(line, i int) int {
+		if horizontal {
+			return src.PixOffset(i, line)
+		}
+		return src.PixOffset(line, i)
+	}
+
+	for line := 0; line < lines; line++ {
+		var sum [4]int
+		for i := -radius; i <= radius; i++ {
+			o := offset(line, min(max(i, 0), length-1))
+			for c := 0; c < 4; c++ {
+				sum[c] += int(src.Pix[o+c])
+			}
+		}
+
+		window := 2*radius + 1
+		for i := 0; i < length; i++ {
+			o := offset(line, i)
+			for c := 0; c < 4; c++ {
+				dst.Pix[o+c] = uint8(sum[c] / window)
+			}
+
+			in := offset(line, min(i+radius+1, length-1))
+			out := offset(line, max(i-radius, 0))
+			for c := 0; c < 4; c++ {
+				sum[c] += int(src.Pix[in+c]) - int(src.Pix[out+c])
+			}
+		}
+	}
+}
+
+// DHash computes a 64-bit difference hash: the image is shrunk to 9x8
+// grayscale pixels and every bit records whether a pixel is brighter than
+// its right neighbour. Visually similar images have hashes with a small
+// Hamming distance.
+func DHash(img image.Image) uint64 {
+	small := Resize(img, 9, 8)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if luminance(small.RGBAAt(x, y)) > luminance(small.RGBAAt(x+1, y)) {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+func luminance(c color.RGBA) int {
+	return (299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000
+}
+
+// CropSquare cuts the largest centered square out of img.
+func CropSquare(img image.Image) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			square.Set(x, y, img.At(x0+x, y0+y))
+		}
+	}
+	return square
+}
@@ -14,9 +14,84 @@ const (
 )
 
 type Config struct {
-	Address     string        `yaml:"address" env-default:"localhost:8080"`
-	TimeOut     time.Duration `yaml:"timeout" env-default:"4s"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	Address     string            `yaml:"address" env-default:"localhost:8080"`
+	TimeOut     time.Duration     `yaml:"timeout" env-default:"4s"`
+	IdleTimeout time.Duration     `yaml:"idle_timeout" env-default:"60s"`
+	Search      SearchConfig      `yaml:"search"`
+	Jobs        JobsConfig        `yaml:"jobs"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Trash       TrashConfig       `yaml:"trash"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+
+	Notifications NotificationsConfig `yaml:"notifications"`
+}
+
+type SearchConfig struct {
+	// MaxRadiusKm caps the radius of nearby card searches.
+	MaxRadiusKm float64 `yaml:"max_radius_km" env-default:"50"`
+}
+
+// TrashConfig sets how long deleted cards stay in the trash, where their
+// owners can restore them, before they and their files are removed for good.
+type TrashConfig struct {
+	Retention time.Duration `yaml:"retention" env-default:"720h"`
+}
+
+// IdempotencyConfig sets how long the response to a request with an
+// Idempotency-Key header is kept to be replayed to its retries.
+type IdempotencyConfig struct {
+	TTL time.Duration `yaml:"ttl" env-default:"24h"`
+}
+
+// DatabaseConfig controls the schema migrations on start. Without
+// AutoMigrate the server and the worker refuse to start on a schema older
+// than the migrations they were built with.
+type DatabaseConfig struct {
+	AutoMigrate bool `yaml:"auto_migrate" env:"AUTO_MIGRATE" env-default:"false"`
+}
+
+// JobsConfig configures the background processing. Workers is the number of
+// jobs an instance runs at the same time. Embedded runs the job runner, the
+// dispatchers and the outbox relay inside the server; turn it off when they
+// run in cmd/worker instead.
+type JobsConfig struct {
+	Workers  int  `yaml:"workers" env-default:"2"`
+	Embedded bool `yaml:"embedded" env-default:"true"`
+}
+
+// NotificationsConfig configures the external delivery channels. A channel
+// is enabled only when its section is filled in.
+type NotificationsConfig struct {
+	Email    EmailConfig    `yaml:"email"`
+	Telegram TelegramConfig `yaml:"telegram"`
+	WebPush  WebPushConfig  `yaml:"webpush"`
+}
+
+type EmailConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port" env-default:"587"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// TelegramConfig enables both the notification channel and the bot. Only
+// one instance may poll for updates, so Polling can be turned off on the
+// others.
+type TelegramConfig struct {
+	BotToken    string `yaml:"bot_token"`
+	BotUsername string `yaml:"bot_username"`
+	APIURL      string `yaml:"api_url" env-default:"https://api.telegram.org"`
+	Polling     bool   `yaml:"polling" env-default:"true"`
+}
+
+// WebPushConfig holds the VAPID key pair as unpadded base64url, the public
+// key in uncompressed form and the private key as a raw scalar. Subject is
+// a mailto: or https: contact of the sender.
+type WebPushConfig struct {
+	PublicKey  string `yaml:"public_key"`
+	PrivateKey string `yaml:"private_key"`
+	Subject    string `yaml:"subject"`
 }
 
 func MustLoadServerConfig() (*Config, error) {
@@ -39,6 +39,10 @@ type S3Config struct {
 	SecretKey string `yaml:"secret_key"`
 	Bucket    string `yaml:"bucket"`
 	UseSSL    bool   `yaml:"use_ssl"`
+	// ManagePolicy lets the server add the public read statement to the
+	// bucket policy on start. It needs policy admin rights, so it is off by
+	// default and the statement is set with lafctl files policy instead.
+	ManagePolicy bool `yaml:"manage_policy"`
 }
 
 func MustLoadStorageConfig() (*Config, error) {
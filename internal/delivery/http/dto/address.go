@@ -0,0 +1,13 @@
+package dto
+
+type AddressResponse struct {
+	City        string  `json:"city"`
+	Street      string  `json:"street"`
+	HouseNumber string  `json:"house_number,omitempty"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+}
+
+type GazetteerImportResponse struct {
+	Imported int `json:"imported"`
+}
@@ -1,13 +1,13 @@
 package dto
 
 type CreateCardRequest struct {
-	Title       string   `json:"title"       validate:"required"`
-	Description string   `json:"description"`
-	Latitude    float64  `json:"latitude"    validate:"required"`
-	Longitude   float64  `json:"longitude"   validate:"required"`
-	PreviewURL  string   `json:"preview_url"`
-	Status      string   `json:"status"      validate:"required,oneof=lost found"`
-	Images      []string `json:"images"`
-	City        string   `json:"city"        validate:"required"`
-	Street      string   `json:"street"`
+	Title       string         `json:"title"       validate:"required"`
+	Description string         `json:"description"`
+	Latitude    *float64       `json:"latitude"    validate:"required_without=City,required_with=Longitude,omitnil,gte=-90,lte=90"`
+	Longitude   *float64       `json:"longitude"   validate:"required_without=City,required_with=Latitude,omitnil,gte=-180,lte=180"`
+	Status      string         `json:"status"      validate:"required,oneof=lost found"`
+	Category    string         `json:"category"    validate:"omitempty,oneof=documents electronics clothing accessories keys bags other"`
+	Images      []ImageRequest `json:"images" validate:"omitempty,dive"`
+	City        string         `json:"city"        validate:"required_without=Latitude"`
+	Street      string         `json:"street"`
 }
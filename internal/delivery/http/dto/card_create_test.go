@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// The coordinate ranges are covered by entity.Location and the mapper; this
+// checks that a card needs either a point or a city.
+func TestCreateCardRequestLocation(t *testing.T) {
+	coord := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		lat     *float64
+		lon     *float64
+		city    string
+		wantErr bool
+	}{
+		{name: "coordinates without city", lat: coord(55.75), lon: coord(37.61)},
+		{name: "city without coordinates", city: "Москва"},
+		{name: "coordinates and city", lat: coord(55.75), lon: coord(37.61), city: "Москва"},
+		{name: "latitude without longitude", lat: coord(55.75), city: "Москва", wantErr: true},
+		{name: "longitude without latitude", lon: coord(37.61), city: "Москва", wantErr: true},
+		{name: "neither coordinates nor city", wantErr: true},
+	}
+
+	validate := validator.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := CreateCardRequest{
+				Title:     "Ключи",
+				Status:    "lost",
+				Latitude:  tt.lat,
+				Longitude: tt.lon,
+				City:      tt.city,
+			}
+			if err := validate.Struct(req); (err != nil) != tt.wantErr {
+				t.Errorf("Struct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// CardEventResponse is the data of a card feed event. The card carries no
+// images and owner contacts; clients load the full card by its ID.
+type CardEventResponse struct {
+	Type       string                `json:"type" enums:"card.created,card.updated,card.deleted,card.restored"`
+	OccurredAt time.Time             `json:"occurred_at"`
+	Card       CardEventCardResponse `json:"card"`
+}
+
+type CardEventCardResponse struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	Category  string    `json:"category"`
+	Latitude  *float64  `json:"latitude"`
+	Longitude *float64  `json:"longitude"`
+	City      string    `json:"city"`
+	Street    string    `json:"street"`
+	PlaceID   string    `json:"place_id,omitempty"`
+	PlaceName string    `json:"place_name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "encoding/json"
+
+// ImageRequest describes a card image. For compatibility with older clients
+// an image may also be sent as a plain URL string.
+type ImageRequest struct {
+	URL     string `json:"url"      validate:"required"`
+	Caption string `json:"caption"  validate:"max=500"`
+	IsCover bool   `json:"is_cover"`
+}
+
+func (i *ImageRequest) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		*i = ImageRequest{URL: url}
+		return nil
+	}
+
+	type plain ImageRequest
+	return json.Unmarshal(data, (*plain)(i))
+}
+
+type ImageResponse struct {
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	Position int    `json:"position"`
+	Caption  string `json:"caption,omitempty"`
+	Width    int    `json:"width,omitempty"`
+	Height   int    `json:"height,omitempty"`
+	IsCover  bool   `json:"is_cover"`
+}
+
+type ReorderImagesRequest struct {
+	ImageIDs []string `json:"image_ids" validate:"required,min=1,dive,required"`
+}
+
+type UpdateImageRequest struct {
+	Caption *string `json:"caption,omitempty" validate:"omitempty,max=500"`
+	IsCover bool    `json:"is_cover,omitempty"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+type MapCardResponse struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Status     string  `json:"status"`
+	Category   string  `json:"category"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	PreviewURL string  `json:"preview_url"`
+}
+
+type MapClusterResponse struct {
+	Latitude  float64        `json:"latitude"`
+	Longitude float64        `json:"longitude"`
+	Count     int            `json:"count"`
+	Statuses  map[string]int `json:"statuses"`
+	CardID    string         `json:"card_id,omitempty"`
+}
+
+// MapResponse holds either cards or clusters depending on Mode.
+type MapResponse struct {
+	Mode     string               `json:"mode" enums:"cards,clusters"`
+	Cards    []MapCardResponse    `json:"cards,omitempty"`
+	Clusters []MapClusterResponse `json:"clusters,omitempty"`
+}
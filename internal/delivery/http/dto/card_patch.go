@@ -0,0 +1,16 @@
+package dto
+
+// CardDocument is the editable part of a card that PATCH /cards/{id} applies
+// a merge patch to. The patched document has to be a valid card, so removing
+// the title or the status, or both the city and the coordinates, is refused.
+type CardDocument struct {
+	Title       string         `json:"title" validate:"required,min=3"`
+	Description string         `json:"description,omitempty" validate:"omitempty,min=10"`
+	City        string         `json:"city,omitempty" validate:"required_without=Latitude"`
+	Street      string         `json:"street,omitempty"`
+	Status      string         `json:"status" validate:"required,oneof=lost found"`
+	Category    string         `json:"category,omitempty" validate:"omitempty,oneof=documents electronics clothing accessories keys bags other"`
+	Latitude    *float64       `json:"latitude,omitempty" validate:"required_without=City,required_with=Longitude,omitnil,gte=-90,lte=90"`
+	Longitude   *float64       `json:"longitude,omitempty" validate:"required_with=Latitude,omitnil,gte=-180,lte=180"`
+	Images      []ImageRequest `json:"images,omitempty" validate:"omitempty,dive"`
+}
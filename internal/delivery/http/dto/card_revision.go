@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// CardRevisionResponse is one update of a card. reverted_to is set when the
+// update brought back an earlier revision.
+type CardRevisionResponse struct {
+	Number     int                       `json:"number"`
+	AuthorID   string                    `json:"author_id"`
+	RevertedTo *int                      `json:"reverted_to,omitempty"`
+	Changes    []CardFieldChangeResponse `json:"changes"`
+	CreatedAt  time.Time                 `json:"created_at"`
+}
+
+// CardFieldChangeResponse holds the old and new value of a field: a string
+// for text fields, a point for location and a list of images for images.
+type CardFieldChangeResponse struct {
+	Field string `json:"field" enums:"title,description,city,street,status,category,location,images"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+type PointResponse struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+type SimilarCardResponse struct {
+	Card     CardResponse `json:"card"`
+	ImageURL string       `json:"image_url"`
+	Distance int          `json:"distance"`
+}
+
+type CreateCardResponse struct {
+	ID         string                `json:"id"`
+	Message    string                `json:"message"`
+	Duplicates []SimilarCardResponse `json:"duplicates,omitempty"`
+}
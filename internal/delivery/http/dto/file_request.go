@@ -3,4 +3,5 @@ package dto
 type FileRequest struct {
 	FileName    string `json:"file_name" validate:"required,min=1"`
 	ContentType string `json:"content_type" validate:"required"`
+	Visibility  string `json:"visibility" validate:"omitempty,oneof=public private"`
 }
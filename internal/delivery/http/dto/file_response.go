@@ -2,6 +2,8 @@ package dto
 
 type FileUploadResponse struct {
 	FileName     string `json:"file_name"`
+	Key          string `json:"key"`
+	Visibility   string `json:"visibility"`
 	PresignedURL string `json:"presigned_url"`
-	PublicURL    string `json:"public_url"`
+	PublicURL    string `json:"public_url,omitempty"`
 }
@@ -0,0 +1,63 @@
+package dto
+
+import "time"
+
+type NotificationResponse struct {
+	ID         string             `json:"id"`
+	Kind       string             `json:"kind"`
+	Title      string             `json:"title"`
+	Body       string             `json:"body"`
+	CardID     string             `json:"card_id,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ReadAt     *time.Time         `json:"read_at"`
+	Deliveries []DeliveryResponse `json:"deliveries"`
+}
+
+type DeliveryResponse struct {
+	Channel  string     `json:"channel"`
+	Status   string     `json:"status"`
+	Attempts int        `json:"attempts"`
+	SentAt   *time.Time `json:"sent_at,omitempty"`
+}
+
+type UnreadCountResponse struct {
+	Count int `json:"count"`
+}
+
+// QuietHours holds back external deliveries between Start and End, given as
+// HH:MM in TimeZone; a period such as 23:00–08:00 spans midnight.
+type QuietHours struct {
+	Start    string `json:"start" validate:"required,datetime=15:04"`
+	End      string `json:"end" validate:"required,datetime=15:04"`
+	TimeZone string `json:"time_zone" validate:"required,timezone"`
+}
+
+type NotificationPreferencesRequest struct {
+	Channels   []string    `json:"channels" validate:"dive,oneof=email telegram webpush"`
+	QuietHours *QuietHours `json:"quiet_hours" validate:"omitnil"`
+}
+
+type NotificationPreferencesResponse struct {
+	Channels          []string    `json:"channels"`
+	QuietHours        *QuietHours `json:"quiet_hours"`
+	AvailableChannels []string    `json:"available_channels"`
+}
+
+// PushSubscriptionRequest matches PushSubscription.toJSON() of the browser.
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" validate:"required,url"`
+	Keys     struct {
+		P256dh string `json:"p256dh" validate:"required"`
+		Auth   string `json:"auth" validate:"required"`
+	} `json:"keys"`
+}
+
+type PushSubscriptionResponse struct {
+	ID        string    `json:"id"`
+	Endpoint  string    `json:"endpoint"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PushKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PlaceRequest struct {
+	ParentID string          `json:"parent_id" validate:"omitempty,uuid"`
+	Kind     string          `json:"kind" validate:"required,oneof=campus building zone"`
+	Name     string          `json:"name" validate:"required,max=200"`
+	Geometry json.RawMessage `json:"geometry" validate:"required" swaggertype:"object"`
+}
+
+type PlaceResponse struct {
+	ID        string          `json:"id"`
+	ParentID  string          `json:"parent_id,omitempty"`
+	Kind      string          `json:"kind"`
+	Name      string          `json:"name"`
+	Geometry  json.RawMessage `json:"geometry,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+	Children  []PlaceResponse `json:"children,omitempty"`
+}
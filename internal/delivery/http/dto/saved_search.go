@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type SavedSearchRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// Query uses the same parameters as GET /cards/all, e.g.
+	// "status=found&category=keys&q=ключи&place_id=...".
+	Query     string `json:"query"`
+	Frequency string `json:"frequency" validate:"required,oneof=instant daily"`
+}
+
+type SavedSearchResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Frequency string    `json:"frequency"`
+	LastRunAt time.Time `json:"last_run_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+import "time"
+
+type StreamTicketResponse struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
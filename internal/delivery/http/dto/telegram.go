@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type TelegramLinkResponse struct {
+	Code      string    `json:"code"`
+	URL       string    `json:"url,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type UpdateAvatarRequest struct {
+	Key string `json:"key" validate:"required"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// UserDocument is the editable part of a profile that PATCH /users/me applies
+// a merge patch to. It is checked like a registration; the password is never
+// part of the document and only a patch can set a new one.
+type UserDocument struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password,omitempty" validate:"omitempty,min=6"`
+	Name     string `json:"name" validate:"required,min=3"`
+	Surname  string `json:"surname" validate:"required,min=3"`
+	Phone    string `json:"phone" validate:"required,min=6"`
+	Telegram string `json:"telegram" validate:"required,min=4"`
+}
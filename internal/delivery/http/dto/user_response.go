@@ -7,4 +7,7 @@ type UserResponse struct {
 	Surname  string `json:"surname"`
 	Phone    string `json:"phone"`
 	Telegram string `json:"telegram"`
+
+	AvatarURL      string `json:"avatar_url,omitempty"`
+	AvatarThumbURL string `json:"avatar_thumb_url,omitempty"`
 }
@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+type WatchAreaRequest struct {
+	Name      string   `json:"name" validate:"required,max=100"`
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,excluded_with=PlaceID,omitnil,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitnil,gte=-180,lte=180"`
+	RadiusM   float64  `json:"radius_m" validate:"required_with=Latitude,omitempty,gt=0"`
+	PlaceID   string   `json:"place_id" validate:"required_without=Latitude,omitempty,uuid"`
+	Category  string   `json:"category" validate:"omitempty,oneof=documents electronics clothing accessories keys bags other"`
+	Status    string   `json:"status" validate:"omitempty,oneof=lost found"`
+}
+
+type WatchAreaResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Latitude  *float64  `json:"latitude,omitempty"`
+	Longitude *float64  `json:"longitude,omitempty"`
+	RadiusM   float64   `json:"radius_m,omitempty"`
+	PlaceID   string    `json:"place_id,omitempty"`
+	PlaceName string    `json:"place_name,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
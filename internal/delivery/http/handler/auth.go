@@ -1,10 +1,12 @@
 package handler
 
 import (
+	e "LostAndFound/internal/common/errors"
 	v "LostAndFound/internal/common/validation"
 	"LostAndFound/internal/delivery/http/dto"
 	"LostAndFound/internal/delivery/http/mapper"
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
@@ -50,6 +52,7 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 // @Success      200    {object}  string           "JWT токены"
 // @Failure      400    {string}  string
 // @Failure      401    {string}  string  "Invalid credentials"
+// @Failure      403    {string}  string  "user is banned"
 // @Router       /auth/login [post]
 
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
@@ -64,6 +67,10 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	token, err := h.services.Auth.Login(r.Context(), req.Email, req.Password)
+	if errors.Is(err, e.ErrUserBanned) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		http.Error(w, "invalid credentials", http.StatusUnauthorized)
 		return
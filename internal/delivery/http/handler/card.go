@@ -2,8 +2,10 @@ package handler
 
 import (
 	e "LostAndFound/internal/common/errors"
+	v "LostAndFound/internal/common/validation"
 	"LostAndFound/internal/delivery/http/dto"
 	"LostAndFound/internal/delivery/http/mapper"
+	"LostAndFound/internal/domain/entity"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,14 +16,23 @@ import (
 )
 
 // @Summary Создание объявления
+// @Description Город и улица заполняются по координатам из локального справочника адресов.
+// @Description Если координаты не переданы, точка определяется по городу и улице.
+// @Description Фото документов должны быть загружены как приватные файлы (visibility=private).
+// @Description Фото, которые не удалось проверить на дубликаты сразу, проверяются в фоне: о совпадениях
+// @Description владелец получит уведомление.
 // @Tags Cards
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param input body dto.CreateCardRequest true "Данные объявления"
-// @Success 201 {string} string "Создано"
-// @Failure 400 {string} string "Некорректный запрос"
+// @Param Idempotency-Key header string false "Ключ для безопасного повтора запроса"
+// @Success 201 {object} dto.CreateCardResponse "Создано; duplicates содержит объявления с той же фотографией"
+// @Failure 400 {string} string "Некорректный запрос или фото документа в публичном файле"
 // @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Нет доступа к приватному файлу"
+// @Failure 409 {string} string "Запрос с этим Idempotency-Key еще выполняется"
+// @Failure 422 {string} string "Адрес не найден или Idempotency-Key использован с другим телом"
 // @Failure 500 {string} string "Ошибка сервера"
 // @Router /api/cards [post]
 func (h *Handler) CreateCard(w http.ResponseWriter, r *http.Request) {
@@ -43,19 +54,44 @@ func (h *Handler) CreateCard(w http.ResponseWriter, r *http.Request) {
 
 	card := mapper.ToCardEntity(req, userID)
 
-	if err := h.services.Cards.CreateCard(r.Context(), card); err != nil {
+	duplicates, err := h.services.Cards.CreateCard(r.Context(), card)
+	if err != nil {
+		if errors.Is(err, e.ErrPermissionDenied) {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, e.ErrInvalidLocation) || errors.Is(err, e.ErrPublicDocumentImage) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, e.ErrAddressNotFound) {
+			http.Error(w, "address not found, specify coordinates", http.StatusUnprocessableEntity)
+			return
+		}
 		http.Error(w, "failed to create card", http.StatusInternalServerError)
 		return
 	}
+
+	resp := dto.CreateCardResponse{ID: card.ID, Message: "card created successfully"}
+	for _, d := range duplicates {
+		resp.Duplicates = append(resp.Duplicates, mapper.ToSimilarCardResponse(d))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"message": "card created successfully"})
+	json.NewEncoder(w).Encode(resp)
 }
 
 // @Summary Получить объявление по ID
+// @Description ETag — версия объявления. Ссылки на приватные файлы действуют 5 минут, поэтому после
+// @Description ответа 304 их стоит загрузить заново без If-None-Match.
 // @Tags Cards
 // @Produce json
 // @Param id path string true "ID объявления"
+// @Param If-None-Match header string false "ETag известной версии"
 // @Success 200 {object} dto.CardResponse
+// @Header 200 {string} ETag "Версия объявления"
+// @Success 304 {string} string "Объявление не изменилось"
 // @Failure 404 {string} string "Объявление не найдено"
 // @Failure 500 {string} string "Ошибка сервера"
 // @Router /api/cards/{id} [get]
@@ -77,14 +113,10 @@ func (h *Handler) GetCardByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	owner := dto.OwnerDTO{
-		ID:       card.Owner.ID,
-		Name:     card.Owner.Name,
-		Surname:  card.Owner.Surname,
-		Phone:    card.Owner.Phone,
-		Telegram: card.Owner.Telegram,
+	if writeETag(w, r, card.Version) {
+		return
 	}
-	resp := mapper.ToCardResponse(card, owner)
+	resp := mapper.ToCardResponse(card, mapper.ToOwnerDTO(card.Owner))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
@@ -94,27 +126,46 @@ func (h *Handler) GetCardByID(w http.ResponseWriter, r *http.Request) {
 // @Tags Cards
 // @Produce json
 // @Param status query string false "Статус объявления (lost/found)"
+// @Param category query string false "Категория"
+// @Param place_id query string false "Место (включая вложенные)"
+// @Param q query string false "Текст в заголовке или описании"
+// @Param created_from query string false "Создано не раньше (YYYY-MM-DD или RFC 3339)"
+// @Param created_to query string false "Создано раньше (YYYY-MM-DD или RFC 3339)"
+// @Param lat query number false "Широта центра области"
+// @Param lon query number false "Долгота центра области"
+// @Param radius query number false "Радиус области в километрах"
+// @Param radius_m query number false "Радиус области в метрах (вместо radius)"
+// @Param deleted query bool false "Удаленные объявления вместо действующих (только для администраторов)"
 // @Success 200 {array} dto.CardResponse
+// @Failure 400 {string} string "Некорректный фильтр"
+// @Failure 403 {string} string "Нет доступа"
 // @Failure 500 {string} string "Ошибка сервера"
 // @Router /api/cards/all [get]
 func (h *Handler) GetAllCards(w http.ResponseWriter, r *http.Request) {
+	filter, err := mapper.ParseCardFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Deleted = r.URL.Query().Get("deleted") == "true"
 
-	status := r.URL.Query().Get("status")
-
-	cards, err := h.services.GetAllCards(r.Context(), status)
+	cards, err := h.services.GetAllCards(r.Context(), filter)
 	if err != nil {
+		if errors.Is(err, e.ErrInvalidRadius) || errors.Is(err, e.ErrInvalidLocation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, e.ErrPermissionDenied) {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
 		http.Error(w, fmt.Sprintf("failed to get cards: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	var resp []dto.CardResponse
 	for _, l := range cards {
-		owner := dto.OwnerDTO{
-			ID: l.Owner.ID, Name: l.Owner.Name,
-			Surname: l.Owner.Surname, Phone: l.Owner.Phone,
-			Telegram: l.Owner.Telegram,
-		}
-		resp = append(resp, mapper.ToCardResponse(l, owner))
+		resp = append(resp, mapper.ToCardResponse(l, mapper.ToOwnerDTO(l.Owner)))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -126,67 +177,270 @@ func (h *Handler) GetAllCards(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param lat query number true "Широта"
 // @Param lon query number true "Долгота"
-// @Param radius query number true "Радиус поиска (км)"
+// @Param radius query number false "Радиус поиска в километрах"
+// @Param radius_m query number false "Радиус поиска в метрах (вместо radius)"
 // @Param status query string false "Статус"
+// @Param category query string false "Категория"
+// @Param place_id query string false "Место (включая вложенные)"
+// @Param q query string false "Текст в заголовке или описании"
+// @Param created_from query string false "Создано не раньше (YYYY-MM-DD или RFC 3339)"
+// @Param created_to query string false "Создано раньше (YYYY-MM-DD или RFC 3339)"
 // @Success 200 {array} dto.CardResponse
-// @Failure 400 {string} string "Некорректные координаты"
+// @Failure 400 {string} string "Некорректные координаты или радиус"
 // @Failure 500 {string} string "Ошибка сервера"
 // @Router /api/cards/near [get]
 func (h *Handler) GetCardsNear(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 
-	latStr := q.Get("lat")
-	lonStr := q.Get("lon")
-	radiusStr := q.Get("radius")
-	status := q.Get("status")
+	if !q.Has("lat") || !q.Has("lon") {
+		http.Error(w, "lat and lon are required", http.StatusBadRequest)
+		return
+	}
 
-	lat, err := strconv.ParseFloat(latStr, 64)
+	filter, err := mapper.ParseCardFilter(q)
 	if err != nil {
-		http.Error(w, "invalid latitude", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	lon, err := strconv.ParseFloat(lonStr, 64)
+
+	cards, err := h.services.GetCardsNear(r.Context(), filter)
 	if err != nil {
-		http.Error(w, "invalid longitude", http.StatusBadRequest)
+		if errors.Is(err, e.ErrInvalidRadius) || errors.Is(err, e.ErrInvalidLocation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("failed to get nearby cards: %v", err), http.StatusInternalServerError)
 		return
 	}
-	radius, err := strconv.ParseFloat(radiusStr, 64)
+
+	var resp []dto.CardResponse
+	for _, l := range cards {
+		resp = append(resp, mapper.ToCardResponse(l, mapper.ToOwnerDTO(l.Owner)))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// @Summary Получить объявления для области карты
+// @Description При крупном масштабе возвращает отдельные объявления, при мелком — кластеры
+// @Description с количеством объявлений, центроидом и разбивкой по статусам. Отдается не больше
+// @Description 500 объявлений или 1000 самых крупных кластеров.
+// @Tags Cards
+// @Produce json
+// @Param bbox query string true "Область карты: minLon,minLat,maxLon,maxLat"
+// @Param zoom query int true "Масштаб карты (0-22)"
+// @Param status query string false "Статус (lost/found)"
+// @Param category query string false "Категория"
+// @Param place_id query string false "Место (включая вложенные)"
+// @Param q query string false "Текст в заголовке или описании"
+// @Param created_from query string false "Создано не раньше (YYYY-MM-DD или RFC 3339)"
+// @Param created_to query string false "Создано раньше (YYYY-MM-DD или RFC 3339)"
+// @Success 200 {object} dto.MapResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/map [get]
+func (h *Handler) GetCardsMap(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	bbox, err := mapper.ParseBoundingBox(q.Get("bbox"))
 	if err != nil {
-		http.Error(w, "invalid radius", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	cards, err := h.services.GetCardsNear(r.Context(), lat, lon, radius, status)
+	zoom, err := strconv.Atoi(q.Get("zoom"))
+	if err != nil || zoom < 0 || zoom > 22 {
+		http.Error(w, "invalid zoom", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := mapper.ParseCardFilter(r.URL.Query())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to get nearby cards: %v", err), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	var resp []dto.CardResponse
-	for _, l := range cards {
-		owner := dto.OwnerDTO{
-			ID: l.Owner.ID, Name: l.Owner.Name,
-			Surname: l.Owner.Surname, Phone: l.Owner.Phone,
-			Telegram: l.Owner.Telegram,
+	view, err := h.services.Cards.GetMapView(r.Context(), bbox, zoom, filter)
+	if err != nil {
+		if errors.Is(err, e.ErrInvalidRadius) || errors.Is(err, e.ErrInvalidLocation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-		resp = append(resp, mapper.ToCardResponse(l, owner))
+		http.Error(w, fmt.Sprintf("failed to get map: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapper.ToMapResponse(view))
+}
+
+// @Summary Получить объявления с похожими фотографиями
+// @Tags Cards
+// @Produce json
+// @Param id path string true "ID объявления"
+// @Param max_distance query int false "Максимальное расстояние Хэмминга между хешами (1-64)"
+// @Success 200 {array} dto.SimilarCardResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 404 {string} string "Объявление не найдено"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/{id}/similar-images [get]
+func (h *Handler) GetSimilarImages(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	maxDistance := 0
+	if v := r.URL.Query().Get("max_distance"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 1 || d > 64 {
+			http.Error(w, "invalid max_distance", http.StatusBadRequest)
+			return
+		}
+		maxDistance = d
+	}
+
+	similar, err := h.services.Cards.GetSimilarImages(r.Context(), id, maxDistance)
+	if err != nil {
+		if errors.Is(err, e.ErrNotFound) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, fmt.Sprintf("failed to get similar images: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]dto.SimilarCardResponse, 0, len(similar))
+	for _, s := range similar {
+		resp = append(resp, mapper.ToSimilarCardResponse(s))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
+// @Summary Изменить порядок фотографий объявления
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID объявления"
+// @Param input body dto.ReorderImagesRequest true "ID всех фотографий в новом порядке"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Нет доступа"
+// @Failure 404 {string} string "Объявление не найдено"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/{id}/images/order [put]
+func (h *Handler) ReorderImages(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReorderImagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.services.Cards.ReorderImages(r.Context(), chi.URLParam(r, "id"), req.ImageIDs); err != nil {
+		writeCardImageError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "images reordered successfully"})
+}
+
+// @Summary Изменить подпись фотографии или сделать ее обложкой
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID объявления"
+// @Param imageID path string true "ID фотографии"
+// @Param input body dto.UpdateImageRequest true "Подпись и признак обложки"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Нет доступа"
+// @Failure 404 {string} string "Фотография не найдена"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/{id}/images/{imageID} [patch]
+func (h *Handler) UpdateImage(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	err := h.services.Cards.UpdateImage(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "imageID"), req.Caption, req.IsCover)
+	if err != nil {
+		writeCardImageError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "image updated successfully"})
+}
+
+// @Summary Удалить фотографию из объявления
+// @Tags Cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID объявления"
+// @Param imageID path string true "ID фотографии"
+// @Success 200 {string} string "OK"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Нет доступа"
+// @Failure 404 {string} string "Фотография не найдена"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/{id}/images/{imageID} [delete]
+func (h *Handler) RemoveImage(w http.ResponseWriter, r *http.Request) {
+	if err := h.services.Cards.RemoveImage(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "imageID")); err != nil {
+		writeCardImageError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "image removed successfully"})
+}
+
+func writeCardImageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, e.ErrUnauthorized):
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	case errors.Is(err, e.ErrPermissionDenied):
+		http.Error(w, "permission denied", http.StatusForbidden)
+	case errors.Is(err, e.ErrNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, e.ErrNoChanges), errors.Is(err, e.ErrInvalidImageOrder):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "failed to update images: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // @Summary Обновить объявление
+// @Description Пустые поля оставляют значение без изменений. Чтобы очистить поле, используйте PATCH.
 // @Tags Cards
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "ID объявления"
 // @Param input body dto.UpdateCardRequest true "Объявление"
+// @Param If-Match header string false "ETag версии, которую видел клиент"
 // @Success 200 {string} string "OK"
 // @Failure 400 {string} string "Некорректный запрос"
 // @Failure 401 {string} string "Неавторизован"
 // @Failure 403 {string} string "Нет доступа"
+// @Failure 404 {string} string "Объявление не найдено"
+// @Failure 409 {string} string "Объявление изменено параллельным запросом"
+// @Failure 412 {string} string "Версия не совпадает с If-Match"
+// @Failure 422 {string} string "Адрес не найден, укажите координаты"
 // @Failure 500 {string} string "Ошибка сервера"
 // @Router /api/cards/{id} [put]
 func (h *Handler) UpdateCard(w http.ResponseWriter, r *http.Request) {
@@ -201,6 +455,10 @@ func (h *Handler) UpdateCard(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid JSON body", http.StatusBadRequest)
 		return
 	}
+	if err := h.validator.Struct(card); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
 
 	cardID := chi.URLParam(r, "id")
 	if cardID == "" {
@@ -208,10 +466,37 @@ func (h *Handler) UpdateCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
+	}
+
 	entity := mapper.ToCardUpdateEntity(card, userId, cardID)
 	entity.ID = cardID
+	entity.Version = version
 
 	if err := h.services.Cards.UpdateCard(r.Context(), entity); err != nil {
+		if errors.Is(err, e.ErrPermissionDenied) {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, e.ErrNotFound) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, e.ErrVersionMismatch) {
+			writeVersionMismatch(w, r)
+			return
+		}
+		if errors.Is(err, e.ErrInvalidLocation) || errors.Is(err, e.ErrPublicDocumentImage) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, e.ErrAddressNotFound) {
+			http.Error(w, "address not found, specify coordinates", http.StatusUnprocessableEntity)
+			return
+		}
 		http.Error(w, "failed to update card: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -219,13 +504,184 @@ func (h *Handler) UpdateCard(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"message": "card updated successfully"})
 }
 
+// @Summary Частично обновить объявление
+// @Description Принимает JSON Merge Patch (RFC 7386): переданные поля заменяются, null удаляет поле,
+// @Description images заменяется целиком. Результат проверяется по тем же правилам, что и новое объявление.
+// @Tags Cards
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID объявления"
+// @Param input body dto.CardDocument true "Merge patch объявления"
+// @Param If-Match header string false "ETag версии, которую видел клиент"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "Некорректный patch или результат не прошел проверку"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Нет доступа"
+// @Failure 404 {string} string "Объявление не найдено"
+// @Failure 409 {string} string "Объявление изменено параллельным запросом"
+// @Failure 412 {string} string "Версия не совпадает с If-Match"
+// @Failure 415 {string} string "Неподдерживаемый Content-Type"
+// @Failure 422 {string} string "Адрес не найден, укажите координаты"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/{id} [patch]
+func (h *Handler) PatchCard(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	cardID := chi.URLParam(r, "id")
+	if cardID == "" {
+		http.Error(w, "missing card ID", http.StatusBadRequest)
+		return
+	}
+
+	patch, err := readMergePatch(r)
+	if err != nil {
+		writePatchError(w, err)
+		return
+	}
+
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
+	}
+
+	err = h.services.Cards.PatchCard(r.Context(), cardID, userID, version, func(card *entity.Card) error {
+		var doc dto.CardDocument
+		if err := applyMergePatch(mapper.ToCardDocument(card), patch, &doc); err != nil {
+			return err
+		}
+		if err := h.validator.Struct(doc); err != nil {
+			return fmt.Errorf("%w: %s", e.ErrInvalidPatch, v.FormatValidationError(err))
+		}
+		mapper.ApplyCardDocument(doc, card)
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, e.ErrInvalidPatch), errors.Is(err, e.ErrInvalidLocation), errors.Is(err, e.ErrPublicDocumentImage):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, e.ErrNoChanges):
+			http.Error(w, "no changes made to card", http.StatusBadRequest)
+		case errors.Is(err, e.ErrPermissionDenied):
+			http.Error(w, "permission denied", http.StatusForbidden)
+		case errors.Is(err, e.ErrNotFound):
+			http.Error(w, "not found", http.StatusNotFound)
+		case errors.Is(err, e.ErrVersionMismatch):
+			writeVersionMismatch(w, r)
+		case errors.Is(err, e.ErrAddressNotFound):
+			http.Error(w, "address not found, specify coordinates", http.StatusUnprocessableEntity)
+		default:
+			http.Error(w, "failed to update card: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "card updated successfully"})
+}
+
+// @Summary История изменений объявления
+// @Description Каждое обновление с измененными полями, от первого к последнему. Доступно владельцу и администраторам.
+// @Tags Cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID объявления"
+// @Success 200 {array} dto.CardRevisionResponse
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Нет доступа"
+// @Failure 404 {string} string "Объявление не найдено"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/{id}/history [get]
+func (h *Handler) GetCardHistory(w http.ResponseWriter, r *http.Request) {
+	cardID := chi.URLParam(r, "id")
+
+	revisions, err := h.services.Cards.GetCardHistory(r.Context(), cardID)
+	if err != nil {
+		switch {
+		case errors.Is(err, e.ErrUnauthorized):
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		case errors.Is(err, e.ErrPermissionDenied):
+			http.Error(w, "permission denied", http.StatusForbidden)
+		case errors.Is(err, e.ErrNotFound):
+			http.Error(w, "not found", http.StatusNotFound)
+		default:
+			http.Error(w, "failed to get card history: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resp := make([]dto.CardRevisionResponse, 0, len(revisions))
+	for _, rev := range revisions {
+		resp = append(resp, mapper.ToCardRevisionResponse(rev))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// @Summary Вернуть объявление к ревизии
+// @Description Восстанавливает поля объявления после указанной ревизии; 0 — состояние до первого изменения.
+// @Description Возврат сохраняется как новая ревизия. Только для администраторов.
+// @Tags Cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID объявления"
+// @Param revision path int true "Номер ревизии"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "Некорректный номер, объявление уже в этом состоянии или фото документа в публичном файле"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Нет доступа"
+// @Failure 404 {string} string "Объявление или ревизия не найдены"
+// @Failure 409 {string} string "Объявление изменено параллельным запросом"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/{id}/history/{revision}/revert [post]
+func (h *Handler) RevertCard(w http.ResponseWriter, r *http.Request) {
+	cardID := chi.URLParam(r, "id")
+	number, err := strconv.Atoi(chi.URLParam(r, "revision"))
+	if err != nil || number < 0 {
+		http.Error(w, "invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	if err = h.services.Cards.RevertCard(r.Context(), cardID, number); err != nil {
+		switch {
+		case errors.Is(err, e.ErrUnauthorized):
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		case errors.Is(err, e.ErrPermissionDenied):
+			http.Error(w, "permission denied", http.StatusForbidden)
+		case errors.Is(err, e.ErrNotFound):
+			http.Error(w, "not found", http.StatusNotFound)
+		case errors.Is(err, e.ErrNoChanges), errors.Is(err, e.ErrPublicDocumentImage):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, e.ErrVersionMismatch):
+			writeVersionMismatch(w, r)
+		default:
+			http.Error(w, "failed to revert card: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "card reverted successfully"})
+}
+
 // @Summary Удалить объявление
+// @Description Объявление попадает в корзину, откуда его можно восстановить до окончательного удаления.
 // @Tags Cards
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "ID объявления"
+// @Param If-Match header string false "ETag версии, которую видел клиент"
 // @Success 200 {string} string "OK"
 // @Failure 400 {string} string "Некорректный запрос"
 // @Failure 403 {string} string "Нет доступа"
+// @Failure 404 {string} string "Объявление не найдено"
+// @Failure 409 {string} string "Объявление изменено параллельным запросом"
+// @Failure 412 {string} string "Версия не совпадает с If-Match"
 // @Failure 500 {string} string "Ошибка сервера"
 // @Router /api/cards/{id} [delete]
 func (h *Handler) DeleteCard(w http.ResponseWriter, r *http.Request) {
@@ -241,11 +697,25 @@ func (h *Handler) DeleteCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.services.Cards.DeleteCard(r.Context(), cardID); err != nil {
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
+	}
+
+	if err := h.services.Cards.DeleteCard(r.Context(), cardID, version); err != nil {
 		if errors.Is(err, e.ErrPermissionDenied) {
 			http.Error(w, "permission denied", http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, e.ErrNotFound) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, e.ErrVersionMismatch) {
+			writeVersionMismatch(w, r)
+			return
+		}
 		http.Error(w, "failed to delete card: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -253,3 +723,70 @@ func (h *Handler) DeleteCard(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "card deleted successfully"})
 }
+
+// @Summary Восстановить объявление из корзины
+// @Description Доступно владельцу и администраторам, пока объявление не удалено окончательно.
+// @Tags Cards
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID объявления"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "Объявление не удалено"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Нет доступа"
+// @Failure 404 {string} string "Объявление не найдено или срок восстановления истек"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/{id}/restore [post]
+func (h *Handler) RestoreCard(w http.ResponseWriter, r *http.Request) {
+	cardID := chi.URLParam(r, "id")
+
+	if err := h.services.Cards.RestoreCard(r.Context(), cardID); err != nil {
+		switch {
+		case errors.Is(err, e.ErrUnauthorized):
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		case errors.Is(err, e.ErrPermissionDenied):
+			http.Error(w, "permission denied", http.StatusForbidden)
+		case errors.Is(err, e.ErrNotFound):
+			http.Error(w, "not found", http.StatusNotFound)
+		case errors.Is(err, e.ErrNoChanges):
+			http.Error(w, "card is not deleted", http.StatusBadRequest)
+		default:
+			http.Error(w, "failed to restore card: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "card restored successfully"})
+}
+
+// @Summary Корзина
+// @Description Удаленные объявления пользователя, которые еще можно восстановить; последние удаленные первыми.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.CardResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/trash [get]
+func (h *Handler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	cards, err := h.services.Cards.GetTrash(r.Context(), userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get trash: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]dto.CardResponse, 0, len(cards))
+	for _, l := range cards {
+		resp = append(resp, mapper.ToCardResponse(l, mapper.ToOwnerDTO(l.Owner)))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
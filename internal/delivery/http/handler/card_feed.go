@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/delivery/http/mapper"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// feedHeartbeat keeps idle streams from being closed by proxies.
+	feedHeartbeat = 15 * time.Second
+	// feedRetry is the reconnect delay suggested to EventSource clients.
+	feedRetry = 5 * time.Second
+)
+
+// @Summary Поток изменений объявлений
+// @Description Server-Sent Events: события card.created, card.updated, card.deleted и card.restored с краткими
+// @Description данными объявления. EventSource не умеет задавать заголовки, поэтому вместо токена
+// @Description можно передать одноразовый билет из POST /api/cards/stream/ticket в параметре ticket;
+// @Description для переподключения нужен новый билет. Раз в 15 секунд приходит комментарий-пинг.
+// @Description Если клиент не успевает читать события, поток закрывается — после переподключения
+// @Description стоит заново загрузить объявления.
+// @Tags Cards
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param ticket query string false "Одноразовый билет вместо заголовка Authorization"
+// @Param status query string false "Статус (lost/found)"
+// @Param category query string false "Категория"
+// @Param bbox query string false "Область карты: minLon,minLat,maxLon,maxLat"
+// @Success 200 {object} dto.CardEventResponse "Данные каждого события"
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Неавторизован, билет истек или уже использован"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/stream [get]
+func (h *Handler) StreamCards(w http.ResponseWriter, r *http.Request) {
+	filter, err := mapper.ParseCardEventFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	sub := h.services.Feed.Subscribe(filter)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", feedRetry.Milliseconds())
+	if err = rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(feedHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case event, ok := <-sub.Events:
+			if !ok {
+				// The client fell behind or the server is shutting down.
+				return
+			}
+			data, err := json.Marshal(mapper.ToCardEventResponse(event))
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		if err = rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// @Summary Билет для потока изменений
+// @Description Выдает одноразовый билет на 30 секунд для открытия /api/cards/stream из EventSource.
+// @Tags Cards
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} dto.StreamTicketResponse
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/cards/stream/ticket [post]
+func (h *Handler) CreateStreamTicket(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value("role").(string)
+
+	ticket, err := h.services.Auth.CreateStreamTicket(r.Context(), userID, role)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to create stream ticket: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.StreamTicketResponse{
+		Ticket:    ticket.Ticket,
+		ExpiresAt: ticket.ExpiresAt,
+	})
+}
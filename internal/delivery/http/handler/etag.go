@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag gives the ETag of a card or a profile, which is its version, so a
+// client can send it back in If-Match to update only what it has seen.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// writeETag sets the ETag header and reports whether the request's
+// If-None-Match already lists it, in which case 304 has been written.
+func writeETag(w http.ResponseWriter, r *http.Request, version int) bool {
+	tag := etag(version)
+	w.Header().Set("ETag", tag)
+
+	if matchesETag(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// ifMatchVersion returns the version required by the If-Match header, or 0
+// when the header is missing or "*". A header that names no version of ours
+// can never match, so it gives ok == false.
+func ifMatchVersion(r *http.Request) (version int, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	// Only a single strong tag can name one version.
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || version <= 0 || etag(version) != header {
+		return 0, false
+	}
+	return version, true
+}
+
+// writeVersionMismatch answers a write that lost to a concurrent change:
+// 412 when the client set If-Match, and 409 when it did not.
+func writeVersionMismatch(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("If-Match") != "" {
+		http.Error(w, "resource has been modified", http.StatusPreconditionFailed)
+		return
+	}
+	http.Error(w, "resource has been modified concurrently, reload it and try again", http.StatusConflict)
+}
+
+// matchesETag checks a weak comparison of If-None-Match against the tag.
+func matchesETag(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -1,24 +1,50 @@
 package handler
 
 import (
+	e "LostAndFound/internal/common/errors"
 	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/service"
 	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
 )
 
+// MaxUploadRequestSize limits a multipart upload request: the file plus room
+// for the other fields and the part headers.
+const MaxUploadRequestSize = service.MaxUploadSize + 1<<20
+
 // UploadFile godoc
-// @Summary Генерация URL для загрузки файла
+// @Summary Генерация URL для загрузки файла или прямая загрузка файла
+// @Description При JSON-запросе возвращает presigned URL для загрузки в хранилище.
+// @Description При multipart/form-data файл передается в поле file и загружается через API;
+// @Description поле visibility (public/private) должно идти перед файлом.
 // @Tags Files
 // @Accept json
+// @Accept mpfd
 // @Produce json
 // @Security BearerAuth
-// @Param input body dto.FileRequest true "Данные о файле"
+// @Param input body dto.FileRequest false "Данные о файле"
+// @Param file formData file false "Файл для прямой загрузки"
+// @Param visibility formData string false "Видимость файла (public/private)"
+// @Param Idempotency-Key header string false "Ключ для безопасного повтора запроса"
 // @Success 201 {object} dto.FileUploadResponse
 // @Failure 400 {string} string "Некорректный запрос"
 // @Failure 401 {string} string "Неавторизован"
+// @Failure 413 {string} string "Файл слишком большой"
+// @Failure 415 {string} string "Неподдерживаемый тип файла"
+// @Failure 409 {string} string "Запрос с этим Idempotency-Key еще выполняется"
+// @Failure 422 {string} string "Idempotency-Key использован с другим телом"
 // @Failure 500 {string} string "Ошибка сервера"
 // @Router /api/files/upload [post]
 func (h *Handler) UploadFile(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		h.uploadMultipartFile(w, r)
+		return
+	}
+
 	var req dto.FileRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request", http.StatusBadRequest)
@@ -46,6 +72,71 @@ func (h *Handler) UploadFile(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(res)
 }
 
+// uploadMultipartFile streams the "file" part straight to storage without
+// buffering the whole request body, unless the Idempotency middleware has
+// already read it.
+func (h *Handler) uploadMultipartFile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadRequestSize)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "invalid multipart request", http.StatusBadRequest)
+		return
+	}
+
+	visibility := ""
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			http.Error(w, "missing file", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, "invalid multipart request", http.StatusBadRequest)
+			return
+		}
+
+		switch part.FormName() {
+		case "visibility":
+			value, err := io.ReadAll(io.LimitReader(part, 16))
+			if err != nil {
+				http.Error(w, "invalid multipart request", http.StatusBadRequest)
+				return
+			}
+			visibility = string(value)
+			if visibility != "public" && visibility != "private" {
+				http.Error(w, "visibility must be public or private", http.StatusBadRequest)
+				return
+			}
+		case "file":
+			res, err := h.services.Files.UploadFile(r.Context(), userID, part.FileName(), visibility, part)
+			if err != nil {
+				switch {
+				case errors.Is(err, e.ErrFileTooLarge):
+					http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+				case errors.Is(err, e.ErrUnsupportedFileType):
+					http.Error(w, "unsupported file type", http.StatusUnsupportedMediaType)
+				default:
+					http.Error(w, "failed to upload file: "+err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(res)
+			return
+		}
+		part.Close()
+	}
+}
+
 func (h *Handler) DeleteFile(w http.ResponseWriter, r *http.Request) {
 
 }
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/delivery/http/mapper"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// maxGazetteerImportSize limits the CSV accepted by ImportGazetteer.
+const maxGazetteerImportSize = 200 << 20
+
+// @Summary Адрес по координатам
+// @Description Ищет ближайший адрес в локальном справочнике.
+// @Tags Geocoding
+// @Produce json
+// @Param lat query number true "Широта"
+// @Param lon query number true "Долгота"
+// @Success 200 {object} dto.AddressResponse
+// @Failure 400 {string} string "Некорректные координаты"
+// @Failure 404 {string} string "Адрес не найден"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/geocoding/reverse [get]
+func (h *Handler) ReverseGeocode(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	point, err := mapper.ParseLocation(q.Get("lat"), q.Get("lon"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	addr, err := h.services.Geocoding.Reverse(r.Context(), point)
+	if err != nil {
+		writeGeocodingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapper.ToAddressResponse(addr))
+}
+
+// @Summary Координаты по адресу
+// @Description Ищет улицу в городе по локальному справочнику; если улица не найдена,
+// @Description возвращается центр города.
+// @Tags Geocoding
+// @Produce json
+// @Param city query string true "Город"
+// @Param street query string false "Улица и дом"
+// @Success 200 {object} dto.AddressResponse
+// @Failure 400 {string} string "Не указан город"
+// @Failure 404 {string} string "Адрес не найден"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/geocoding/search [get]
+func (h *Handler) SearchAddress(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	city := q.Get("city")
+	if city == "" {
+		http.Error(w, "city is required", http.StatusBadRequest)
+		return
+	}
+
+	addr, err := h.services.Geocoding.Search(r.Context(), city, q.Get("street"))
+	if err != nil {
+		writeGeocodingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapper.ToAddressResponse(addr))
+}
+
+// @Summary Импорт справочника адресов
+// @Description Заменяет справочник адресов данными из CSV. Первая строка — заголовок
+// @Description с колонками city, lat, lon и необязательными street, house_number.
+// @Tags Geocoding
+// @Accept plain
+// @Produce json
+// @Security BearerAuth
+// @Param input body string true "CSV"
+// @Success 201 {object} dto.GazetteerImportResponse
+// @Failure 400 {string} string "Некорректный CSV"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 413 {string} string "Файл слишком большой"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/geocoding/import [post]
+func (h *Handler) ImportGazetteer(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, maxGazetteerImportSize)
+
+	n, err := h.services.Geocoding.ImportGazetteer(r.Context(), body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		writeGeocodingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.GazetteerImportResponse{Imported: n})
+}
+
+func writeGeocodingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, e.ErrAddressNotFound):
+		http.Error(w, "address not found", http.StatusNotFound)
+	case errors.Is(err, e.ErrInvalidGazetteer):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fmt.Sprintf("geocoding failed: %v", err), http.StatusInternalServerError)
+	}
+}
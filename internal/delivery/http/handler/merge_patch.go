@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	e "LostAndFound/internal/common/errors"
+)
+
+// PATCH requests take JSON merge patches (RFC 7386): members of the patch
+// replace those of the resource, null removes a member, and everything the
+// patch leaves out stays as it is.
+
+const mergePatchContentType = "application/merge-patch+json"
+
+var errUnsupportedPatchType = errors.New("unsupported patch content type")
+
+// readMergePatch decodes the request body as a merge patch. Only objects make
+// sense as patches of our resources.
+func readMergePatch(r *http.Request) (map[string]any, error) {
+	if header := r.Header.Get("Content-Type"); header != "" {
+		mediaType, _, err := mime.ParseMediaType(header)
+		if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+			return nil, errUnsupportedPatchType
+		}
+	}
+
+	var patch map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		return nil, fmt.Errorf("%w: the patch must be a JSON object", e.ErrInvalidPatch)
+	}
+	return patch, nil
+}
+
+// applyMergePatch applies the patch to the JSON form of doc and decodes the
+// outcome into result. Members unknown to result are rejected.
+func applyMergePatch(doc any, patch map[string]any, result any) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode document: %w", err)
+	}
+	var target map[string]any
+	if err = json.Unmarshal(data, &target); err != nil {
+		return fmt.Errorf("failed to decode document: %w", err)
+	}
+
+	if data, err = json.Marshal(mergePatch(target, patch)); err != nil {
+		return fmt.Errorf("failed to encode patched document: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(result); err != nil {
+		return fmt.Errorf("%w: %s", e.ErrInvalidPatch, err.Error())
+	}
+	return nil
+}
+
+// mergePatch is the MergePatch function of RFC 7386.
+func mergePatch(target, patch any) any {
+	members, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	result, ok := target.(map[string]any)
+	if !ok {
+		result = map[string]any{}
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = mergePatch(result[name], value)
+	}
+	return result
+}
+
+// writePatchError answers a patch that could not be read.
+func writePatchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errUnsupportedPatchType) {
+		http.Error(w, "use "+mergePatchContentType, http.StatusUnsupportedMediaType)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
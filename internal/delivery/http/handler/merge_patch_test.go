@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/delivery/http/dto"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{name: "replaces a member", target: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{name: "adds a member", target: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{name: "null deletes a member", target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{name: "null on a missing member", target: `{"a":"b"}`, patch: `{"c":null}`, want: `{"a":"b"}`},
+		{name: "nested objects merge", target: `{"a":{"b":"c","d":"e"}}`, patch: `{"a":{"d":"f","g":null}}`, want: `{"a":{"b":"c","d":"f"}}`},
+		{name: "null deletes a nested member", target: `{"a":{"b":"c","d":"e"}}`, patch: `{"a":{"b":null}}`, want: `{"a":{"d":"e"}}`},
+		{name: "object replaces a scalar", target: `{"a":"b"}`, patch: `{"a":{"c":null,"d":"e"}}`, want: `{"a":{"d":"e"}}`},
+		{name: "arrays are replaced", target: `{"a":["b","c"]}`, patch: `{"a":["d"]}`, want: `{"a":["d"]}`},
+		{name: "array elements are not merged", target: `{"a":[{"b":"c"}]}`, patch: `{"a":[{"d":"e"}]}`, want: `{"a":[{"d":"e"}]}`},
+		{name: "non-object patch replaces the target", target: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{name: "null patch replaces the target", target: `{"a":"b"}`, patch: `null`, want: `null`},
+		{name: "patch of a non-object target", target: `["a"]`, patch: `{"a":"b"}`, want: `{"a":"b"}`},
+		{name: "empty patch", target: `{"a":"b"}`, patch: `{}`, want: `{"a":"b"}`},
+	}
+
+	decode := func(t *testing.T, s string) any {
+		t.Helper()
+		var v any
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatalf("invalid JSON %s: %v", s, err)
+		}
+		return v
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergePatch(decode(t, tt.target), decode(t, tt.patch))
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("mergePatch() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyMergePatchCardDocument(t *testing.T) {
+	doc := dto.CardDocument{
+		Title:       "Ключи от машины",
+		Description: "Связка из двух ключей с брелоком",
+		City:        "Москва",
+		Status:      "lost",
+		Category:    "keys",
+	}
+
+	tests := []struct {
+		name         string
+		patch        string
+		wantPatchErr bool
+		wantValidErr bool
+		check        func(t *testing.T, got dto.CardDocument)
+	}{
+		{
+			name:  "description cleared via null",
+			patch: `{"description":null}`,
+			check: func(t *testing.T, got dto.CardDocument) {
+				if got.Description != "" {
+					t.Errorf("Description = %q, want it cleared", got.Description)
+				}
+				if got.Title != doc.Title || got.City != doc.City {
+					t.Errorf("untouched members changed: %+v", got)
+				}
+			},
+		},
+		{
+			name:  "title replaced",
+			patch: `{"title":"Ключи от дома"}`,
+			check: func(t *testing.T, got dto.CardDocument) {
+				if got.Title != "Ключи от дома" {
+					t.Errorf("Title = %q, want %q", got.Title, "Ключи от дома")
+				}
+			},
+		},
+		{name: "title removed", patch: `{"title":null}`, wantValidErr: true},
+		{name: "city removed without coordinates", patch: `{"city":null}`, wantValidErr: true},
+		{name: "unknown member", patch: `{"owner":"someone"}`, wantPatchErr: true},
+		{name: "wrong member type", patch: `{"title":42}`, wantPatchErr: true},
+	}
+
+	validate := validator.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]any
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("invalid patch: %v", err)
+			}
+
+			var got dto.CardDocument
+			err := applyMergePatch(doc, patch, &got)
+			if tt.wantPatchErr {
+				if !errors.Is(err, e.ErrInvalidPatch) {
+					t.Errorf("applyMergePatch() error = %v, want ErrInvalidPatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyMergePatch() error = %v", err)
+			}
+
+			if err = validate.Struct(got); (err != nil) != tt.wantValidErr {
+				t.Errorf("Struct() error = %v, wantErr %v", err, tt.wantValidErr)
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+		})
+	}
+}
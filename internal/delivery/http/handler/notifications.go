@@ -0,0 +1,304 @@
+package handler
+
+import (
+	e "LostAndFound/internal/common/errors"
+	v "LostAndFound/internal/common/validation"
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/delivery/http/mapper"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Входящие уведомления
+// @Description Уведомления пользователя от новых к старым вместе со статусами доставки по каналам.
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Только непрочитанные"
+// @Param limit query int false "Количество (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} dto.NotificationResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/notifications [get]
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	var limit, offset int
+	var err error
+	if s := q.Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := q.Get("offset"); s != "" {
+		if offset, err = strconv.Atoi(s); err != nil || offset < 0 {
+			http.Error(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	notifications, err := h.services.Notifications.GetNotifications(r.Context(), userID, q.Get("unread") == "true", limit, offset)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get notifications: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]dto.NotificationResponse, 0, len(notifications))
+	for _, n := range notifications {
+		resp = append(resp, mapper.ToNotificationResponse(n))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// @Summary Количество непрочитанных уведомлений
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.UnreadCountResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/notifications/unread-count [get]
+func (h *Handler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	count, err := h.services.Notifications.CountUnread(r.Context(), userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to count notifications: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.UnreadCountResponse{Count: count})
+}
+
+// @Summary Отметить уведомление прочитанным
+// @Tags Notifications
+// @Security BearerAuth
+// @Param id path string true "ID уведомления"
+// @Success 204 "Отмечено"
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Уведомление не найдено"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/notifications/{id}/read [post]
+func (h *Handler) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid notification id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.services.Notifications.MarkRead(r.Context(), userID, id); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Отметить все уведомления прочитанными
+// @Tags Notifications
+// @Security BearerAuth
+// @Success 204 "Отмечено"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/notifications/read-all [post]
+func (h *Handler) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.services.Notifications.MarkAllRead(r.Context(), userID); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Настройки уведомлений
+// @Description Включенные каналы доставки, тихие часы и каналы, доступные на сервере.
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.NotificationPreferencesResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/notifications/preferences [get]
+func (h *Handler) GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	prefs, err := h.services.Notifications.GetPreferences(r.Context(), userID)
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapper.ToNotificationPreferencesResponse(prefs, h.services.Notifications.AvailableChannels()))
+}
+
+// @Summary Изменить настройки уведомлений
+// @Description Уведомления всегда попадают во входящие, а по включенным каналам (email, telegram, webpush)
+// @Description еще и отправляются. В тихие часы отправка откладывается до их окончания.
+// @Tags Notifications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.NotificationPreferencesRequest true "Настройки"
+// @Success 200 {object} dto.NotificationPreferencesResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/notifications/preferences [put]
+func (h *Handler) UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.NotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	prefs := mapper.ToNotificationPreferencesEntity(req, userID)
+	if err := h.services.Notifications.UpdatePreferences(r.Context(), prefs); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapper.ToNotificationPreferencesResponse(prefs, h.services.Notifications.AvailableChannels()))
+}
+
+// @Summary Ключ сервера для Web Push
+// @Description VAPID-ключ для applicationServerKey при подписке в браузере. Пустой, если Web Push отключен.
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.PushKeyResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Router /api/notifications/push/key [get]
+func (h *Handler) GetPushKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.PushKeyResponse{PublicKey: h.services.Notifications.PushPublicKey()})
+}
+
+// @Summary Подписать браузер на Web Push
+// @Tags Notifications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.PushSubscriptionRequest true "PushSubscription из браузера"
+// @Success 201 {object} dto.PushSubscriptionResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/notifications/push/subscriptions [post]
+func (h *Handler) CreatePushSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.PushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	sub := mapper.ToPushSubscriptionEntity(req, userID)
+	if err := h.services.Notifications.SubscribePush(r.Context(), sub); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(mapper.ToPushSubscriptionResponse(sub))
+}
+
+// @Summary Отписать браузер от Web Push
+// @Tags Notifications
+// @Security BearerAuth
+// @Param id path string true "ID подписки"
+// @Success 204 "Удалено"
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Подписка не найдена"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/notifications/push/subscriptions/{id} [delete]
+func (h *Handler) DeletePushSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid subscription id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.services.Notifications.UnsubscribePush(r.Context(), userID, id); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeNotificationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, e.ErrInvalidPreferences), errors.Is(err, e.ErrInvalidPushSubscription):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, e.ErrNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	default:
+		http.Error(w, fmt.Sprintf("failed to process notifications: %v", err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	e "LostAndFound/internal/common/errors"
+	v "LostAndFound/internal/common/validation"
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/delivery/http/mapper"
+	"LostAndFound/internal/domain/entity"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxPlacesImportSize limits the GeoJSON accepted by ImportPlaces.
+const maxPlacesImportSize = 20 << 20
+
+// @Summary Получить дерево мест
+// @Description Возвращает кампусы с вложенными корпусами и зонами.
+// @Tags Places
+// @Produce json
+// @Param geometry query bool false "Включить GeoJSON-геометрию мест"
+// @Success 200 {array} dto.PlaceResponse
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/places [get]
+func (h *Handler) GetPlaces(w http.ResponseWriter, r *http.Request) {
+	withGeometry := r.URL.Query().Get("geometry") == "true"
+
+	places, err := h.services.Places.GetPlaceTree(r.Context(), withGeometry)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get places: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapper.ToPlaceResponses(places))
+}
+
+// @Summary Создать место
+// @Description Объявления внутри нового места автоматически к нему привязываются.
+// @Tags Places
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body dto.PlaceRequest true "Место"
+// @Success 201 {object} dto.PlaceResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/places [post]
+func (h *Handler) CreatePlace(w http.ResponseWriter, r *http.Request) {
+	var req dto.PlaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	place := mapper.ToPlaceEntity(req)
+	if err := h.services.Places.CreatePlace(r.Context(), place); err != nil {
+		writePlaceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(mapper.ToPlaceResponses([]*entity.Place{place})[0])
+}
+
+// @Summary Обновить место
+// @Description Привязка объявлений в старой и новой границах места пересчитывается.
+// @Tags Places
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID места"
+// @Param input body dto.PlaceRequest true "Место"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 404 {string} string "Место не найдено"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/places/{id} [put]
+func (h *Handler) UpdatePlace(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid place id", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.PlaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	place := mapper.ToPlaceEntity(req)
+	place.ID = id
+	if err := h.services.Places.UpdatePlace(r.Context(), place); err != nil {
+		writePlaceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "place updated successfully"})
+}
+
+// @Summary Удалить место
+// @Description Вложенные места удаляются вместе с ним, объявления перепривязываются.
+// @Tags Places
+// @Security BearerAuth
+// @Param id path string true "ID места"
+// @Success 204 "Удалено"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 404 {string} string "Место не найдено"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/places/{id} [delete]
+func (h *Handler) DeletePlace(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid place id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.services.Places.DeletePlace(r.Context(), id); err != nil {
+		writePlaceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Импорт мест из GeoJSON
+// @Description Принимает FeatureCollection с полигонами. У каждого объекта в properties
+// @Description должны быть name и kind (campus/building/zone), а у корпусов и зон — parent
+// @Description с названием родительского места из файла или из уже существующих.
+// @Tags Places
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body object true "GeoJSON FeatureCollection"
+// @Success 201 {array} dto.PlaceResponse
+// @Failure 400 {string} string "Некорректный GeoJSON"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 413 {string} string "Файл слишком большой"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/places/import [post]
+func (h *Handler) ImportPlaces(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPlacesImportSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	places, err := h.services.Places.ImportPlaces(r.Context(), data)
+	if err != nil {
+		writePlaceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(mapper.ToPlaceResponses(places))
+}
+
+func writePlaceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, e.ErrInvalidPlace):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, e.ErrNotFound):
+		http.Error(w, "place not found", http.StatusNotFound)
+	default:
+		http.Error(w, fmt.Sprintf("failed to save place: %v", err), http.StatusInternalServerError)
+	}
+}
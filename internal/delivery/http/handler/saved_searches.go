@@ -0,0 +1,190 @@
+package handler
+
+import (
+	e "LostAndFound/internal/common/errors"
+	v "LostAndFound/internal/common/validation"
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/delivery/http/mapper"
+	"LostAndFound/internal/domain/entity"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Мои сохраненные поиски
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.SavedSearchResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/saved-searches [get]
+func (h *Handler) GetSavedSearches(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	searches, err := h.services.SavedSearches.GetSavedSearches(r.Context(), userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get saved searches: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]dto.SavedSearchResponse, 0, len(searches))
+	for _, s := range searches {
+		resp = append(resp, mapper.ToSavedSearchResponse(s))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// @Summary Сохранить поиск
+// @Description query — строка параметров как у /cards/all (status, category, place_id, q,
+// @Description lat/lon/radius_m, created_from/created_to). Новые подходящие объявления
+// @Description приходят уведомлением сразу (instant) или раз в сутки (daily).
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.SavedSearchRequest true "Сохраненный поиск"
+// @Success 201 {object} dto.SavedSearchResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/saved-searches [post]
+func (h *Handler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	search, err := h.decodeSavedSearch(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = h.services.SavedSearches.CreateSavedSearch(r.Context(), search); err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(mapper.ToSavedSearchResponse(search))
+}
+
+// @Summary Изменить сохраненный поиск
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID поиска"
+// @Param input body dto.SavedSearchRequest true "Сохраненный поиск"
+// @Success 200 {object} dto.SavedSearchResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Поиск не найден"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/saved-searches/{id} [put]
+func (h *Handler) UpdateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid saved search id", http.StatusBadRequest)
+		return
+	}
+
+	search, err := h.decodeSavedSearch(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	search.ID = id
+
+	if err = h.services.SavedSearches.UpdateSavedSearch(r.Context(), search); err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapper.ToSavedSearchResponse(search))
+}
+
+// @Summary Удалить сохраненный поиск
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "ID поиска"
+// @Success 204 "Удалено"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Поиск не найден"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/saved-searches/{id} [delete]
+func (h *Handler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid saved search id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.services.SavedSearches.DeleteSavedSearch(r.Context(), userID, id); err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeSavedSearch reads the request body and parses its query with the
+// same rules as the card listing endpoints.
+func (h *Handler) decodeSavedSearch(r *http.Request, userID string) (*entity.SavedSearch, error) {
+	var req dto.SavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.New("invalid request")
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return nil, errors.New(v.FormatValidationError(err))
+	}
+
+	values, err := url.ParseQuery(strings.TrimPrefix(req.Query, "?"))
+	if err != nil {
+		return nil, errors.New("invalid query")
+	}
+	filter, err := mapper.ParseCardFilter(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.ToSavedSearchEntity(req, filter, userID), nil
+}
+
+func writeSavedSearchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, e.ErrInvalidSavedSearch), errors.Is(err, e.ErrInvalidRadius), errors.Is(err, e.ErrInvalidLocation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, e.ErrNotFound):
+		http.Error(w, "saved search not found", http.StatusNotFound)
+	default:
+		http.Error(w, fmt.Sprintf("failed to save search: %v", err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/delivery/http/dto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// @Summary Привязать Telegram
+// @Description Выдает одноразовый код на 15 минут. Ссылка открывает бота, который привязывает чат
+// @Description к аккаунту; без ссылки код можно отправить боту командой /start <код>.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 201 {object} dto.TelegramLinkResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/telegram/link [post]
+func (h *Handler) CreateTelegramLink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	link, err := h.services.Telegram.CreateLink(r.Context(), userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to create telegram link: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.TelegramLinkResponse{
+		Code:      link.Code,
+		URL:       link.URL,
+		ExpiresAt: link.ExpiresAt,
+	})
+}
+
+// @Summary Отвязать Telegram
+// @Tags users
+// @Security BearerAuth
+// @Success 204 "Отвязано"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Пользователь не найден"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/telegram/link [delete]
+func (h *Handler) DeleteTelegramLink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.services.Telegram.Unlink(r.Context(), userID); err != nil {
+		if errors.Is(err, e.ErrNotFound) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, fmt.Sprintf("failed to unlink telegram: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"LostAndFound/internal/delivery/http/mapper"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// GetCardsTile godoc
+// @Summary Векторный тайл с объявлениями
+// @Description Mapbox Vector Tile со слоем cards; у каждой точки есть свойства id, status и category.
+// @Tags Tiles
+// @Produce application/vnd.mapbox-vector-tile
+// @Param z path int true "Масштаб (0-22)"
+// @Param x path int true "Колонка тайла"
+// @Param y path int true "Строка тайла"
+// @Success 200 {file} binary "Тайл"
+// @Success 204 {string} string "В тайле нет объявлений"
+// @Failure 400 {string} string "Некорректные координаты тайла"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /tiles/cards/{z}/{x}/{y}.mvt [get]
+func (h *Handler) GetCardsTile(w http.ResponseWriter, r *http.Request) {
+	if format, _ := r.Context().Value(middleware.URLFormatCtxKey).(string); format != "mvt" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	z, errZ := strconv.Atoi(chi.URLParam(r, "z"))
+	x, errX := strconv.Atoi(chi.URLParam(r, "x"))
+	y, errY := strconv.Atoi(chi.URLParam(r, "y"))
+	if errZ != nil || errX != nil || errY != nil {
+		http.Error(w, "invalid tile coordinates", http.StatusBadRequest)
+		return
+	}
+
+	tile := mapper.ToTile(z, x, y)
+	if !tile.Valid() {
+		http.Error(w, "invalid tile coordinates", http.StatusBadRequest)
+		return
+	}
+
+	data, err := h.services.Cards.GetCardsTile(r.Context(), tile)
+	if err != nil {
+		http.Error(w, "failed to render tile", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	if len(data) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.mapbox-vector-tile")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
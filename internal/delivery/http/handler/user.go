@@ -5,8 +5,10 @@ import (
 	utils "LostAndFound/internal/common/validation"
 	"LostAndFound/internal/delivery/http/dto"
 	"LostAndFound/internal/delivery/http/mapper"
+	"LostAndFound/internal/domain/entity"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 )
 
@@ -15,7 +17,10 @@ import (
 // @Tags users
 // @Security BearerAuth
 // @Produce json
+// @Param If-None-Match header string false "ETag известной версии профиля"
 // @Success 200 {object} dto.UserResponse
+// @Header 200 {string} ETag "Версия профиля"
+// @Success 304 {string} string "Not modified"
 // @Failure 401 {string} string "Unauthorized"
 // @Failure 404 {string} string "User not found"
 // @Router /users [get]
@@ -32,10 +37,13 @@ func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if writeETag(w, r, user.Version) {
+		return
+	}
 	userDTO := mapper.ToUserDTO(user)
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(userDTO)
 }
 
@@ -44,7 +52,10 @@ func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 // @Tags users
 // @Produce json
 // @Param id query string true "User ID"
+// @Param If-None-Match header string false "ETag известной версии профиля"
 // @Success 200 {object} dto.UserResponse
+// @Header 200 {string} ETag "Версия профиля"
+// @Success 304 {string} string "Not modified"
 // @Failure 404 {string} string "User not found"
 // @Router /users/profile [get]
 func (h *Handler) GetProfileByID(w http.ResponseWriter, r *http.Request) {
@@ -56,24 +67,31 @@ func (h *Handler) GetProfileByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if writeETag(w, r, user.Version) {
+		return
+	}
 	userDTO := mapper.ToUserDTO(user)
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(userDTO)
 }
 
 // UpdateProfile обновляет профиль текущего пользователя
 // @Summary Обновить свой профиль
+// @Description Пустые поля оставляют значение без изменений; частичное обновление — PATCH /users/me.
 // @Tags users
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param data body dto.UpdateUserRequest true "Данные для обновления"
+// @Param If-Match header string false "ETag версии, которую видел клиент"
 // @Success 200 {string} string "OK"
 // @Failure 400 {string} string "Invalid request or no changes"
 // @Failure 401 {string} string "Unauthorized"
 // @Failure 404 {string} string "User not found"
+// @Failure 409 {string} string "Profile was modified concurrently"
+// @Failure 412 {string} string "Version does not match If-Match"
 // @Failure 500 {string} string "Error updating user"
 // @Router /users/update [put]
 func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
@@ -94,8 +112,15 @@ func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
+	}
+
 	user := mapper.ToUserEntity(dto.UserRegisterRequest(req))
 	user.ID = id
+	user.Version = version
 
 	if err := h.services.Users.UpdateProfile(r.Context(), user); err != nil {
 		if errors.Is(err, e.ErrNoChanges) {
@@ -106,6 +131,10 @@ func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "user not found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, e.ErrVersionMismatch) {
+			writeVersionMismatch(w, r)
+			return
+		}
 		http.Error(w, "error updating user", http.StatusInternalServerError)
 		return
 	}
@@ -113,3 +142,187 @@ func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "profile updated successfully"})
 }
+
+// PatchProfile частично обновляет профиль текущего пользователя
+// @Summary Частично обновить свой профиль
+// @Description Принимает JSON Merge Patch (RFC 7386). Результат проверяется по правилам регистрации;
+// @Description пароль в профиль не входит и меняется, только если передан в patch.
+// @Tags users
+// @Security BearerAuth
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param data body dto.UserDocument true "Merge patch профиля"
+// @Param If-Match header string false "ETag версии, которую видел клиент"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "Invalid patch or no changes"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "User not found"
+// @Failure 409 {string} string "Profile was modified concurrently"
+// @Failure 412 {string} string "Version does not match If-Match"
+// @Failure 415 {string} string "Unsupported Content-Type"
+// @Failure 500 {string} string "Error updating user"
+// @Router /users/me [patch]
+func (h *Handler) PatchProfile(w http.ResponseWriter, r *http.Request) {
+	id, ok := r.Context().Value("userID").(string)
+	if !ok || id == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	patch, err := readMergePatch(r)
+	if err != nil {
+		writePatchError(w, err)
+		return
+	}
+
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
+	}
+
+	err = h.services.Users.PatchProfile(r.Context(), id, version, func(user *entity.User) error {
+		var doc dto.UserDocument
+		if err := applyMergePatch(mapper.ToUserDocument(user), patch, &doc); err != nil {
+			return err
+		}
+		if err := h.validator.Struct(doc); err != nil {
+			return fmt.Errorf("%w: %s", e.ErrInvalidPatch, utils.FormatValidationError(err))
+		}
+		mapper.ApplyUserDocument(doc, user)
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, e.ErrInvalidPatch):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, e.ErrNoChanges):
+			http.Error(w, "no changes made to profile", http.StatusBadRequest)
+		case errors.Is(err, e.ErrNotFound):
+			http.Error(w, "user not found", http.StatusNotFound)
+		case errors.Is(err, e.ErrVersionMismatch):
+			writeVersionMismatch(w, r)
+		default:
+			http.Error(w, "error updating user", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "profile updated successfully"})
+}
+
+// UpdateAvatar устанавливает аватар текущего пользователя
+// @Summary Установить аватар
+// @Description Принимает ключ файла, загруженного через /files. Изображение обрезается до квадрата
+// @Description и сохраняется в двух размерах, предыдущий аватар удаляется из хранилища.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param data body dto.UpdateAvatarRequest true "Ключ загруженного файла"
+// @Param If-Match header string false "ETag версии профиля, которую видел клиент"
+// @Success 200 {object} dto.UserResponse
+// @Header 200 {string} ETag "Новая версия профиля"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "File belongs to another user"
+// @Failure 404 {string} string "File not found"
+// @Failure 409 {string} string "Profile was modified concurrently"
+// @Failure 412 {string} string "Version does not match If-Match"
+// @Failure 415 {string} string "File is not an image"
+// @Failure 500 {string} string "Error updating avatar"
+// @Router /users/avatar [put]
+func (h *Handler) UpdateAvatar(w http.ResponseWriter, r *http.Request) {
+	id, ok := r.Context().Value("userID").(string)
+	if !ok || id == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.UpdateAvatarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, utils.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
+	}
+
+	user, err := h.services.Users.SetAvatar(r.Context(), id, req.Key, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, e.ErrPermissionDenied):
+			http.Error(w, "permission denied", http.StatusForbidden)
+		case errors.Is(err, e.ErrFileNotFound), errors.Is(err, e.ErrNotFound):
+			http.Error(w, "file not found", http.StatusNotFound)
+		case errors.Is(err, e.ErrUnsupportedFileType):
+			http.Error(w, "file is not an image", http.StatusUnsupportedMediaType)
+		case errors.Is(err, e.ErrVersionMismatch):
+			writeVersionMismatch(w, r)
+		default:
+			http.Error(w, "error updating avatar", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", etag(user.Version))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(mapper.ToUserDTO(user))
+}
+
+// DeleteAvatar удаляет аватар текущего пользователя
+// @Summary Удалить аватар
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param If-Match header string false "ETag версии профиля, которую видел клиент"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "No avatar set"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "Profile was modified concurrently"
+// @Failure 412 {string} string "Version does not match If-Match"
+// @Failure 500 {string} string "Error deleting avatar"
+// @Router /users/avatar [delete]
+func (h *Handler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	id, ok := r.Context().Value("userID").(string)
+	if !ok || id == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
+	}
+
+	if err := h.services.Users.DeleteAvatar(r.Context(), id, version); err != nil {
+		if errors.Is(err, e.ErrNoChanges) {
+			http.Error(w, "no avatar set", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, e.ErrNotFound) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, e.ErrVersionMismatch) {
+			writeVersionMismatch(w, r)
+			return
+		}
+		http.Error(w, "error deleting avatar", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "avatar deleted successfully"})
+}
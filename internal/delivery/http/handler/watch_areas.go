@@ -0,0 +1,173 @@
+package handler
+
+import (
+	e "LostAndFound/internal/common/errors"
+	v "LostAndFound/internal/common/validation"
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/delivery/http/mapper"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Мои зоны отслеживания
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.WatchAreaResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/watch-areas [get]
+func (h *Handler) GetWatchAreas(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	areas, err := h.services.WatchAreas.GetWatchAreas(r.Context(), userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get watch areas: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]dto.WatchAreaResponse, 0, len(areas))
+	for _, a := range areas {
+		resp = append(resp, mapper.ToWatchAreaResponse(a))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// @Summary Создать зону отслеживания
+// @Description Зона задается точкой с радиусом (radius_m, до 10 км) или местом (place_id).
+// @Description О новых объявлениях внутри зоны, подходящих под категорию и статус, приходит уведомление.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.WatchAreaRequest true "Зона отслеживания"
+// @Success 201 {object} dto.WatchAreaResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/watch-areas [post]
+func (h *Handler) CreateWatchArea(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.WatchAreaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	area := mapper.ToWatchAreaEntity(req, userID)
+	if err := h.services.WatchAreas.CreateWatchArea(r.Context(), area); err != nil {
+		writeWatchAreaError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(mapper.ToWatchAreaResponse(area))
+}
+
+// @Summary Изменить зону отслеживания
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID зоны"
+// @Param input body dto.WatchAreaRequest true "Зона отслеживания"
+// @Success 200 {object} dto.WatchAreaResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Зона не найдена"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/watch-areas/{id} [put]
+func (h *Handler) UpdateWatchArea(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid watch area id", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.WatchAreaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	area := mapper.ToWatchAreaEntity(req, userID)
+	area.ID = id
+	if err := h.services.WatchAreas.UpdateWatchArea(r.Context(), area); err != nil {
+		writeWatchAreaError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapper.ToWatchAreaResponse(area))
+}
+
+// @Summary Удалить зону отслеживания
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "ID зоны"
+// @Success 204 "Удалено"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Зона не найдена"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /users/watch-areas/{id} [delete]
+func (h *Handler) DeleteWatchArea(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid watch area id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.services.WatchAreas.DeleteWatchArea(r.Context(), userID, id); err != nil {
+		writeWatchAreaError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeWatchAreaError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, e.ErrInvalidWatchArea), errors.Is(err, e.ErrInvalidLocation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, e.ErrNotFound):
+		http.Error(w, "watch area not found", http.StatusNotFound)
+	default:
+		http.Error(w, fmt.Sprintf("failed to save watch area: %v", err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	e "LostAndFound/internal/common/errors"
+	v "LostAndFound/internal/common/validation"
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/delivery/http/mapper"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Список вебхуков
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.WebhookResponse
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/webhooks [get]
+func (h *Handler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.services.Webhooks.GetWebhooks(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get webhooks: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]dto.WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		resp = append(resp, mapper.ToWebhookResponse(webhook))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// @Summary Зарегистрировать вебхук
+// @Description На url отправляются POST-запросы с событиями объявлений; пустой events подписывает
+// @Description на все события. Секрет для проверки подписи возвращается только в этом ответе:
+// @Description заголовок X-Webhook-Signature имеет вид t=<unix-время>,v1=<hex HMAC-SHA256 строки "<t>.<тело>">.
+// @Description Неуспешные доставки повторяются с экспоненциальной задержкой.
+// @Tags Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.WebhookRequest true "Вебхук"
+// @Success 201 {object} dto.WebhookResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/webhooks [post]
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req dto.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	webhook := mapper.ToWebhookEntity(req)
+	if err := h.services.Webhooks.CreateWebhook(r.Context(), webhook); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	resp := mapper.ToWebhookResponse(webhook)
+	resp.Secret = webhook.Secret
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// @Summary Обновить вебхук
+// @Description Секрет не меняется.
+// @Tags Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID вебхука"
+// @Param input body dto.WebhookRequest true "Вебхук"
+// @Success 200 {object} dto.WebhookResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 404 {string} string "Вебхук не найден"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/webhooks/{id} [put]
+func (h *Handler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid webhook id", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, v.FormatValidationError(err), http.StatusBadRequest)
+		return
+	}
+
+	webhook := mapper.ToWebhookEntity(req)
+	webhook.ID = id
+	if err := h.services.Webhooks.UpdateWebhook(r.Context(), webhook); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapper.ToWebhookResponse(webhook))
+}
+
+// @Summary Удалить вебхук
+// @Description Журнал доставок удаляется вместе с вебхуком.
+// @Tags Webhooks
+// @Security BearerAuth
+// @Param id path string true "ID вебхука"
+// @Success 204 "Удалено"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 404 {string} string "Вебхук не найден"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid webhook id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.services.Webhooks.DeleteWebhook(r.Context(), id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Журнал доставок вебхука
+// @Description Доставки от новых к старым с числом попыток, кодом ответа и последней ошибкой.
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID вебхука"
+// @Param limit query int false "Количество (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} dto.WebhookDeliveryResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 404 {string} string "Вебхук не найден"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/webhooks/{id}/deliveries [get]
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid webhook id", http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	var limit, offset int
+	var err error
+	if s := q.Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := q.Get("offset"); s != "" {
+		if offset, err = strconv.Atoi(s); err != nil || offset < 0 {
+			http.Error(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	deliveries, err := h.services.Webhooks.GetDeliveries(r.Context(), id, limit, offset)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	resp := make([]dto.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, mapper.ToWebhookDeliveryResponse(d))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// @Summary Повторить доставку
+// @Description Ставит в очередь новую доставку с тем же содержимым и event_id; исходная остается в журнале.
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID вебхука"
+// @Param deliveryID path string true "ID доставки"
+// @Success 202 {object} dto.WebhookDeliveryResponse
+// @Failure 400 {string} string "Некорректный запрос"
+// @Failure 401 {string} string "Неавторизован"
+// @Failure 403 {string} string "Доступ запрещен"
+// @Failure 404 {string} string "Доставка не найдена"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /api/webhooks/{id}/deliveries/{deliveryID}/replay [post]
+func (h *Handler) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	deliveryID := chi.URLParam(r, "deliveryID")
+	if err := h.validator.Var(id, "required,uuid"); err != nil {
+		http.Error(w, "invalid webhook id", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Var(deliveryID, "required,uuid"); err != nil {
+		http.Error(w, "invalid delivery id", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := h.services.Webhooks.ReplayDelivery(r.Context(), id, deliveryID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(mapper.ToWebhookDeliveryResponse(delivery))
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, e.ErrInvalidWebhook):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, e.ErrNotFound):
+		http.Error(w, "webhook not found", http.StatusNotFound)
+	default:
+		http.Error(w, fmt.Sprintf("failed to process webhook: %v", err), http.StatusInternalServerError)
+	}
+}
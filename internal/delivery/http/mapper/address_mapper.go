@@ -0,0 +1,16 @@
+package mapper
+
+import (
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/domain/entity"
+)
+
+func ToAddressResponse(a *entity.Address) dto.AddressResponse {
+	return dto.AddressResponse{
+		City:        a.City,
+		Street:      a.Street,
+		HouseNumber: a.HouseNumber,
+		Latitude:    a.Latitude,
+		Longitude:   a.Longitude,
+	}
+}
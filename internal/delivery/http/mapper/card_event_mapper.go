@@ -0,0 +1,56 @@
+package mapper
+
+import (
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/domain/entity"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// ParseCardEventFilter reads the status, category and bbox of a card feed.
+func ParseCardEventFilter(q url.Values) (entity.CardEventFilter, error) {
+	var filter entity.CardEventFilter
+
+	if status := entity.CardStatus(q.Get("status")); status != "" {
+		if !slices.Contains(filterStatuses, status) {
+			return entity.CardEventFilter{}, fmt.Errorf("invalid status")
+		}
+		filter.Status = status
+	}
+	if category := entity.CardCategory(q.Get("category")); category != "" {
+		if !slices.Contains(filterCategories, category) {
+			return entity.CardEventFilter{}, fmt.Errorf("invalid category")
+		}
+		filter.Category = category
+	}
+	if q.Has("bbox") {
+		bbox, err := ParseBoundingBox(q.Get("bbox"))
+		if err != nil {
+			return entity.CardEventFilter{}, err
+		}
+		filter.BBox = &bbox
+	}
+	return filter, nil
+}
+
+func ToCardEventResponse(event *entity.CardEvent) dto.CardEventResponse {
+	card := &event.Card
+	return dto.CardEventResponse{
+		Type:       string(event.Type),
+		OccurredAt: event.OccurredAt,
+		Card: dto.CardEventCardResponse{
+			ID:        card.ID,
+			Title:     card.Title,
+			Status:    string(card.Status),
+			Category:  string(card.Category),
+			Latitude:  latitudeOf(card.Location),
+			Longitude: longitudeOf(card.Location),
+			City:      card.City,
+			Street:    card.Street,
+			PlaceID:   card.PlaceID,
+			PlaceName: card.PlaceName,
+			CreatedAt: card.CreatedAt,
+		},
+	}
+}
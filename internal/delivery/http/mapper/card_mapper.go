@@ -14,13 +14,12 @@ func ToCardEntity(r dto.CreateCardRequest, ownerID string) *entity.Card {
 		ID:          uuid.NewString(),
 		Title:       r.Title,
 		Description: r.Description,
-		Latitude:    r.Latitude,
-		Longitude:   r.Longitude,
+		Location:    ToLocation(r.Latitude, r.Longitude),
 		City:        r.City,
 		Street:      r.Street,
-		PreviewURL:  r.PreviewURL,
-		Images:      r.Images,
+		Images:      ToCardImages(r.Images),
 		Status:      entity.CardStatus(r.Status),
+		Category:    entity.CardCategory(r.Category),
 		OwnerID:     ownerID,
 		CreatedAt:   time.Now(),
 	}
@@ -35,27 +34,179 @@ func ToCardUpdateEntity(dto dto.UpdateCardRequest, ownerID, cardID string) *enti
 		City:        dto.City,
 		Street:      dto.Street,
 		Status:      entity.CardStatus(dto.Status),
-		PreviewURL:  dto.PreviewURL,
-		Latitude:    dto.Latitude,
-		Longitude:   dto.Longitude,
-		Images:      dto.Images,
+		Category:    entity.CardCategory(dto.Category),
+		Location:    ToLocation(dto.Latitude, dto.Longitude),
+		Images:      ToCardImages(dto.Images),
 	}
 }
 
+// ToCardDocument gives the editable fields of the card for a merge patch.
+func ToCardDocument(l *entity.Card) dto.CardDocument {
+	images := make([]dto.ImageRequest, 0, len(l.Images))
+	for _, img := range l.Images {
+		images = append(images, dto.ImageRequest{URL: img.URL, Caption: img.Caption, IsCover: img.IsCover})
+	}
+	return dto.CardDocument{
+		Title:       l.Title,
+		Description: l.Description,
+		City:        l.City,
+		Street:      l.Street,
+		Status:      string(l.Status),
+		Category:    string(l.Category),
+		Latitude:    latitudeOf(l.Location),
+		Longitude:   longitudeOf(l.Location),
+		Images:      images,
+	}
+}
+
+// ApplyCardDocument sets the editable fields of the card from a patched
+// document; what the document leaves out is cleared.
+func ApplyCardDocument(doc dto.CardDocument, l *entity.Card) {
+	l.Title = doc.Title
+	l.Description = doc.Description
+	l.City = doc.City
+	l.Street = doc.Street
+	l.Status = entity.CardStatus(doc.Status)
+	l.Category = entity.CardCategory(doc.Category)
+	l.Location = ToLocation(doc.Latitude, doc.Longitude)
+	l.Images = ToCardImages(doc.Images)
+}
+
 func ToCardResponse(l *entity.Card, owner dto.OwnerDTO) dto.CardResponse {
 	return dto.CardResponse{
 		ID:          l.ID,
 		Title:       l.Title,
 		Description: l.Description,
-		Latitude:    l.Latitude,
-		Longitude:   l.Longitude,
+		Latitude:    latitudeOf(l.Location),
+		Longitude:   longitudeOf(l.Location),
 		DistanceM:   l.DistanceM,
 		City:        l.City,
 		Street:      l.Street,
 		PreviewURL:  l.PreviewURL,
-		Images:      l.Images,
+		Images:      ToImageResponses(l.Images),
 		Status:      string(l.Status),
+		Category:    string(l.Category),
+		PlaceID:     l.PlaceID,
+		PlaceName:   l.PlaceName,
 		Owner:       owner,
 		CreatedAt:   l.CreatedAt,
+		DeletedAt:   l.DeletedAt,
+	}
+}
+
+func ToCardImages(images []dto.ImageRequest) []entity.CardImage {
+	if len(images) == 0 {
+		return nil
+	}
+
+	result := make([]entity.CardImage, 0, len(images))
+	for _, img := range images {
+		result = append(result, entity.CardImage{
+			URL:     img.URL,
+			Caption: img.Caption,
+			IsCover: img.IsCover,
+		})
+	}
+	return result
+}
+
+func ToImageResponses(images []entity.CardImage) []dto.ImageResponse {
+	result := make([]dto.ImageResponse, 0, len(images))
+	for _, img := range images {
+		result = append(result, dto.ImageResponse{
+			ID:       img.ID,
+			URL:      img.URL,
+			Position: img.Position,
+			Caption:  img.Caption,
+			Width:    img.Width,
+			Height:   img.Height,
+			IsCover:  img.IsCover,
+		})
+	}
+	return result
+}
+
+func ToOwnerDTO(o entity.Owner) dto.OwnerDTO {
+	return dto.OwnerDTO{
+		ID:        o.ID,
+		Name:      o.Name,
+		Surname:   o.Surname,
+		Phone:     o.Phone,
+		Telegram:  o.Telegram,
+		AvatarURL: o.AvatarURL,
+	}
+}
+
+func ToSimilarCardResponse(s *entity.SimilarCard) dto.SimilarCardResponse {
+	return dto.SimilarCardResponse{
+		Card:     ToCardResponse(s.Card, ToOwnerDTO(s.Card.Owner)),
+		ImageURL: s.ImageURL,
+		Distance: s.Distance,
+	}
+}
+
+// ToLocation builds a location from optional request coordinates; it is nil
+// unless both are set, so 0 is a regular coordinate rather than "missing".
+func ToLocation(lat, lon *float64) *entity.Location {
+	if lat == nil || lon == nil {
+		return nil
+	}
+	return &entity.Location{Latitude: *lat, Longitude: *lon}
+}
+
+func latitudeOf(l *entity.Location) *float64 {
+	if l == nil {
+		return nil
+	}
+	return &l.Latitude
+}
+
+func longitudeOf(l *entity.Location) *float64 {
+	if l == nil {
+		return nil
+	}
+	return &l.Longitude
+}
+
+func ToCardRevisionResponse(rev *entity.CardRevision) dto.CardRevisionResponse {
+	resp := dto.CardRevisionResponse{
+		Number:     rev.Number,
+		AuthorID:   rev.AuthorID,
+		RevertedTo: rev.RevertedTo,
+		Changes:    make([]dto.CardFieldChangeResponse, 0, len(rev.Fields)),
+		CreatedAt:  rev.CreatedAt,
+	}
+	for _, field := range rev.Fields {
+		resp.Changes = append(resp.Changes, dto.CardFieldChangeResponse{
+			Field: string(field),
+			Old:   snapshotField(rev.Before, field),
+			New:   snapshotField(rev.After, field),
+		})
+	}
+	return resp
+}
+
+func snapshotField(s entity.CardSnapshot, field entity.CardField) any {
+	switch field {
+	case entity.CardFieldTitle:
+		return s.Title
+	case entity.CardFieldDescription:
+		return s.Description
+	case entity.CardFieldCity:
+		return s.City
+	case entity.CardFieldStreet:
+		return s.Street
+	case entity.CardFieldStatus:
+		return s.Status
+	case entity.CardFieldCategory:
+		return s.Category
+	case entity.CardFieldLocation:
+		if s.Location == nil {
+			return nil
+		}
+		return dto.PointResponse{Latitude: s.Location.Latitude, Longitude: s.Location.Longitude}
+	case entity.CardFieldImages:
+		return ToImageResponses(s.Images)
 	}
+	return nil
 }
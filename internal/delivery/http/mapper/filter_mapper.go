@@ -0,0 +1,116 @@
+package mapper
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+// filterDateLayout is accepted for created_from/created_to besides RFC 3339.
+const filterDateLayout = "2006-01-02"
+
+var (
+	filterStatuses   = []entity.CardStatus{entity.StatusLost, entity.StatusFound}
+	filterCategories = []entity.CardCategory{
+		entity.CategoryDocuments, entity.CategoryElectronics, entity.CategoryClothing,
+		entity.CategoryAccessories, entity.CategoryKeys, entity.CategoryBags, entity.CategoryOther,
+	}
+)
+
+// ParseCardFilter reads the listing filter from query parameters: status,
+// category, place_id, q (text), lat/lon with radius (km) or radius_m, and
+// created_from/created_to as dates or RFC 3339 timestamps. The same
+// representation is stored in saved searches.
+func ParseCardFilter(q url.Values) (entity.CardFilter, error) {
+	var filter entity.CardFilter
+
+	if status := entity.CardStatus(q.Get("status")); status != "" {
+		if !slices.Contains(filterStatuses, status) {
+			return entity.CardFilter{}, fmt.Errorf("invalid status")
+		}
+		filter.Status = status
+	}
+	if category := entity.CardCategory(q.Get("category")); category != "" {
+		if !slices.Contains(filterCategories, category) {
+			return entity.CardFilter{}, fmt.Errorf("invalid category")
+		}
+		filter.Category = category
+	}
+	if placeID := q.Get("place_id"); placeID != "" {
+		if _, err := uuid.Parse(placeID); err != nil {
+			return entity.CardFilter{}, fmt.Errorf("invalid place_id")
+		}
+		filter.PlaceID = placeID
+	}
+	filter.Text = q.Get("q")
+
+	if q.Has("lat") || q.Has("lon") {
+		center, err := ParseLocation(q.Get("lat"), q.Get("lon"))
+		if err != nil {
+			return entity.CardFilter{}, err
+		}
+		radius, err := ParseRadius(q.Get("radius"), q.Get("radius_m"))
+		if err != nil {
+			return entity.CardFilter{}, err
+		}
+		filter.Area = &entity.CircleArea{Center: center, Radius: radius}
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseFilterTime(q.Get("created_from")); err != nil {
+		return entity.CardFilter{}, fmt.Errorf("invalid created_from")
+	}
+	if filter.CreatedTo, err = parseFilterTime(q.Get("created_to")); err != nil {
+		return entity.CardFilter{}, fmt.Errorf("invalid created_to")
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return entity.CardFilter{}, fmt.Errorf("created_from must be before created_to")
+	}
+
+	return filter, nil
+}
+
+// FormatCardFilter is the inverse of ParseCardFilter.
+func FormatCardFilter(filter entity.CardFilter) url.Values {
+	q := url.Values{}
+	if filter.Status != "" {
+		q.Set("status", string(filter.Status))
+	}
+	if filter.Category != "" {
+		q.Set("category", string(filter.Category))
+	}
+	if filter.PlaceID != "" {
+		q.Set("place_id", filter.PlaceID)
+	}
+	if filter.Text != "" {
+		q.Set("q", filter.Text)
+	}
+	if filter.Area != nil {
+		q.Set("lat", strconv.FormatFloat(filter.Area.Center.Latitude, 'f', -1, 64))
+		q.Set("lon", strconv.FormatFloat(filter.Area.Center.Longitude, 'f', -1, 64))
+		q.Set("radius_m", strconv.FormatFloat(filter.Area.Radius.Meters(), 'f', -1, 64))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		q.Set("created_from", filter.CreatedFrom.UTC().Format(time.RFC3339))
+	}
+	if !filter.CreatedTo.IsZero() {
+		q.Set("created_to", filter.CreatedTo.UTC().Format(time.RFC3339))
+	}
+	return q
+}
+
+func parseFilterTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(filterDateLayout, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
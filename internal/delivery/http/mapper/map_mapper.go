@@ -0,0 +1,110 @@
+package mapper
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/domain/entity"
+)
+
+// ParseBoundingBox parses a "minLon,minLat,maxLon,maxLat" query value.
+func ParseBoundingBox(s string) (entity.BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return entity.BoundingBox{}, fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat")
+	}
+
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return entity.BoundingBox{}, fmt.Errorf("invalid bbox coordinate %q", p)
+		}
+		v[i] = f
+	}
+
+	bbox := entity.BoundingBox{MinLon: v[0], MinLat: v[1], MaxLon: v[2], MaxLat: v[3]}
+	if bbox.MinLon < -180 || bbox.MaxLon > 180 || bbox.MinLat < -90 || bbox.MaxLat > 90 {
+		return entity.BoundingBox{}, fmt.Errorf("bbox is out of range")
+	}
+	if bbox.MinLon >= bbox.MaxLon || bbox.MinLat >= bbox.MaxLat {
+		return entity.BoundingBox{}, fmt.Errorf("bbox minimum must be less than maximum")
+	}
+	return bbox, nil
+}
+
+// ParseLocation parses latitude and longitude query values and checks that
+// they are within range. 0 is a valid coordinate.
+func ParseLocation(lat, lon string) (entity.Location, error) {
+	var l entity.Location
+	var err error
+	if l.Latitude, err = strconv.ParseFloat(strings.TrimSpace(lat), 64); err != nil || math.IsNaN(l.Latitude) {
+		return entity.Location{}, fmt.Errorf("invalid latitude")
+	}
+	if l.Longitude, err = strconv.ParseFloat(strings.TrimSpace(lon), 64); err != nil || math.IsNaN(l.Longitude) {
+		return entity.Location{}, fmt.Errorf("invalid longitude")
+	}
+	if !l.Valid() {
+		return entity.Location{}, fmt.Errorf("coordinates are out of range")
+	}
+	return l, nil
+}
+
+// ParseRadius parses a search radius given either in kilometers or, when
+// radiusM is set, in meters.
+func ParseRadius(radiusKm, radiusM string) (entity.Distance, error) {
+	value, unit := radiusKm, entity.Kilometer
+	if radiusM != "" {
+		value, unit = radiusM, entity.Meter
+	}
+
+	r, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(r) || math.IsInf(r, 0) || r <= 0 {
+		return 0, fmt.Errorf("invalid radius")
+	}
+	return entity.Distance(r) * unit, nil
+}
+
+func ToMapResponse(view *entity.MapView) dto.MapResponse {
+	if view.Clustered {
+		resp := dto.MapResponse{Mode: "clusters", Clusters: make([]dto.MapClusterResponse, 0, len(view.Clusters))}
+		for _, c := range view.Clusters {
+			statuses := make(map[string]int, len(c.StatusCounts))
+			for status, n := range c.StatusCounts {
+				statuses[string(status)] = n
+			}
+			resp.Clusters = append(resp.Clusters, dto.MapClusterResponse{
+				Latitude:  c.Latitude,
+				Longitude: c.Longitude,
+				Count:     c.Count,
+				Statuses:  statuses,
+				CardID:    c.CardID,
+			})
+		}
+		return resp
+	}
+
+	resp := dto.MapResponse{Mode: "cards", Cards: make([]dto.MapCardResponse, 0, len(view.Cards))}
+	for _, card := range view.Cards {
+		if card.Location == nil {
+			continue
+		}
+		resp.Cards = append(resp.Cards, dto.MapCardResponse{
+			ID:         card.ID,
+			Title:      card.Title,
+			Status:     string(card.Status),
+			Category:   string(card.Category),
+			Latitude:   card.Location.Latitude,
+			Longitude:  card.Location.Longitude,
+			PreviewURL: card.PreviewURL,
+		})
+	}
+	return resp
+}
+
+func ToTile(z, x, y int) entity.Tile {
+	return entity.Tile{Z: z, X: x, Y: y}
+}
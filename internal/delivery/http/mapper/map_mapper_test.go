@@ -0,0 +1,103 @@
+package mapper
+
+import (
+	"testing"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+func TestParseBoundingBox(t *testing.T) {
+	tests := []struct {
+		name    string
+		bbox    string
+		want    entity.BoundingBox
+		wantErr bool
+	}{
+		{name: "world", bbox: "-180,-90,180,90", want: entity.BoundingBox{MinLon: -180, MinLat: -90, MaxLon: 180, MaxLat: 90}},
+		{name: "city", bbox: "37.5, 55.7, 37.7, 55.8", want: entity.BoundingBox{MinLon: 37.5, MinLat: 55.7, MaxLon: 37.7, MaxLat: 55.8}},
+		{name: "past the antimeridian", bbox: "-180.0001,-90,180,90", wantErr: true},
+		{name: "past the pole", bbox: "-180,-90,180,90.0001", wantErr: true},
+		{name: "min above max", bbox: "180,-90,-180,90", wantErr: true},
+		{name: "NaN", bbox: "NaN,-90,180,90", wantErr: true},
+		{name: "infinite", bbox: "-Inf,-90,180,90", wantErr: true},
+		{name: "three values", bbox: "-180,-90,180", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBoundingBox(tt.bbox)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBoundingBox(%q) error = %v, wantErr %v", tt.bbox, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseBoundingBox(%q) = %+v, want %+v", tt.bbox, got, tt.want)
+			}
+		})
+	}
+}
+
+// The coordinate ranges themselves are covered by entity.Location.Valid; this
+// checks the parsing around it.
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		lat     string
+		lon     string
+		want    entity.Location
+		wantErr bool
+	}{
+		{name: "origin", lat: "0", lon: "0", want: entity.Location{}},
+		{name: "negative zero", lat: "-0", lon: "-0.0", want: entity.Location{}},
+		{name: "surrounding spaces", lat: " 55.75 ", lon: " 37.61 ", want: entity.Location{Latitude: 55.75, Longitude: 37.61}},
+		{name: "out of range", lat: "90.0001", lon: "0", wantErr: true},
+		{name: "latitude NaN", lat: "NaN", lon: "0", wantErr: true},
+		{name: "longitude NaN", lat: "0", lon: "nan", wantErr: true},
+		{name: "infinite", lat: "+Inf", lon: "0", wantErr: true},
+		{name: "missing latitude", lat: "", lon: "0", wantErr: true},
+		{name: "not a number", lat: "north", lon: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLocation(tt.lat, tt.lon)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLocation(%q, %q) error = %v, wantErr %v", tt.lat, tt.lon, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseLocation(%q, %q) = %+v, want %+v", tt.lat, tt.lon, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRadius(t *testing.T) {
+	tests := []struct {
+		name     string
+		radiusKm string
+		radiusM  string
+		want     entity.Distance
+		wantErr  bool
+	}{
+		{name: "kilometers", radiusKm: "2.5", want: 2500 * entity.Meter},
+		{name: "meters", radiusM: "300", want: 300 * entity.Meter},
+		{name: "meters take precedence", radiusKm: "5", radiusM: "300", want: 300 * entity.Meter},
+		{name: "zero", radiusKm: "0", wantErr: true},
+		{name: "negative", radiusM: "-1", wantErr: true},
+		{name: "NaN", radiusKm: "NaN", wantErr: true},
+		{name: "infinite", radiusM: "Inf", wantErr: true},
+		{name: "missing", wantErr: true},
+		{name: "not a number", radiusKm: "far", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRadius(tt.radiusKm, tt.radiusM)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRadius(%q, %q) error = %v, wantErr %v", tt.radiusKm, tt.radiusM, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseRadius(%q, %q) = %v, want %v", tt.radiusKm, tt.radiusM, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package mapper
+
+import (
+	"fmt"
+	"time"
+
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/domain/entity"
+)
+
+func ToNotificationResponse(n *entity.Notification) dto.NotificationResponse {
+	deliveries := make([]dto.DeliveryResponse, 0, len(n.Deliveries))
+	for _, d := range n.Deliveries {
+		deliveries = append(deliveries, dto.DeliveryResponse{
+			Channel:  string(d.Channel),
+			Status:   string(d.Status),
+			Attempts: d.Attempts,
+			SentAt:   d.SentAt,
+		})
+	}
+
+	return dto.NotificationResponse{
+		ID:         n.ID,
+		Kind:       string(n.Kind),
+		Title:      n.Title,
+		Body:       n.Body,
+		CardID:     n.CardID,
+		CreatedAt:  n.CreatedAt,
+		ReadAt:     n.ReadAt,
+		Deliveries: deliveries,
+	}
+}
+
+func ToNotificationPreferencesEntity(r dto.NotificationPreferencesRequest, userID string) *entity.NotificationPreferences {
+	prefs := &entity.NotificationPreferences{
+		UserID:   userID,
+		Channels: make([]entity.NotificationChannel, 0, len(r.Channels)),
+	}
+	for _, channel := range r.Channels {
+		prefs.Channels = append(prefs.Channels, entity.NotificationChannel(channel))
+	}
+
+	// The times are validated by the request's datetime tags.
+	if q := r.QuietHours; q != nil {
+		start, _ := time.Parse("15:04", q.Start)
+		end, _ := time.Parse("15:04", q.End)
+		prefs.QuietHours = &entity.QuietHours{
+			Start:    start.Hour()*60 + start.Minute(),
+			End:      end.Hour()*60 + end.Minute(),
+			TimeZone: q.TimeZone,
+		}
+	}
+	return prefs
+}
+
+func ToNotificationPreferencesResponse(p *entity.NotificationPreferences, available []entity.NotificationChannel) dto.NotificationPreferencesResponse {
+	resp := dto.NotificationPreferencesResponse{
+		Channels:          make([]string, 0, len(p.Channels)),
+		AvailableChannels: make([]string, 0, len(available)),
+	}
+	for _, channel := range p.Channels {
+		resp.Channels = append(resp.Channels, string(channel))
+	}
+	for _, channel := range available {
+		resp.AvailableChannels = append(resp.AvailableChannels, string(channel))
+	}
+
+	if q := p.QuietHours; q != nil {
+		resp.QuietHours = &dto.QuietHours{
+			Start:    fmt.Sprintf("%02d:%02d", q.Start/60, q.Start%60),
+			End:      fmt.Sprintf("%02d:%02d", q.End/60, q.End%60),
+			TimeZone: q.TimeZone,
+		}
+	}
+	return resp
+}
+
+func ToPushSubscriptionEntity(r dto.PushSubscriptionRequest, userID string) *entity.PushSubscription {
+	return &entity.PushSubscription{
+		UserID:   userID,
+		Endpoint: r.Endpoint,
+		P256dh:   r.Keys.P256dh,
+		Auth:     r.Keys.Auth,
+	}
+}
+
+func ToPushSubscriptionResponse(s *entity.PushSubscription) dto.PushSubscriptionResponse {
+	return dto.PushSubscriptionResponse{
+		ID:        s.ID,
+		Endpoint:  s.Endpoint,
+		CreatedAt: s.CreatedAt,
+	}
+}
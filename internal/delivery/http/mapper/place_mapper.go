@@ -0,0 +1,37 @@
+package mapper
+
+import (
+	"github.com/google/uuid"
+
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/domain/entity"
+)
+
+func ToPlaceEntity(r dto.PlaceRequest) *entity.Place {
+	return &entity.Place{
+		ID:       uuid.NewString(),
+		ParentID: r.ParentID,
+		Kind:     entity.PlaceKind(r.Kind),
+		Name:     r.Name,
+		Geometry: r.Geometry,
+	}
+}
+
+func ToPlaceResponses(places []*entity.Place) []dto.PlaceResponse {
+	result := make([]dto.PlaceResponse, 0, len(places))
+	for _, p := range places {
+		resp := dto.PlaceResponse{
+			ID:        p.ID,
+			ParentID:  p.ParentID,
+			Kind:      string(p.Kind),
+			Name:      p.Name,
+			Geometry:  p.Geometry,
+			CreatedAt: p.CreatedAt,
+		}
+		if len(p.Children) > 0 {
+			resp.Children = ToPlaceResponses(p.Children)
+		}
+		result = append(result, resp)
+	}
+	return result
+}
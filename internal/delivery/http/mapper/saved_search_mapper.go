@@ -0,0 +1,29 @@
+package mapper
+
+import (
+	"github.com/google/uuid"
+
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/domain/entity"
+)
+
+func ToSavedSearchEntity(r dto.SavedSearchRequest, filter entity.CardFilter, userID string) *entity.SavedSearch {
+	return &entity.SavedSearch{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Name:      r.Name,
+		Filter:    filter,
+		Frequency: entity.DigestFrequency(r.Frequency),
+	}
+}
+
+func ToSavedSearchResponse(s *entity.SavedSearch) dto.SavedSearchResponse {
+	return dto.SavedSearchResponse{
+		ID:        s.ID,
+		Name:      s.Name,
+		Query:     FormatCardFilter(s.Filter).Encode(),
+		Frequency: string(s.Frequency),
+		LastRunAt: s.LastRunAt,
+		CreatedAt: s.CreatedAt,
+	}
+}
@@ -22,6 +22,28 @@ func ToUserEntity(r dto.UserRegisterRequest) *entity.User {
 	}
 }
 
+// ToUserDocument gives the editable fields of the user for a merge patch.
+func ToUserDocument(u *entity.User) dto.UserDocument {
+	return dto.UserDocument{
+		Email:    u.Email,
+		Name:     u.Name,
+		Surname:  u.Surname,
+		Phone:    u.Phone,
+		Telegram: u.Telegram,
+	}
+}
+
+// ApplyUserDocument sets the editable fields of the user from a patched
+// document.
+func ApplyUserDocument(doc dto.UserDocument, u *entity.User) {
+	u.Email = doc.Email
+	u.Password = doc.Password
+	u.Name = doc.Name
+	u.Surname = doc.Surname
+	u.Phone = doc.Phone
+	u.Telegram = doc.Telegram
+}
+
 func ToUserDTO(u *entity.User) *dto.UserResponse {
 	return &dto.UserResponse{
 		ID:       u.ID,
@@ -30,5 +52,8 @@ func ToUserDTO(u *entity.User) *dto.UserResponse {
 		Surname:  u.Surname,
 		Phone:    u.Phone,
 		Telegram: u.Telegram,
+
+		AvatarURL:      u.AvatarURL,
+		AvatarThumbURL: u.AvatarThumbURL,
 	}
 }
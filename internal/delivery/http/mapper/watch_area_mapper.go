@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"github.com/google/uuid"
+
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/domain/entity"
+)
+
+func ToWatchAreaEntity(r dto.WatchAreaRequest, userID string) *entity.WatchArea {
+	return &entity.WatchArea{
+		ID:       uuid.NewString(),
+		UserID:   userID,
+		Name:     r.Name,
+		Center:   ToLocation(r.Latitude, r.Longitude),
+		Radius:   entity.Distance(r.RadiusM) * entity.Meter,
+		PlaceID:  r.PlaceID,
+		Category: entity.CardCategory(r.Category),
+		Status:   entity.CardStatus(r.Status),
+	}
+}
+
+func ToWatchAreaResponse(a *entity.WatchArea) dto.WatchAreaResponse {
+	return dto.WatchAreaResponse{
+		ID:        a.ID,
+		Name:      a.Name,
+		Latitude:  latitudeOf(a.Center),
+		Longitude: longitudeOf(a.Center),
+		RadiusM:   a.Radius.Meters(),
+		PlaceID:   a.PlaceID,
+		PlaceName: a.PlaceName,
+		Category:  string(a.Category),
+		Status:    string(a.Status),
+		CreatedAt: a.CreatedAt,
+	}
+}
@@ -0,0 +1,56 @@
+package mapper
+
+import (
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/domain/entity"
+)
+
+func ToWebhookEntity(r dto.WebhookRequest) *entity.Webhook {
+	webhook := &entity.Webhook{
+		URL:         r.URL,
+		Description: r.Description,
+		Active:      r.Active == nil || *r.Active,
+	}
+	for _, event := range r.Events {
+		webhook.Events = append(webhook.Events, entity.CardEventType(event))
+	}
+	return webhook
+}
+
+// ToWebhookResponse leaves the secret out; it is shown once, when the
+// webhook is created.
+func ToWebhookResponse(w *entity.Webhook) dto.WebhookResponse {
+	events := make([]string, 0, len(w.Events))
+	for _, event := range w.Events {
+		events = append(events, string(event))
+	}
+
+	return dto.WebhookResponse{
+		ID:          w.ID,
+		URL:         w.URL,
+		Events:      events,
+		Description: w.Description,
+		Active:      w.Active,
+		CreatedAt:   w.CreatedAt,
+	}
+}
+
+func ToWebhookDeliveryResponse(d *entity.WebhookDelivery) dto.WebhookDeliveryResponse {
+	resp := dto.WebhookDeliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Payload:        d.Payload,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == entity.DeliveryPending {
+		resp.NextAttemptAt = &d.NextAttemptAt
+	}
+	return resp
+}
@@ -0,0 +1,266 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	// idempotencyReplayedHeader marks a response served from the stored one.
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// MaxIdempotentBodySize is the body limit for JSON requests.
+	MaxIdempotentBodySize = 1 << 20
+	// idempotencyPendingTTL bounds how long a request that never finished,
+	// e.g. because the server went down, blocks its key.
+	idempotencyPendingTTL = time.Minute
+	// maxIdempotencyAttempts bounds how many times a request is retried as
+	// a fresh one when its stored response expires under it.
+	maxIdempotencyAttempts = 3
+)
+
+// idempotentHeaders are the response headers stored and replayed with the
+// body.
+var idempotentHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// idempotentResponse is what is kept in Redis under an idempotency key.
+// A zero Status means the first request is still being handled.
+type idempotentResponse struct {
+	BodyHash string              `json:"body_hash"`
+	Status   int                 `json:"status"`
+	Header   map[string][]string `json:"header,omitempty"`
+	Body     []byte              `json:"body,omitempty"`
+}
+
+// Idempotency makes a request with an Idempotency-Key header safe to retry:
+// the first response is stored for ttl and sent again for every repeat with
+// the same key and body, while reusing the key with a different body gives
+// 422. Keys are scoped to the user and the path. Server errors and rate
+// limiting are not stored, so such requests can be retried for real.
+// The body, up to maxBodySize, is hashed while the handler reads it, so an
+// upload is still streamed and nothing is held in memory.
+func Idempotency(redisClient *redis.Client, ttl time.Duration, maxBodySize int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			idempotencyKey := r.Header.Get(idempotencyHeader)
+			if idempotencyKey == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(idempotencyKey) > maxIdempotencyKeyLength {
+				http.Error(w, "idempotency key is too long", http.StatusBadRequest)
+				return
+			}
+
+			userID, _ := ctx.Value(ctxUserIDKey).(string)
+			key := fmt.Sprintf("idempotency:%s:%s:%s", userID, r.URL.Path, idempotencyKey)
+			body := http.MaxBytesReader(w, r.Body, maxBodySize)
+			hasher := newBodyHasher(r)
+
+			pending, _ := json.Marshal(idempotentResponse{})
+			for attempt := 1; ; attempt++ {
+				acquired, err := redisClient.SetNX(ctx, key, pending, idempotencyPendingTTL).Result()
+				if err != nil {
+					http.Error(w, "internal idempotency error", http.StatusInternalServerError)
+					return
+				}
+				if acquired {
+					break
+				}
+
+				stored, err := loadIdempotentResponse(r, redisClient, key)
+				if err != nil {
+					http.Error(w, "internal idempotency error", http.StatusInternalServerError)
+					return
+				}
+				if stored == nil {
+					// The key expired in between, so the request is handled
+					// as a fresh one.
+					if attempt < maxIdempotencyAttempts {
+						continue
+					}
+					http.Error(w, "internal idempotency error", http.StatusInternalServerError)
+					return
+				}
+				if stored.Status == 0 {
+					http.Error(w, "request with this idempotency key is in progress", http.StatusConflict)
+					return
+				}
+
+				if _, err = io.Copy(hasher, body); err != nil {
+					var maxBytesErr *http.MaxBytesError
+					if errors.As(err, &maxBytesErr) {
+						http.Error(w, "request body is too large", http.StatusRequestEntityTooLarge)
+						return
+					}
+					http.Error(w, "failed to read request body", http.StatusBadRequest)
+					return
+				}
+				if stored.BodyHash != hasher.Sum() {
+					http.Error(w, "idempotency key was used with a different request body", http.StatusUnprocessableEntity)
+					return
+				}
+				replayIdempotentResponse(w, stored)
+				return
+			}
+
+			tee := io.TeeReader(body, hasher)
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{tee, body}
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			// A detached context: the response has to be stored even if the
+			// client is already gone, as that is when it will retry.
+			storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+			defer cancel()
+
+			if rec.status >= http.StatusInternalServerError || rec.status == http.StatusTooManyRequests {
+				redisClient.Del(storeCtx, key)
+				return
+			}
+
+			// The hash has to cover the part of the body the handler did not
+			// read. Once the response is written the server may have closed
+			// the body; then the response is not stored and a retry is
+			// handled anew.
+			if _, err := io.Copy(io.Discard, tee); err != nil {
+				redisClient.Del(storeCtx, key)
+				return
+			}
+
+			response := idempotentResponse{
+				BodyHash: hasher.Sum(),
+				Status:   rec.status,
+				Header:   map[string][]string{},
+				Body:     rec.body.Bytes(),
+			}
+			for _, name := range idempotentHeaders {
+				if values := w.Header().Values(name); len(values) > 0 {
+					response.Header[name] = values
+				}
+			}
+			data, err := json.Marshal(response)
+			if err != nil {
+				redisClient.Del(storeCtx, key)
+				return
+			}
+			redisClient.Set(storeCtx, key, data, ttl)
+		})
+	}
+}
+
+func loadIdempotentResponse(r *http.Request, redisClient *redis.Client, key string) (*idempotentResponse, error) {
+	data, err := redisClient.Get(r.Context(), key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stored idempotentResponse
+	if err = json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, stored *idempotentResponse) {
+	for name, values := range stored.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.Header().Set(idempotencyReplayedHeader, "true")
+	w.WriteHeader(stored.Status)
+	w.Write(stored.Body)
+}
+
+// bodyHasher hashes a request body as it is written to it. The boundary of
+// a multipart body is replaced with a fixed one, since clients pick a new
+// boundary for every attempt.
+type bodyHasher struct {
+	hash      hash.Hash
+	delimiter []byte
+	// pending is the tail that may be the start of a delimiter.
+	pending []byte
+}
+
+var canonicalDelimiter = []byte("--boundary")
+
+func newBodyHasher(r *http.Request) *bodyHasher {
+	h := &bodyHasher{hash: sha256.New()}
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" && params["boundary"] != "" {
+		h.delimiter = []byte("--" + params["boundary"])
+	}
+	return h
+}
+
+func (h *bodyHasher) Write(p []byte) (int, error) {
+	if len(h.delimiter) == 0 {
+		return h.hash.Write(p)
+	}
+
+	h.pending = append(h.pending, p...)
+	for {
+		i := bytes.Index(h.pending, h.delimiter)
+		if i < 0 {
+			break
+		}
+		h.hash.Write(h.pending[:i])
+		h.hash.Write(canonicalDelimiter)
+		h.pending = h.pending[i+len(h.delimiter):]
+	}
+	if keep := len(h.delimiter) - 1; len(h.pending) > keep {
+		h.hash.Write(h.pending[:len(h.pending)-keep])
+		h.pending = append(h.pending[:0], h.pending[len(h.pending)-keep:]...)
+	}
+	return len(p), nil
+}
+
+// Sum returns the hash of everything written so far.
+func (h *bodyHasher) Sum() string {
+	h.hash.Write(h.pending)
+	h.pending = nil
+	return hex.EncodeToString(h.hash.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status, rec.wroteHeader = status, true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
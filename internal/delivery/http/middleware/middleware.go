@@ -10,6 +10,8 @@ import (
 const (
 	ctxUserIDKey string = "userID"
 	ctxRoleKey   string = "role"
+
+	streamTicketParam = "ticket"
 )
 
 func AuthMiddleware(tokenManager *auth.TokenManager) func(http.Handler) http.Handler {
@@ -43,6 +45,16 @@ func AuthMiddleware(tokenManager *auth.TokenManager) func(http.Handler) http.Han
 				return
 			}
 
+			isBlocked, err := tokenManager.CacheRepo.IsUserBlocked(r.Context(), claims.UserID)
+			if err != nil {
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			if isBlocked {
+				http.Error(w, "user is banned", http.StatusForbidden)
+				return
+			}
+
 			userID := claims.UserID
 			role := claims.Role
 			ctx := context.WithValue(r.Context(), ctxUserIDKey, userID)
@@ -53,6 +65,41 @@ func AuthMiddleware(tokenManager *auth.TokenManager) func(http.Handler) http.Han
 	}
 }
 
+// OptionalAuthMiddleware authenticates the request when a valid bearer token
+// is present and lets anonymous requests through untouched.
+func OptionalAuthMiddleware(tokenManager *auth.TokenManager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := tokenManager.GetToken(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			isBlacklisted, err := tokenManager.CacheRepo.IsTokenBlacklisted(r.Context(), token)
+			if err != nil || isBlacklisted {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := tokenManager.Parse(token)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if isBlocked, err := tokenManager.CacheRepo.IsUserBlocked(r.Context(), claims.UserID); err != nil || isBlocked {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ctxUserIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, ctxRoleKey, claims.Role)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 func GetUserID(ctx context.Context) string {
 	id, _ := ctx.Value(ctxUserIDKey).(string)
 	return id
@@ -75,3 +122,69 @@ func AdminOnlyMiddleware() func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// StreamTicketMiddleware authenticates a streaming request by the ticket
+// query parameter. Browsers cannot set headers on an EventSource, so they
+// open the stream with a single-use ticket instead of the access token,
+// which would otherwise end up in access and proxy logs. Requests without
+// a ticket go through AuthMiddleware.
+func StreamTicketMiddleware(tokenManager *auth.TokenManager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withToken := AuthMiddleware(tokenManager)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.URL.Query().Get(streamTicketParam)
+			if id == "" {
+				withToken.ServeHTTP(w, r)
+				return
+			}
+
+			ticket, err := tokenManager.CacheRepo.TakeStreamTicket(r.Context(), id)
+			if err != nil {
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			if ticket == nil {
+				http.Error(w, "ticket expired or already used", http.StatusUnauthorized)
+				return
+			}
+
+			isBlocked, err := tokenManager.CacheRepo.IsUserBlocked(r.Context(), ticket.UserID)
+			if err != nil {
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			if isBlocked {
+				http.Error(w, "user is banned", http.StatusForbidden)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ctxUserIDKey, ticket.UserID)
+			ctx = context.WithValue(ctx, ctxRoleKey, ticket.Role)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RedactQuery hides the values of the given query parameters in
+// r.RequestURI, which is what the access log prints. Routing and handlers
+// read r.URL and still see the values.
+func RedactQuery(params ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			redacted := false
+			for _, param := range params {
+				if query.Has(param) {
+					query.Set(param, "REDACTED")
+					redacted = true
+				}
+			}
+			if redacted {
+				r = r.WithContext(r.Context())
+				r.RequestURI = r.URL.EscapedPath() + "?" + query.Encode()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
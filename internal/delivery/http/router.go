@@ -1,6 +1,7 @@
 package router
 
 import (
+	server_config "LostAndFound/internal/config/server_config"
 	"LostAndFound/internal/delivery/http/handler"
 	m "LostAndFound/internal/delivery/http/middleware"
 
@@ -15,9 +16,11 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func NewRouter(h *handler.Handler, redisClient *redis.Client) *chi.Mux {
+func NewRouter(h *handler.Handler, redisClient *redis.Client, idempotency server_config.IdempotencyConfig) *chi.Mux {
 	r := chi.NewRouter()
 
+	// Tickets and tokens in the URL must not reach the access log.
+	r.Use(m.RedactQuery("ticket", "access_token"))
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
@@ -39,6 +42,20 @@ func NewRouter(h *handler.Handler, redisClient *redis.Client) *chi.Mux {
 			r.Use(m.AuthMiddleware(h.TokenManager))
 			r.With(m.RateLimitByUserID(redisClient, 30, 1*time.Minute)).Get("/", h.GetProfile)
 			r.With(m.RateLimitByUserID(redisClient, 3, 5*time.Minute)).Put("/update", h.UpdateProfile)
+			r.With(m.RateLimitByUserID(redisClient, 3, 5*time.Minute)).Patch("/me", h.PatchProfile)
+			r.With(m.RateLimitByUserID(redisClient, 5, 10*time.Minute)).Put("/avatar", h.UpdateAvatar)
+			r.With(m.RateLimitByUserID(redisClient, 5, 10*time.Minute)).Delete("/avatar", h.DeleteAvatar)
+			r.With(m.RateLimitByUserID(redisClient, 30, 1*time.Minute)).Get("/watch-areas", h.GetWatchAreas)
+			r.With(m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Post("/watch-areas", h.CreateWatchArea)
+			r.With(m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Put("/watch-areas/{id}", h.UpdateWatchArea)
+			r.With(m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Delete("/watch-areas/{id}", h.DeleteWatchArea)
+			r.With(m.RateLimitByUserID(redisClient, 30, 1*time.Minute)).Get("/saved-searches", h.GetSavedSearches)
+			r.With(m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Post("/saved-searches", h.CreateSavedSearch)
+			r.With(m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Put("/saved-searches/{id}", h.UpdateSavedSearch)
+			r.With(m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Delete("/saved-searches/{id}", h.DeleteSavedSearch)
+			r.With(m.RateLimitByUserID(redisClient, 5, 10*time.Minute)).Post("/telegram/link", h.CreateTelegramLink)
+			r.With(m.RateLimitByUserID(redisClient, 5, 10*time.Minute)).Delete("/telegram/link", h.DeleteTelegramLink)
+			r.With(m.RateLimitByUserID(redisClient, 30, 1*time.Minute)).Get("/trash", h.GetTrash)
 		})
 		r.With(m.RateLimitByUserID(redisClient, 20, 1*time.Minute)).Get("/profile", h.GetProfileByID)
 	})
@@ -46,19 +63,80 @@ func NewRouter(h *handler.Handler, redisClient *redis.Client) *chi.Mux {
 	r.Route("/cards", func(r chi.Router) {
 		r.Group(func(r chi.Router) {
 			r.Use(m.AuthMiddleware(h.TokenManager))
-			r.With(m.RateLimitByUserID(redisClient, 5, 1*time.Minute)).Post("/", h.CreateCard)
+			r.With(m.Idempotency(redisClient, idempotency.TTL, m.MaxIdempotentBodySize), m.RateLimitByUserID(redisClient, 5, 1*time.Minute)).Post("/", h.CreateCard)
 			r.With(m.RateLimitByUserID(redisClient, 5, 1*time.Minute)).Put("/{id}", h.UpdateCard)
+			r.With(m.RateLimitByUserID(redisClient, 5, 1*time.Minute)).Patch("/{id}", h.PatchCard)
 			r.With(m.RateLimitByUserID(redisClient, 5, 1*time.Minute)).Delete("/{id}", h.DeleteCard)
+			r.With(m.RateLimitByUserID(redisClient, 5, 1*time.Minute)).Post("/{id}/restore", h.RestoreCard)
+			r.With(m.RateLimitByUserID(redisClient, 30, 1*time.Minute)).Get("/{id}/history", h.GetCardHistory)
+			r.With(m.AdminOnlyMiddleware()).Post("/{id}/history/{revision}/revert", h.RevertCard)
+			r.With(m.RateLimitByUserID(redisClient, 20, 1*time.Minute)).Put("/{id}/images/order", h.ReorderImages)
+			r.With(m.RateLimitByUserID(redisClient, 20, 1*time.Minute)).Patch("/{id}/images/{imageID}", h.UpdateImage)
+			r.With(m.RateLimitByUserID(redisClient, 20, 1*time.Minute)).Delete("/{id}/images/{imageID}", h.RemoveImage)
 		})
 
-		r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Get("/all", h.GetAllCards)
-		r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Get("/{id}", h.GetCardByID)
-		r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Get("/near", h.GetCardsNear)
+		r.Group(func(r chi.Router) {
+			r.Use(m.OptionalAuthMiddleware(h.TokenManager))
+			r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Get("/all", h.GetAllCards)
+			r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Get("/{id}", h.GetCardByID)
+			r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Get("/near", h.GetCardsNear)
+			r.With(m.RateLimitByUserID(redisClient, 120, 1*time.Minute)).Get("/map", h.GetCardsMap)
+			r.With(m.RateLimitByUserID(redisClient, 30, 1*time.Minute)).Get("/{id}/similar-images", h.GetSimilarImages)
+		})
+
+		r.With(m.AuthMiddleware(h.TokenManager), m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Post("/stream/ticket", h.CreateStreamTicket)
+		r.With(m.StreamTicketMiddleware(h.TokenManager), m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Get("/stream", h.StreamCards)
+	})
+
+	r.Route("/notifications", func(r chi.Router) {
+		r.Use(m.AuthMiddleware(h.TokenManager))
+		r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Get("/", h.GetNotifications)
+		r.With(m.RateLimitByUserID(redisClient, 120, 1*time.Minute)).Get("/unread-count", h.GetUnreadCount)
+		r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Post("/{id}/read", h.MarkNotificationRead)
+		r.With(m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Post("/read-all", h.MarkAllNotificationsRead)
+		r.With(m.RateLimitByUserID(redisClient, 30, 1*time.Minute)).Get("/preferences", h.GetNotificationPreferences)
+		r.With(m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Put("/preferences", h.UpdateNotificationPreferences)
+		r.With(m.RateLimitByUserID(redisClient, 30, 1*time.Minute)).Get("/push/key", h.GetPushKey)
+		r.With(m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Post("/push/subscriptions", h.CreatePushSubscription)
+		r.With(m.RateLimitByUserID(redisClient, 10, 1*time.Minute)).Delete("/push/subscriptions/{id}", h.DeletePushSubscription)
+	})
+
+	r.Route("/places", func(r chi.Router) {
+		r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Get("/", h.GetPlaces)
+		r.Group(func(r chi.Router) {
+			r.Use(m.AuthMiddleware(h.TokenManager))
+			r.Use(m.AdminOnlyMiddleware())
+			r.Post("/", h.CreatePlace)
+			r.Post("/import", h.ImportPlaces)
+			r.Put("/{id}", h.UpdatePlace)
+			r.Delete("/{id}", h.DeletePlace)
+		})
+	})
+
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Use(m.AuthMiddleware(h.TokenManager))
+		r.Use(m.AdminOnlyMiddleware())
+		r.Get("/", h.GetWebhooks)
+		r.Post("/", h.CreateWebhook)
+		r.Put("/{id}", h.UpdateWebhook)
+		r.Delete("/{id}", h.DeleteWebhook)
+		r.Get("/{id}/deliveries", h.GetWebhookDeliveries)
+		r.Post("/{id}/deliveries/{deliveryID}/replay", h.ReplayWebhookDelivery)
+	})
+
+	r.Route("/geocoding", func(r chi.Router) {
+		r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Get("/reverse", h.ReverseGeocode)
+		r.With(m.RateLimitByUserID(redisClient, 60, 1*time.Minute)).Get("/search", h.SearchAddress)
+		r.With(m.AuthMiddleware(h.TokenManager), m.AdminOnlyMiddleware()).Post("/import", h.ImportGazetteer)
+	})
+
+	r.Route("/tiles", func(r chi.Router) {
+		r.With(m.RateLimitByUserID(redisClient, 600, 1*time.Minute)).Get("/cards/{z}/{x}/{y}", h.GetCardsTile)
 	})
 
 	r.Route("/files", func(r chi.Router) {
 		r.Use(m.AuthMiddleware(h.TokenManager))
-		r.With(m.RateLimitByUserID(redisClient, 5, 10*time.Minute)).Post("/", h.UploadFile)
+		r.With(m.Idempotency(redisClient, idempotency.TTL, handler.MaxUploadRequestSize), m.RateLimitByUserID(redisClient, 5, 10*time.Minute)).Post("/", h.UploadFile)
 		r.With(m.RateLimitByUserID(redisClient, 5, 10*time.Minute)).Delete("/{key}", h.DeleteFile)
 	})
 
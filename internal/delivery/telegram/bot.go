@@ -0,0 +1,228 @@
+package telegram
+
+import (
+	tgapi "LostAndFound/internal/adapters/telegram"
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// handleTimeout bounds the processing of a single message, including
+	// photo uploads.
+	handleTimeout = time.Minute
+	// pollRetryDelay is the pause after a failed getUpdates call.
+	pollRetryDelay = 5 * time.Second
+	// nearbyRadius and nearbyLimit describe the search around a shared
+	// location.
+	nearbyRadius = 1 * entity.Kilometer
+	nearbyLimit  = 10
+)
+
+const helpText = `Я помогаю искать потерянные вещи.
+
+/new — разместить объявление
+/near — объявления рядом с вами
+/unlink — отвязать аккаунт
+/cancel — отменить текущее действие
+
+Чтобы получать уведомления и размещать объявления, привяжите аккаунт в профиле на сайте.`
+
+// Bot is the Telegram front end of the service. It long-polls the Bot API
+// and handles updates one by one, so the dialog state needs no locking.
+type Bot struct {
+	api      *tgapi.Client
+	services *service.Service
+	sessions map[int64]*session
+}
+
+// Run handles updates until ctx is done.
+func (b *Bot) Run(ctx context.Context) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := b.api.GetUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("failed to get telegram updates", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pollRetryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message != nil {
+				b.handleMessage(ctx, update.Message)
+			}
+		}
+	}
+}
+
+func (b *Bot) handleMessage(c context.Context, msg *tgapi.Message) {
+	// Cards and accounts are personal, so group chats are ignored.
+	if msg.Chat.Type != "private" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, handleTimeout)
+	defer cancel()
+
+	chatID := msg.Chat.ID
+	command, arg := parseCommand(msg.Text)
+	switch command {
+	case "/start":
+		b.start(ctx, chatID, arg)
+		return
+	case "/help":
+		b.send(ctx, chatID, helpText, removeKeyboard)
+		return
+	case "/cancel":
+		delete(b.sessions, chatID)
+		b.send(ctx, chatID, "Действие отменено.", removeKeyboard)
+		return
+	case "/new":
+		b.startDialog(ctx, chatID)
+		return
+	case "/near":
+		delete(b.sessions, chatID)
+		b.send(ctx, chatID, "Отправьте геопозицию, и я покажу объявления в радиусе километра.", locationKeyboard)
+		return
+	case "/unlink":
+		b.unlink(ctx, chatID)
+		return
+	}
+
+	if s := b.session(chatID); s != nil {
+		b.continueDialog(ctx, chatID, s, msg)
+		return
+	}
+	if msg.Location != nil {
+		b.sendNearby(ctx, chatID, entity.Location{Latitude: msg.Location.Latitude, Longitude: msg.Location.Longitude})
+		return
+	}
+	b.send(ctx, chatID, helpText, removeKeyboard)
+}
+
+// start links the chat when the user came with a deep link from the site.
+func (b *Bot) start(ctx context.Context, chatID int64, code string) {
+	delete(b.sessions, chatID)
+	if code == "" {
+		b.send(ctx, chatID, helpText, removeKeyboard)
+		return
+	}
+
+	user, err := b.services.Telegram.Link(ctx, code, chatID)
+	switch {
+	case errors.Is(err, e.ErrInvalidLinkCode):
+		b.send(ctx, chatID, "Ссылка недействительна или устарела. Получите новую в профиле на сайте.", removeKeyboard)
+	case err != nil:
+		slog.Error("failed to link telegram chat", "chat_id", chatID, "error", err)
+		b.send(ctx, chatID, "Не удалось привязать аккаунт, попробуйте позже.", removeKeyboard)
+	default:
+		b.send(ctx, chatID, fmt.Sprintf("%s, аккаунт привязан. Сюда будут приходить уведомления.\n\n%s", user.Name, helpText), removeKeyboard)
+	}
+}
+
+func (b *Bot) unlink(ctx context.Context, chatID int64) {
+	delete(b.sessions, chatID)
+
+	user, ok := b.linkedUser(ctx, chatID)
+	if !ok {
+		return
+	}
+	if err := b.services.Telegram.Unlink(ctx, user.ID); err != nil {
+		slog.Error("failed to unlink telegram chat", "chat_id", chatID, "error", err)
+		b.send(ctx, chatID, "Не удалось отвязать аккаунт, попробуйте позже.", removeKeyboard)
+		return
+	}
+	b.send(ctx, chatID, "Аккаунт отвязан, уведомления сюда больше не придут.", removeKeyboard)
+}
+
+// linkedUser returns the account of the chat and explains how to link one
+// when there is none.
+func (b *Bot) linkedUser(ctx context.Context, chatID int64) (*entity.User, bool) {
+	user, err := b.services.Telegram.GetLinkedUser(ctx, chatID)
+	switch {
+	case errors.Is(err, e.ErrNotFound):
+		b.send(ctx, chatID, "Сначала привяжите аккаунт: откройте ссылку для Telegram в профиле на сайте.", removeKeyboard)
+		return nil, false
+	case err != nil:
+		slog.Error("failed to find telegram user", "chat_id", chatID, "error", err)
+		b.send(ctx, chatID, "Что-то пошло не так, попробуйте позже.", removeKeyboard)
+		return nil, false
+	}
+	return user, true
+}
+
+func (b *Bot) sendNearby(ctx context.Context, chatID int64, point entity.Location) {
+	if user, err := b.services.Telegram.GetLinkedUser(ctx, chatID); err == nil {
+		ctx = context.WithValue(ctx, "userID", user.ID)
+	}
+
+	cards, err := b.services.Cards.GetCardsNear(ctx, entity.CardFilter{
+		Area: &entity.CircleArea{Center: point, Radius: nearbyRadius},
+	})
+	if err != nil {
+		slog.Error("failed to find cards near telegram location", "chat_id", chatID, "error", err)
+		b.send(ctx, chatID, "Не удалось найти объявления, попробуйте позже.", removeKeyboard)
+		return
+	}
+	if len(cards) == 0 {
+		b.send(ctx, chatID, "В радиусе километра объявлений нет.", removeKeyboard)
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString("Объявления рядом:\n")
+	for i, card := range cards {
+		if i == nearbyLimit {
+			fmt.Fprintf(&text, "\nи еще %d", len(cards)-nearbyLimit)
+			break
+		}
+		fmt.Fprintf(&text, "\n%d. %s: %s — %.0f м", i+1, statusLabels[card.Status], card.Title, card.DistanceM)
+		if address := strings.Trim(card.City+", "+card.Street, ", "); address != "" {
+			fmt.Fprintf(&text, "\n%s", address)
+		}
+		if card.Owner.Telegram != "" {
+			fmt.Fprintf(&text, "\nКонтакт: %s", card.Owner.Telegram)
+		}
+		text.WriteString("\n")
+	}
+	b.send(ctx, chatID, text.String(), removeKeyboard)
+}
+
+func (b *Bot) send(ctx context.Context, chatID int64, text string, markup any) {
+	if err := b.api.SendMessage(ctx, chatID, text, markup); err != nil {
+		slog.Error("failed to send telegram message", "chat_id", chatID, "error", err)
+	}
+}
+
+// parseCommand splits "/start code" into the command and its argument. The
+// "@botname" suffix used in group chats is dropped.
+func parseCommand(text string) (string, string) {
+	if !strings.HasPrefix(text, "/") {
+		return "", ""
+	}
+	command, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), strings.TrimSpace(arg)
+}
+
+func NewBot(api *tgapi.Client, services *service.Service) *Bot {
+	return &Bot{
+		api:      api,
+		services: services,
+		sessions: make(map[int64]*session),
+	}
+}
@@ -0,0 +1,243 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgapi "LostAndFound/internal/adapters/telegram"
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/config/server_config"
+	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/service"
+)
+
+const (
+	testToken  = "123:test"
+	testChatID = 42
+	testUserID = "user-1"
+	testCode   = "link-code"
+	testPhoto  = "photo-bytes"
+)
+
+// fakeBotAPI is a Bot API server that hands out the queued updates on the
+// first getUpdates call, stops the bot on the next one and records what the
+// bot sends.
+type fakeBotAPI struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	updates []tgapi.Update
+	polled  bool
+	stop    context.CancelFunc
+	sent    []string
+}
+
+func newFakeBotAPI(t *testing.T, messages ...tgapi.Message) *fakeBotAPI {
+	api := &fakeBotAPI{}
+	for i := range messages {
+		messages[i].Chat = tgapi.Chat{ID: testChatID, Type: "private"}
+		api.updates = append(api.updates, tgapi.Update{UpdateID: int64(i + 1), Message: &messages[i]})
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bot"+testToken+"/getUpdates", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		if api.polled {
+			api.stop()
+			writeBotReply(w, []tgapi.Update{})
+			return
+		}
+		api.polled = true
+		writeBotReply(w, api.updates)
+	})
+	mux.HandleFunc("/bot"+testToken+"/sendMessage", func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			ChatID int64  `json:"chat_id"`
+			Text   string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.ChatID != testChatID {
+			t.Errorf("unexpected sendMessage request: %+v, %v", params, err)
+		}
+		api.mu.Lock()
+		api.sent = append(api.sent, params.Text)
+		api.mu.Unlock()
+		writeBotReply(w, map[string]any{"message_id": 1})
+	})
+	mux.HandleFunc("/bot"+testToken+"/getFile", func(w http.ResponseWriter, r *http.Request) {
+		writeBotReply(w, tgapi.File{FileID: "large", FilePath: "photos/large.jpg"})
+	})
+	mux.HandleFunc("/file/bot"+testToken+"/photos/large.jpg", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, testPhoto)
+	})
+
+	api.Server = httptest.NewServer(mux)
+	t.Cleanup(api.Close)
+	return api
+}
+
+func writeBotReply(w http.ResponseWriter, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+// run lets the bot handle the queued updates and returns the replies.
+func (api *fakeBotAPI) run(t *testing.T, services *service.Service) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	api.stop = cancel
+
+	client := tgapi.NewClient(server_config.TelegramConfig{APIURL: api.URL, BotToken: testToken})
+	NewBot(client, services).Run(ctx)
+
+	if !api.polled {
+		t.Fatal("the bot did not poll for updates")
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.sent
+}
+
+type fakeTelegram struct {
+	service.Telegram
+	linkedCode   string
+	linkedChatID int64
+}
+
+func (f *fakeTelegram) Link(_ context.Context, code string, chatID int64) (*entity.User, error) {
+	if code != testCode {
+		return nil, e.ErrInvalidLinkCode
+	}
+	f.linkedCode, f.linkedChatID = code, chatID
+	return &entity.User{ID: testUserID, Name: "Анна"}, nil
+}
+
+func (f *fakeTelegram) GetLinkedUser(_ context.Context, chatID int64) (*entity.User, error) {
+	if chatID != testChatID {
+		return nil, e.ErrNotFound
+	}
+	return &entity.User{ID: testUserID, Name: "Анна"}, nil
+}
+
+type fakeFiles struct {
+	service.Files
+	uploaded   string
+	visibility string
+}
+
+func (f *fakeFiles) UploadFile(_ context.Context, userID, _, visibility string, body io.Reader) (*dto.FileUploadResponse, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	f.uploaded, f.visibility = string(data), visibility
+	return &dto.FileUploadResponse{Key: "users/" + userID + "/photo.jpg", PublicURL: "https://files.test/users/" + userID + "/photo.jpg"}, nil
+}
+
+type fakeCards struct {
+	service.Cards
+	created *entity.Card
+	userID  string
+}
+
+func (f *fakeCards) CreateCard(ctx context.Context, card *entity.Card) ([]*entity.SimilarCard, error) {
+	f.created = card
+	f.userID, _ = ctx.Value("userID").(string)
+	return nil, nil
+}
+
+func TestStartLinksChat(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantReply string
+		wantCode  string
+	}{
+		{name: "valid code", text: "/start " + testCode, wantReply: "аккаунт привязан", wantCode: testCode},
+		{name: "expired code", text: "/start stale", wantReply: "Ссылка недействительна"},
+		{name: "no code", text: "/start", wantReply: "Я помогаю искать потерянные вещи"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			telegram := &fakeTelegram{}
+			api := newFakeBotAPI(t, tgapi.Message{Text: tt.text})
+
+			sent := api.run(t, &service.Service{Telegram: telegram})
+
+			if len(sent) != 1 || !strings.Contains(sent[0], tt.wantReply) {
+				t.Fatalf("replies = %q, want one containing %q", sent, tt.wantReply)
+			}
+			if telegram.linkedCode != tt.wantCode {
+				t.Errorf("linked code = %q, want %q", telegram.linkedCode, tt.wantCode)
+			}
+			if tt.wantCode != "" && telegram.linkedChatID != testChatID {
+				t.Errorf("linked chat = %d, want %d", telegram.linkedChatID, testChatID)
+			}
+		})
+	}
+}
+
+func TestCardDialog(t *testing.T) {
+	telegram := &fakeTelegram{}
+	files := &fakeFiles{}
+	cards := &fakeCards{}
+	api := newFakeBotAPI(t,
+		tgapi.Message{Text: "/new"},
+		tgapi.Message{Text: "Потерял"},
+		tgapi.Message{Text: "Хобби"},
+		tgapi.Message{Text: "Документы"},
+		tgapi.Message{Text: "Паспорт"},
+		tgapi.Message{Text: buttonSkip},
+		tgapi.Message{Location: &tgapi.Location{Latitude: 55.75, Longitude: 37.61}},
+		tgapi.Message{Caption: "обложка", Photo: []tgapi.PhotoSize{{FileID: "small"}, {FileID: "large"}}},
+		tgapi.Message{Text: buttonDone},
+	)
+
+	sent := api.run(t, &service.Service{Telegram: telegram, Files: files, Cards: cards})
+
+	if len(sent) != 9 {
+		t.Fatalf("got %d replies, want 9: %q", len(sent), sent)
+	}
+	if !strings.Contains(sent[2], "Выберите категорию на клавиатуре") {
+		t.Errorf("unknown category reply = %q", sent[2])
+	}
+	if !strings.Contains(sent[8], "«Паспорт» опубликовано") {
+		t.Errorf("last reply = %q", sent[8])
+	}
+
+	if files.uploaded != testPhoto {
+		t.Errorf("uploaded %q, want the largest photo size", files.uploaded)
+	}
+	if files.visibility != "private" {
+		t.Errorf("document photo uploaded as %q, want private", files.visibility)
+	}
+
+	card := cards.created
+	if card == nil {
+		t.Fatal("no card was created")
+	}
+	if cards.userID != testUserID || card.OwnerID != testUserID {
+		t.Errorf("card created for %q/%q, want %q", cards.userID, card.OwnerID, testUserID)
+	}
+	if card.Status != entity.StatusLost || card.Category != entity.CategoryDocuments {
+		t.Errorf("status/category = %q/%q", card.Status, card.Category)
+	}
+	if card.Title != "Паспорт" || card.Description != "" {
+		t.Errorf("title/description = %q/%q", card.Title, card.Description)
+	}
+	if card.Location == nil || *card.Location != (entity.Location{Latitude: 55.75, Longitude: 37.61}) {
+		t.Errorf("location = %+v", card.Location)
+	}
+	if len(card.Images) != 1 || card.Images[0].Caption != "обложка" || !strings.HasSuffix(card.Images[0].URL, "/photo.jpg") {
+		t.Errorf("images = %+v", card.Images)
+	}
+}
@@ -0,0 +1,280 @@
+package telegram
+
+import (
+	tgapi "LostAndFound/internal/adapters/telegram"
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// sessionTTL drops dialogs the user abandoned.
+	sessionTTL = 30 * time.Minute
+	// maxDialogPhotos caps the photos attached through the bot.
+	maxDialogPhotos = 10
+	maxTitleLength  = 200
+)
+
+type step int
+
+const (
+	stepStatus step = iota
+	stepCategory
+	stepTitle
+	stepDescription
+	stepLocation
+	stepPhotos
+)
+
+// session is the state of the guided dialog that creates a card.
+type session struct {
+	userID    string
+	step      step
+	card      entity.Card
+	updatedAt time.Time
+}
+
+const (
+	buttonLost     = "Потерял"
+	buttonFound    = "Нашел"
+	buttonSkip     = "Пропустить"
+	buttonDone     = "Готово"
+	buttonLocation = "Отправить геопозицию"
+)
+
+var statusLabels = map[entity.CardStatus]string{
+	entity.StatusLost:  "Потеряно",
+	entity.StatusFound: "Найдено",
+}
+
+var categoryButtons = []struct {
+	label    string
+	category entity.CardCategory
+}{
+	{"Документы", entity.CategoryDocuments},
+	{"Электроника", entity.CategoryElectronics},
+	{"Одежда", entity.CategoryClothing},
+	{"Аксессуары", entity.CategoryAccessories},
+	{"Ключи", entity.CategoryKeys},
+	{"Сумки", entity.CategoryBags},
+	{"Другое", entity.CategoryOther},
+}
+
+var (
+	removeKeyboard = tgapi.ReplyKeyboardRemove{RemoveKeyboard: true}
+
+	statusKeyboard = keyboard([]string{buttonLost, buttonFound})
+	skipKeyboard   = keyboard([]string{buttonSkip})
+	photosKeyboard = keyboard([]string{buttonDone})
+
+	locationKeyboard = tgapi.ReplyKeyboardMarkup{
+		Keyboard:        [][]tgapi.KeyboardButton{{{Text: buttonLocation, RequestLocation: true}}},
+		ResizeKeyboard:  true,
+		OneTimeKeyboard: true,
+	}
+
+	categoryKeyboard = func() tgapi.ReplyKeyboardMarkup {
+		var rows [][]string
+		for i, b := range categoryButtons {
+			if i%2 == 0 {
+				rows = append(rows, nil)
+			}
+			rows[len(rows)-1] = append(rows[len(rows)-1], b.label)
+		}
+		return keyboard(rows...)
+	}()
+)
+
+func (b *Bot) session(chatID int64) *session {
+	s, ok := b.sessions[chatID]
+	if !ok {
+		return nil
+	}
+	if time.Since(s.updatedAt) > sessionTTL {
+		delete(b.sessions, chatID)
+		return nil
+	}
+	return s
+}
+
+func (b *Bot) startDialog(ctx context.Context, chatID int64) {
+	delete(b.sessions, chatID)
+
+	user, ok := b.linkedUser(ctx, chatID)
+	if !ok {
+		return
+	}
+
+	b.sessions[chatID] = &session{userID: user.ID, step: stepStatus, updatedAt: time.Now()}
+	b.send(ctx, chatID, "Вы потеряли или нашли вещь?", statusKeyboard)
+}
+
+func (b *Bot) continueDialog(ctx context.Context, chatID int64, s *session, msg *tgapi.Message) {
+	s.updatedAt = time.Now()
+	text := strings.TrimSpace(msg.Text)
+
+	switch s.step {
+	case stepStatus:
+		switch text {
+		case buttonLost:
+			s.card.Status = entity.StatusLost
+		case buttonFound:
+			s.card.Status = entity.StatusFound
+		default:
+			b.send(ctx, chatID, "Выберите вариант на клавиатуре.", statusKeyboard)
+			return
+		}
+		s.step = stepCategory
+		b.send(ctx, chatID, "Выберите категорию.", categoryKeyboard)
+
+	case stepCategory:
+		for _, button := range categoryButtons {
+			if button.label == text {
+				s.card.Category = button.category
+			}
+		}
+		if s.card.Category == "" {
+			b.send(ctx, chatID, "Выберите категорию на клавиатуре.", categoryKeyboard)
+			return
+		}
+		s.step = stepTitle
+		b.send(ctx, chatID, "Коротко назовите вещь, например «Черный рюкзак».", removeKeyboard)
+
+	case stepTitle:
+		if text == "" || len([]rune(text)) > maxTitleLength {
+			b.send(ctx, chatID, fmt.Sprintf("Нужен текст до %d символов.", maxTitleLength), nil)
+			return
+		}
+		s.card.Title = text
+		s.step = stepDescription
+		b.send(ctx, chatID, "Опишите вещь и обстоятельства: приметы, где и когда.", skipKeyboard)
+
+	case stepDescription:
+		if text == "" {
+			b.send(ctx, chatID, "Нужен текст описания.", skipKeyboard)
+			return
+		}
+		if text != buttonSkip {
+			s.card.Description = text
+		}
+		s.step = stepLocation
+		b.send(ctx, chatID, "Где это было? Отправьте геопозицию или напишите адрес в виде «Город, улица».", locationKeyboard)
+
+	case stepLocation:
+		switch {
+		case msg.Location != nil:
+			s.card.Location = &entity.Location{Latitude: msg.Location.Latitude, Longitude: msg.Location.Longitude}
+			s.card.City, s.card.Street = "", ""
+		case text != "":
+			city, street, _ := strings.Cut(text, ",")
+			s.card.Location = nil
+			s.card.City, s.card.Street = strings.TrimSpace(city), strings.TrimSpace(street)
+		default:
+			b.send(ctx, chatID, "Отправьте геопозицию или напишите адрес.", locationKeyboard)
+			return
+		}
+		s.step = stepPhotos
+		b.send(ctx, chatID, fmt.Sprintf("Пришлите до %d фото и нажмите «%s», или сразу «%s», если фото нет.", maxDialogPhotos, buttonDone, buttonDone), photosKeyboard)
+
+	case stepPhotos:
+		switch {
+		case len(msg.Photo) > 0:
+			b.addPhoto(ctx, chatID, s, msg)
+		case text == buttonDone:
+			b.createCard(ctx, chatID, s)
+		default:
+			b.send(ctx, chatID, fmt.Sprintf("Пришлите фото или нажмите «%s».", buttonDone), photosKeyboard)
+		}
+	}
+}
+
+// addPhoto copies the largest size of the photo into the file storage.
+// Photos of documents are stored privately, like on the site.
+func (b *Bot) addPhoto(ctx context.Context, chatID int64, s *session, msg *tgapi.Message) {
+	if len(s.card.Images) >= maxDialogPhotos {
+		b.send(ctx, chatID, fmt.Sprintf("Можно приложить не больше %d фото. Нажмите «%s».", maxDialogPhotos, buttonDone), photosKeyboard)
+		return
+	}
+
+	photo := msg.Photo[len(msg.Photo)-1]
+	if photo.FileSize > service.MaxUploadSize {
+		b.send(ctx, chatID, "Фото слишком большое.", photosKeyboard)
+		return
+	}
+
+	file, err := b.api.GetFile(ctx, photo.FileID)
+	if err != nil {
+		slog.Error("failed to get telegram file", "chat_id", chatID, "error", err)
+		b.send(ctx, chatID, "Не удалось получить фото, пришлите его еще раз.", photosKeyboard)
+		return
+	}
+	body, err := b.api.DownloadFile(ctx, file)
+	if err != nil {
+		slog.Error("failed to download telegram file", "chat_id", chatID, "error", err)
+		b.send(ctx, chatID, "Не удалось получить фото, пришлите его еще раз.", photosKeyboard)
+		return
+	}
+	defer body.Close()
+
+	visibility := "public"
+	if s.card.Category == entity.CategoryDocuments {
+		visibility = "private"
+	}
+	uploaded, err := b.services.Files.UploadFile(ctx, s.userID, "telegram.jpg", visibility, body)
+	if err != nil {
+		slog.Error("failed to upload telegram photo", "chat_id", chatID, "error", err)
+		b.send(ctx, chatID, "Не удалось сохранить фото, пришлите его еще раз.", photosKeyboard)
+		return
+	}
+
+	ref := uploaded.PublicURL
+	if ref == "" {
+		ref = uploaded.Key
+	}
+	s.card.Images = append(s.card.Images, entity.CardImage{URL: ref, Caption: strings.TrimSpace(msg.Caption)})
+	b.send(ctx, chatID, fmt.Sprintf("Фото %d добавлено.", len(s.card.Images)), photosKeyboard)
+}
+
+func (b *Bot) createCard(ctx context.Context, chatID int64, s *session) {
+	card := s.card
+	card.Images = append([]entity.CardImage(nil), s.card.Images...)
+	card.OwnerID = s.userID
+	card.CreatedAt = time.Now()
+
+	duplicates, err := b.services.Cards.CreateCard(context.WithValue(ctx, "userID", s.userID), &card)
+	switch {
+	case errors.Is(err, e.ErrAddressNotFound), errors.Is(err, e.ErrInvalidLocation):
+		s.step = stepLocation
+		b.send(ctx, chatID, "Не удалось определить место. Отправьте геопозицию или напишите адрес в виде «Город, улица».", locationKeyboard)
+		return
+	case err != nil:
+		slog.Error("failed to create card from telegram", "chat_id", chatID, "error", err)
+		b.send(ctx, chatID, "Не удалось опубликовать объявление, попробуйте позже.", photosKeyboard)
+		return
+	}
+
+	delete(b.sessions, chatID)
+	text := fmt.Sprintf("Объявление «%s» опубликовано.", card.Title)
+	if len(duplicates) > 0 {
+		text += fmt.Sprintf("\n\nТакие же фото уже есть в других объявлениях (%d). Возможно, вещь уже ищут или нашли.", len(duplicates))
+	}
+	b.send(ctx, chatID, text, removeKeyboard)
+}
+
+func keyboard(rows ...[]string) tgapi.ReplyKeyboardMarkup {
+	markup := tgapi.ReplyKeyboardMarkup{ResizeKeyboard: true, OneTimeKeyboard: true}
+	for _, row := range rows {
+		buttons := make([]tgapi.KeyboardButton, 0, len(row))
+		for _, text := range row {
+			buttons = append(buttons, tgapi.KeyboardButton{Text: text})
+		}
+		markup.Keyboard = append(markup.Keyboard, buttons)
+	}
+	return markup
+}
@@ -0,0 +1,20 @@
+package entity
+
+// Address is a gazetteer entry: a city, an optional street with a house
+// number and the point they are located at.
+type Address struct {
+	City        string
+	Street      string
+	HouseNumber string
+	Latitude    float64
+	Longitude   float64
+}
+
+// StreetLine joins the street and the house number the way they are shown on
+// cards, e.g. "ул. Ленина, 5".
+func (a *Address) StreetLine() string {
+	if a.Street == "" || a.HouseNumber == "" {
+		return a.Street
+	}
+	return a.Street + ", " + a.HouseNumber
+}
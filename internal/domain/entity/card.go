@@ -11,28 +11,68 @@ const (
 	StatusFound CardStatus = "found"
 )
 
+type CardCategory string
+
+const (
+	CategoryDocuments   CardCategory = "documents"
+	CategoryElectronics CardCategory = "electronics"
+	CategoryClothing    CardCategory = "clothing"
+	CategoryAccessories CardCategory = "accessories"
+	CategoryKeys        CardCategory = "keys"
+	CategoryBags        CardCategory = "bags"
+	CategoryOther       CardCategory = "other"
+)
+
 type Owner struct {
-	ID       string
-	Name     string
-	Surname  string
-	Phone    string
-	Telegram string
+	ID        string
+	Name      string
+	Surname   string
+	Phone     string
+	Telegram  string
+	AvatarURL string
 }
 
 type Card struct {
 	ID          string
 	Title       string
 	Description string
-	Latitude    float64
-	Longitude   float64
+	Location    *Location
 	City        string
 	Street      string
 	PreviewURL  string
-	Images      []string
+	Images      []CardImage
 	Status      CardStatus
+	Category    CardCategory
 	OwnerID     string
+	PlaceID     string
+	PlaceName   string
 	CreatedAt   time.Time
+	// DeletedAt is set while the card is in the trash.
+	DeletedAt *time.Time
+	// Version grows with every change of the card.
+	Version int
 
 	Owner     Owner
 	DistanceM float64
 }
+
+// CardImage is a photo attached to a card. Images are shown in Position
+// order and exactly one of them is the cover, which becomes the card preview.
+type CardImage struct {
+	ID       string
+	URL      string
+	Position int
+	Caption  string
+	Width    int
+	Height   int
+	IsCover  bool
+	Hash     *uint64
+}
+
+// SimilarCard is a card whose image is visually close to an image of another
+// card. Distance is the Hamming distance between the two perceptual hashes.
+type SimilarCard struct {
+	Card     *Card
+	ImageURL string
+	Distance int
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+type CardEventType string
+
+const (
+	CardCreated  CardEventType = "card.created"
+	CardUpdated  CardEventType = "card.updated"
+	CardDeleted  CardEventType = "card.deleted"
+	CardRestored CardEventType = "card.restored"
+)
+
+// CardEvent describes a change of a card; Card is its state after the
+// change, or the last state of a deleted card.
+type CardEvent struct {
+	Type       CardEventType
+	Card       Card
+	OccurredAt time.Time
+}
+
+// CardEventFilter narrows a card feed. Empty fields match every card, and a
+// bounding box never matches cards without a location.
+type CardEventFilter struct {
+	Status   CardStatus
+	Category CardCategory
+	BBox     *BoundingBox
+}
+
+func (f CardEventFilter) Match(card *Card) bool {
+	if f.Status != "" && card.Status != f.Status {
+		return false
+	}
+	if f.Category != "" && card.Category != f.Category {
+		return false
+	}
+	if f.BBox != nil && (card.Location == nil || !f.BBox.Contains(*card.Location)) {
+		return false
+	}
+	return true
+}
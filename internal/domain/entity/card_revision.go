@@ -0,0 +1,72 @@
+package entity
+
+import "time"
+
+// CardField names an editable field of a card in its history.
+type CardField string
+
+const (
+	CardFieldTitle       CardField = "title"
+	CardFieldDescription CardField = "description"
+	CardFieldCity        CardField = "city"
+	CardFieldStreet      CardField = "street"
+	CardFieldStatus      CardField = "status"
+	CardFieldCategory    CardField = "category"
+	CardFieldLocation    CardField = "location"
+	CardFieldImages      CardField = "images"
+)
+
+// CardSnapshot holds the editable fields of a card at some point of its
+// history.
+type CardSnapshot struct {
+	Title       string
+	Description string
+	City        string
+	Street      string
+	Status      CardStatus
+	Category    CardCategory
+	Location    *Location
+	Images      []CardImage
+}
+
+func SnapshotOf(card *Card) CardSnapshot {
+	return CardSnapshot{
+		Title:       card.Title,
+		Description: card.Description,
+		City:        card.City,
+		Street:      card.Street,
+		Status:      card.Status,
+		Category:    card.Category,
+		Location:    card.Location,
+		Images:      card.Images,
+	}
+}
+
+// ApplyTo sets the fields of the card to the snapshot.
+func (s CardSnapshot) ApplyTo(card *Card) {
+	card.Title = s.Title
+	card.Description = s.Description
+	card.City = s.City
+	card.Street = s.Street
+	card.Status = s.Status
+	card.Category = s.Category
+	card.Location = s.Location
+	card.Images = s.Images
+}
+
+// CardRevision is a stored update of a card. Fields lists what changed, and
+// Before and After are the card around the change. Numbers start at 1 for
+// each card.
+type CardRevision struct {
+	ID       string
+	CardID   string
+	Number   int
+	AuthorID string
+	Fields   []CardField
+	Before   CardSnapshot
+	After    CardSnapshot
+	// RevertedTo is set when the update brought back the state of an
+	// earlier revision.
+	RevertedTo *int
+	CreatedAt  time.Time
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+type EventType string
+
+const (
+	EventCardCreated EventType = "card.created"
+	EventCardUpdated EventType = "card.updated"
+	EventCardDeleted EventType = "card.deleted"
+	// EventCardRestored takes a card out of the trash.
+	EventCardRestored EventType = "card.restored"
+	// EventCardPurged removes a card from the trash for good. Only its files
+	// are cleaned up; for everyone else the card is gone since card.deleted.
+	EventCardPurged EventType = "card.purged"
+	// EventCardImagesChanged covers reordering, captions and removal of
+	// single images, which are not announced to webhooks and the live feed.
+	EventCardImagesChanged EventType = "card.images_changed"
+	EventUserUpdated       EventType = "user.updated"
+)
+
+// DomainEvent is a change recorded in the outbox in the same transaction as
+// the change itself and handed to the consumers afterwards, at least once.
+type DomainEvent struct {
+	ID          string
+	Type        EventType
+	AggregateID string
+	OccurredAt  time.Time
+
+	// Card is the card after the change, or its last state when deleted.
+	Card *Card
+	// PreviousCard is the card before an update.
+	PreviousCard *Card
+	// DeletedFiles lists the storage keys that are no longer referenced and
+	// have to be removed.
+	DeletedFiles []string
+
+	Attempts  int
+	LastError string
+}
@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+// StoredFile is an object in the file storage.
+type StoredFile struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// CardFilter narrows down card listings. Empty fields are ignored.
+type CardFilter struct {
+	Status   CardStatus
+	Category CardCategory
+	// PlaceID matches cards in the place and in all places nested in it.
+	PlaceID string
+	// Text matches cards whose title or description contains it.
+	Text string
+	// Area matches cards within the circle.
+	Area *CircleArea
+	// CreatedFrom and CreatedTo bound the creation time; From is inclusive
+	// and To is exclusive.
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+
+	// OwnerID matches the cards of one user.
+	OwnerID string
+	// Deleted lists the cards in the trash instead of the live ones;
+	// DeletedBefore and DeletedAfter then keep those deleted before or
+	// after them.
+	Deleted       bool
+	DeletedBefore time.Time
+	DeletedAfter  time.Time
+}
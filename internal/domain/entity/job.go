@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	// JobDead marks a job that failed every attempt. It stays in the queue
+	// for inspection until it is requeued or deleted by hand.
+	JobDead JobStatus = "dead"
+)
+
+// Job is a unit of background work. Key, when set, makes the job unique, so
+// the same work is not queued twice; keys are freed when finished jobs are
+// cleaned up.
+type Job struct {
+	ID          string
+	Kind        string
+	Key         string
+	Payload     []byte
+	Status      JobStatus
+	Attempts    int
+	MaxAttempts int
+	LastError   string
+	RunAt       time.Time
+	CreatedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+type JobFilter struct {
+	Kind   string
+	Status JobStatus
+	Limit  int
+}
+
+// JobCount is the number of jobs of a kind in a status.
+type JobCount struct {
+	Kind   string
+	Status JobStatus
+	Count  int
+}
@@ -0,0 +1,35 @@
+package entity
+
+// Location is a WGS 84 point. Cards without a known point have a nil
+// *Location; the zero value is a valid point on the equator.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Valid reports whether the coordinates are within the WGS 84 ranges.
+func (l Location) Valid() bool {
+	return l.Latitude >= -90 && l.Latitude <= 90 && l.Longitude >= -180 && l.Longitude <= 180
+}
+
+// Distance is a length in meters.
+type Distance float64
+
+const (
+	Meter     Distance = 1
+	Kilometer Distance = 1000
+)
+
+func (d Distance) Meters() float64 {
+	return float64(d)
+}
+
+func (d Distance) Kilometers() float64 {
+	return float64(d / Kilometer)
+}
+
+// CircleArea is a circle of Radius around Center.
+type CircleArea struct {
+	Center Location
+	Radius Distance
+}
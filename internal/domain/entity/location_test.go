@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLocationValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		location Location
+		want     bool
+	}{
+		{"origin", Location{Latitude: 0, Longitude: 0}, true},
+		{"north pole", Location{Latitude: 90, Longitude: 0}, true},
+		{"south pole", Location{Latitude: -90, Longitude: 0}, true},
+		{"antimeridian east", Location{Latitude: 0, Longitude: 180}, true},
+		{"antimeridian west", Location{Latitude: 0, Longitude: -180}, true},
+		{"corner", Location{Latitude: -90, Longitude: 180}, true},
+		{"latitude above range", Location{Latitude: 90.000001, Longitude: 0}, false},
+		{"latitude below range", Location{Latitude: -90.000001, Longitude: 0}, false},
+		{"longitude above range", Location{Latitude: 0, Longitude: 180.000001}, false},
+		{"longitude below range", Location{Latitude: 0, Longitude: -180.000001}, false},
+		{"latitude NaN", Location{Latitude: math.NaN(), Longitude: 0}, false},
+		{"longitude NaN", Location{Latitude: 0, Longitude: math.NaN()}, false},
+		{"latitude infinite", Location{Latitude: math.Inf(1), Longitude: 0}, false},
+		{"longitude infinite", Location{Latitude: 0, Longitude: math.Inf(-1)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.location.Valid(); got != tt.want {
+				t.Errorf("Valid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
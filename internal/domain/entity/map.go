@@ -0,0 +1,66 @@
+package entity
+
+import "math"
+
+// BoundingBox is a map viewport in WGS 84 degrees.
+type BoundingBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// MapCluster groups the cards that fall into one grid cell of the map. When
+// the cluster holds a single card its ID is set, so clients can render it as
+// a regular marker.
+type MapCluster struct {
+	Latitude     float64
+	Longitude    float64
+	Count        int
+	StatusCounts map[CardStatus]int
+	CardID       string
+}
+
+// MapView is what the map shows for a viewport: either individual cards when
+// zoomed in, or clusters when zoomed out.
+type MapView struct {
+	Clustered bool
+	Cards     []*Card
+	Clusters  []MapCluster
+}
+
+// Tile addresses a web map tile in the XYZ scheme.
+type Tile struct {
+	Z int
+	X int
+	Y int
+}
+
+// MaxTileZoom is the deepest zoom level tiles are served for.
+const MaxTileZoom = 22
+
+// TileForPoint returns the tile containing the point at zoom z.
+func TileForPoint(lat, lon float64, z int) Tile {
+	n := math.Exp2(float64(z))
+	x := int(math.Floor((lon + 180) / 360 * n))
+	latRad := lat * math.Pi / 180
+	y := int(math.Floor((1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * n))
+
+	limit := int(n) - 1
+	return Tile{Z: z, X: min(max(x, 0), limit), Y: min(max(y, 0), limit)}
+}
+
+// Valid reports whether the tile coordinates exist at its zoom level.
+func (t Tile) Valid() bool {
+	if t.Z < 0 || t.Z > MaxTileZoom {
+		return false
+	}
+	n := 1 << t.Z
+	return t.X >= 0 && t.X < n && t.Y >= 0 && t.Y < n
+}
+
+// Contains reports whether the point lies inside the box.
+func (b BoundingBox) Contains(p Location) bool {
+	return p.Longitude >= b.MinLon && p.Longitude <= b.MaxLon &&
+		p.Latitude >= b.MinLat && p.Latitude <= b.MaxLat
+}
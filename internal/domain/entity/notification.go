@@ -0,0 +1,112 @@
+package entity
+
+import "time"
+
+type NotificationKind string
+
+const (
+	NotificationWatchAreaMatch    NotificationKind = "watch_area_match"
+	NotificationSavedSearchDigest NotificationKind = "saved_search_digest"
+	NotificationDuplicateImages   NotificationKind = "duplicate_images"
+)
+
+// NotificationChannel is an external way of delivering a notification in
+// addition to the in-app inbox.
+type NotificationChannel string
+
+const (
+	ChannelEmail    NotificationChannel = "email"
+	ChannelTelegram NotificationChannel = "telegram"
+	ChannelWebPush  NotificationChannel = "webpush"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending"
+	DeliverySent    DeliveryStatus = "sent"
+	DeliveryFailed  DeliveryStatus = "failed"
+)
+
+type Notification struct {
+	ID         string
+	UserID     string
+	Kind       NotificationKind
+	Title      string
+	Body       string
+	CardID     string
+	CreatedAt  time.Time
+	ReadAt     *time.Time
+	Deliveries []NotificationDelivery
+}
+
+// NotificationDelivery tracks sending a notification over one channel.
+type NotificationDelivery struct {
+	NotificationID string
+	Channel        NotificationChannel
+	Status         DeliveryStatus
+	Attempts       int
+	LastError      string
+	NextAttemptAt  time.Time
+	SentAt         *time.Time
+}
+
+type NotificationPreferences struct {
+	UserID     string
+	Channels   []NotificationChannel
+	QuietHours *QuietHours
+}
+
+// QuietHours is a daily period during which external deliveries are held
+// back. Start and End are minutes since midnight in TimeZone; a period with
+// Start after End spans midnight.
+type QuietHours struct {
+	Start    int
+	End      int
+	TimeZone string
+}
+
+// Until returns the end of the quiet period t falls into, or the zero time
+// when t is outside of quiet hours.
+func (q QuietHours) Until(t time.Time) time.Time {
+	if q.Start == q.End {
+		return time.Time{}
+	}
+	loc, err := time.LoadLocation(q.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	y, m, d := local.Date()
+
+	switch {
+	case q.Start < q.End && minute >= q.Start && minute < q.End:
+		return time.Date(y, m, d, 0, q.End, 0, 0, loc)
+	case q.Start > q.End && minute >= q.Start:
+		return time.Date(y, m, d+1, 0, q.End, 0, 0, loc)
+	case q.Start > q.End && minute < q.End:
+		return time.Date(y, m, d, 0, q.End, 0, 0, loc)
+	}
+	return time.Time{}
+}
+
+// PushSubscription is a browser endpoint registered through the Push API.
+type PushSubscription struct {
+	ID        string
+	UserID    string
+	Endpoint  string
+	P256dh    string
+	Auth      string
+	CreatedAt time.Time
+}
+
+// Recipient holds what the delivery channels need to reach a user.
+type Recipient struct {
+	UserID            string
+	Email             string
+	TelegramChatID    int64
+	PushSubscriptions []PushSubscription
+	QuietHours        *QuietHours
+}
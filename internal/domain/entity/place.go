@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+type PlaceKind string
+
+const (
+	PlaceCampus   PlaceKind = "campus"
+	PlaceBuilding PlaceKind = "building"
+	PlaceZone     PlaceKind = "zone"
+)
+
+// Place is a named area on the map such as a campus, a building or a zone
+// inside it. Geometry holds a GeoJSON Polygon or MultiPolygon.
+type Place struct {
+	ID        string
+	ParentID  string
+	Kind      PlaceKind
+	Name      string
+	Geometry  []byte
+	CreatedAt time.Time
+
+	Children []*Place
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type DigestFrequency string
+
+const (
+	DigestInstant DigestFrequency = "instant"
+	DigestDaily   DigestFrequency = "daily"
+)
+
+// SavedSearch is a card listing query a user wants to follow. Cards created
+// after LastRunAt that match Filter are sent as a digest with the given
+// frequency.
+type SavedSearch struct {
+	ID        string
+	UserID    string
+	Name      string
+	Filter    CardFilter
+	Frequency DigestFrequency
+	LastRunAt time.Time
+	CreatedAt time.Time
+}
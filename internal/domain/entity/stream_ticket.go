@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// StreamTicket authorizes opening one event stream. Browsers cannot set
+// headers on an EventSource, so the ticket goes in the URL in place of the
+// access token; it is short-lived and can be used once.
+type StreamTicket struct {
+	Ticket    string    `json:"-"`
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// TelegramLink is a one-time code that links a Telegram chat to the account
+// it was issued for once the user opens URL and starts the bot.
+type TelegramLink struct {
+	Code      string
+	URL       string
+	ExpiresAt time.Time
+}
@@ -13,5 +13,14 @@ type User struct {
 	Phone     string
 	Telegram  string
 	IsAdmin   bool
+
+	AvatarURL      string
+	AvatarThumbURL string
+
+	// BannedAt is set while the user is banned.
+	BannedAt *time.Time
+	// Version grows with every update of the profile.
+	Version int
+
 	CreatedAt time.Time
 }
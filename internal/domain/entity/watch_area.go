@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// WatchArea is an area a user wants to hear about: either a circle around
+// Center or a place. New cards inside it that match the optional category
+// and status produce a notification.
+type WatchArea struct {
+	ID        string
+	UserID    string
+	Name      string
+	Center    *Location
+	Radius    Distance
+	PlaceID   string
+	PlaceName string
+	Category  CardCategory
+	Status    CardStatus
+	CreatedAt time.Time
+}
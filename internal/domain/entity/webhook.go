@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// Webhook is an external endpoint subscribed to card events. An empty Events
+// list subscribes it to every event type.
+type Webhook struct {
+	ID          string
+	URL         string
+	Secret      string
+	Events      []CardEventType
+	Description string
+	Active      bool
+	CreatedAt   time.Time
+}
+
+func (w *Webhook) Subscribed(eventType CardEventType) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, eventType)
+}
+
+// WebhookDelivery is one event sent to one webhook. EventID is shared by the
+// deliveries of the same event and by their replays, so receivers can drop
+// duplicates.
+type WebhookDelivery struct {
+	ID             string
+	WebhookID      string
+	EventID        string
+	EventType      CardEventType
+	Payload        []byte
+	Status         DeliveryStatus
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	NextAttemptAt  time.Time
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
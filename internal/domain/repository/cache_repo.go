@@ -14,8 +14,27 @@ type CacheRepo interface {
 
 	BlacklistToken(ctx context.Context, token string, ttl time.Duration) error
 	IsTokenBlacklisted(ctx context.Context, token string) (bool, error)
+	// BlockUser rejects the tokens already issued to the user for ttl, which
+	// has to cover their lifetime.
+	BlockUser(ctx context.Context, userID string, ttl time.Duration) error
+	UnblockUser(ctx context.Context, userID string) error
+	IsUserBlocked(ctx context.Context, userID string) (bool, error)
 
 	SaveCard(ctx context.Context, card *entity.Card) error
 	GetCardByID(ctx context.Context, id string) (*entity.Card, error)
 	DeleteCard(ctx context.Context, id string) error
+
+	SaveTile(ctx context.Context, tile entity.Tile, data []byte) error
+	GetTile(ctx context.Context, tile entity.Tile) ([]byte, bool, error)
+	DeleteTiles(ctx context.Context, tiles []entity.Tile) error
+
+	SaveTelegramLinkCode(ctx context.Context, code, userID string, ttl time.Duration) error
+	// TakeTelegramLinkCode returns the user the code was issued to and
+	// invalidates it; the result is empty for unknown or expired codes.
+	TakeTelegramLinkCode(ctx context.Context, code string) (string, error)
+
+	SaveStreamTicket(ctx context.Context, ticket *entity.StreamTicket, ttl time.Duration) error
+	// TakeStreamTicket returns the ticket and invalidates it; the result is
+	// nil for unknown or expired tickets.
+	TakeStreamTicket(ctx context.Context, ticket string) (*entity.StreamTicket, error)
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+// CardEventBus broadcasts card events to every server instance.
+type CardEventBus interface {
+	Publish(ctx context.Context, event *entity.CardEvent) error
+	// Subscribe delivers the events published from now on until ctx is done
+	// or the connection is lost, at which point the channel is closed.
+	Subscribe(ctx context.Context) (<-chan *entity.CardEvent, error)
+}
@@ -2,15 +2,38 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"LostAndFound/internal/domain/entity"
 )
 
+// CardRepo writes the given domain events to the outbox in the transaction
+// of the change.
 type CardRepo interface {
-	Create(ctx context.Context, l *entity.Card) error
+	Create(ctx context.Context, l *entity.Card, events ...*entity.DomainEvent) error
 	GetByID(ctx context.Context, id string) (*entity.Card, error)
-	FindAll(ctx context.Context, filter string) ([]*entity.Card, error)
-	Update(ctx context.Context, l *entity.Card) error
-	Delete(ctx context.Context, id string) error
-	FindNearLocation(ctx context.Context, lat, lon, radius float64, status string) ([]*entity.Card, error)
+	FindAll(ctx context.Context, filter entity.CardFilter) ([]*entity.Card, error)
+	// Update stores the revision of the card along with the change and
+	// replaces the images when the revision lists them. Update and Delete
+	// only apply to the card version the caller has read and
+	// give sql.ErrNoRows for any other.
+	Update(ctx context.Context, l *entity.Card, revision *entity.CardRevision, events ...*entity.DomainEvent) error
+	// Delete moves the card to the trash; Restore and Purge only act on
+	// cards deleted after and before the given time.
+	Delete(ctx context.Context, id string, version int, events ...*entity.DomainEvent) error
+	Restore(ctx context.Context, id string, deletedAfter time.Time, events ...*entity.DomainEvent) error
+	Purge(ctx context.Context, id string, deletedBefore time.Time, events ...*entity.DomainEvent) error
+	FindNearLocation(ctx context.Context, filter entity.CardFilter) ([]*entity.Card, error)
+	ReorderImages(ctx context.Context, cardID string, imageIDs []string, events ...*entity.DomainEvent) error
+	UpdateImage(ctx context.Context, cardID string, img entity.CardImage, events ...*entity.DomainEvent) error
+	DeleteImage(ctx context.Context, cardID, imageID string, events ...*entity.DomainEvent) error
+	// UpdateImageDetails stores the hash and size of an image. They are
+	// derived from the file, so the card keeps its version.
+	UpdateImageDetails(ctx context.Context, img entity.CardImage) error
+	FindInBounds(ctx context.Context, bbox entity.BoundingBox, filter entity.CardFilter, limit int) ([]*entity.Card, error)
+	// ClusterInBounds returns at most limit clusters, the largest first.
+	ClusterInBounds(ctx context.Context, bbox entity.BoundingBox, filter entity.CardFilter, cellSize float64, limit int) ([]entity.MapCluster, error)
+	RenderTile(ctx context.Context, tile entity.Tile) ([]byte, error)
+	FindSimilarImages(ctx context.Context, cardID string, maxDistance int) ([]*entity.SimilarCard, error)
+	FindRevisions(ctx context.Context, cardID string) ([]*entity.CardRevision, error)
 }
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+// EventStream is an append-only log of domain events that other services can
+// read with consumer groups.
+type EventStream interface {
+	Append(ctx context.Context, event *entity.DomainEvent) error
+}
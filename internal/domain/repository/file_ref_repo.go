@@ -0,0 +1,10 @@
+package repository
+
+import "context"
+
+// FileRefRepo lists the file references stored in the database: card
+// images, previews, the images kept in card history and avatars, as URLs or
+// storage keys.
+type FileRefRepo interface {
+	FindAll(ctx context.Context) ([]string, error)
+}
@@ -2,13 +2,21 @@ package repository
 
 import (
 	"context"
+	"io"
 	"time"
+
+	"LostAndFound/internal/domain/entity"
 )
 
 type FileStorage interface {
 	GeneratePresignedPutURL(key, contentType string, expires time.Duration) (string, error)
+	GeneratePresignedGetURL(key string, expires time.Duration) (string, error)
+	GetFile(ctx context.Context, key string) (io.ReadCloser, error)
+	Put(ctx context.Context, key, contentType string, body io.Reader) error
 	DeleteFile(ctx context.Context, key string) error
 	FileExists(ctx context.Context, key string) (bool, error)
+	// ListFiles returns every stored object whose key starts with prefix.
+	ListFiles(ctx context.Context, prefix string) ([]entity.StoredFile, error)
 	GetBaseURL() string
 	GetBucket() string
 }
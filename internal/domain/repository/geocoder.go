@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+// Geocoder converts between points and addresses without calling external
+// services.
+type Geocoder interface {
+	// Reverse returns the address nearest to the point within radius, or nil
+	// if there is none.
+	Reverse(ctx context.Context, point entity.Location, radius entity.Distance) (*entity.Address, error)
+	// Forward resolves a city and an optional street line to a point, or
+	// returns nil if the address is unknown.
+	Forward(ctx context.Context, city, street string) (*entity.Address, error)
+	// Import replaces the gazetteer with the given addresses.
+	Import(ctx context.Context, addresses []entity.Address) error
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+type JobRepo interface {
+	// Enqueue stores a pending job. It reports false when a job with the
+	// same key has already been queued.
+	Enqueue(ctx context.Context, job *entity.Job) (bool, error)
+	// Claim marks up to limit due jobs of the given kinds as running until
+	// leaseUntil and counts the attempt. Running jobs whose lease expired,
+	// because their worker died, are claimed again.
+	Claim(ctx context.Context, kinds []string, now, leaseUntil time.Time, limit int) ([]*entity.Job, error)
+	Complete(ctx context.Context, id string) error
+	// Retry puts the job back to pending until runAt.
+	Retry(ctx context.Context, job *entity.Job, runAt time.Time) error
+	// Bury moves the job to the dead-letter state.
+	Bury(ctx context.Context, job *entity.Job) error
+	// DeleteFinished removes the jobs done before the given time.
+	DeleteFinished(ctx context.Context, before time.Time) (int64, error)
+
+	// FindAll returns the matching jobs, most recently queued first.
+	FindAll(ctx context.Context, filter entity.JobFilter) ([]*entity.Job, error)
+	Count(ctx context.Context) ([]entity.JobCount, error)
+	// Requeue puts a dead job back to pending with fresh attempts.
+	Requeue(ctx context.Context, id string) error
+}
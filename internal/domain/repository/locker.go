@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Locker takes short-lived locks shared by all instances.
+type Locker interface {
+	// TryLock takes the lock unless another holder has it and keeps it for
+	// ttl; the lock is not released earlier.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+type NotificationRepo interface {
+	// Create stores the notifications together with their pending deliveries.
+	Create(ctx context.Context, notifications ...*entity.Notification) error
+	FindByUser(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*entity.Notification, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID, id string) error
+	MarkAllRead(ctx context.Context, userID string) error
+
+	// FindPreferences returns the stored preferences of the given users;
+	// users who never changed them are left out.
+	FindPreferences(ctx context.Context, userIDs ...string) ([]*entity.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, prefs *entity.NotificationPreferences) error
+
+	// SavePushSubscription registers the endpoint for the user, replacing a
+	// previous registration of the same endpoint.
+	SavePushSubscription(ctx context.Context, sub *entity.PushSubscription) error
+	DeletePushSubscription(ctx context.Context, userID, id string) error
+
+	// ClaimDeliveries locks up to limit pending deliveries due at now by
+	// moving their next attempt to leaseUntil, so that other instances skip
+	// them. Each returned notification holds the one claimed delivery.
+	ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.Notification, error)
+	UpdateDelivery(ctx context.Context, delivery *entity.NotificationDelivery) error
+	GetRecipient(ctx context.Context, userID string) (*entity.Recipient, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+// NotificationSender delivers notifications over one external channel.
+// Errors wrapping app_errors.ErrRecipientUnreachable are not retried.
+type NotificationSender interface {
+	Channel() entity.NotificationChannel
+	Send(ctx context.Context, recipient *entity.Recipient, n *entity.Notification) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+// OutboxRepo reads the events that repositories write to the outbox together
+// with the changes they describe.
+type OutboxRepo interface {
+	// ClaimEvents returns up to limit due events, oldest first, and postpones
+	// them until leaseUntil so that other instances skip them meanwhile.
+	ClaimEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.DomainEvent, error)
+	// Complete removes the event once every consumer has handled it.
+	Complete(ctx context.Context, id string) error
+	// Retry records a failed attempt and schedules the next one.
+	Retry(ctx context.Context, event *entity.DomainEvent, nextAttemptAt time.Time) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+type PlaceRepo interface {
+	Create(ctx context.Context, places ...*entity.Place) error
+	GetByID(ctx context.Context, id string) (*entity.Place, error)
+	FindAll(ctx context.Context, withGeometry bool) ([]*entity.Place, error)
+	Update(ctx context.Context, p *entity.Place) error
+	Delete(ctx context.Context, id string) error
+}
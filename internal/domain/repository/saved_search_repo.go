@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+type SavedSearchRepo interface {
+	Create(ctx context.Context, search *entity.SavedSearch) error
+	FindByUser(ctx context.Context, userID string) ([]*entity.SavedSearch, error)
+	CountByUser(ctx context.Context, userID string) (int, error)
+	Update(ctx context.Context, search *entity.SavedSearch) error
+	Delete(ctx context.Context, userID, id string) error
+	// FindDue returns up to limit searches with the given frequency that were
+	// last run before the given time, oldest first.
+	FindDue(ctx context.Context, frequency entity.DigestFrequency, before time.Time, limit int) ([]*entity.SavedSearch, error)
+	MarkRun(ctx context.Context, id string, runAt time.Time) error
+}
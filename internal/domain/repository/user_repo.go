@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"LostAndFound/internal/domain/entity"
 )
@@ -10,6 +11,17 @@ type UserRepo interface {
 	FindByEmail(ctx context.Context, email string) (*entity.User, error)
 	FindByID(ctx context.Context, id string) (*entity.User, error)
 	Create(ctx context.Context, u *entity.User) error
-	Update(ctx context.Context, u *entity.User) error
+	// Update only applies to the user version the caller has read and gives
+	// sql.ErrNoRows for any other.
+	Update(ctx context.Context, u *entity.User, events ...*entity.DomainEvent) error
 	Delete(ctx context.Context, id string) error
+	FindAll(ctx context.Context) ([]*entity.User, error)
+	SetAdmin(ctx context.Context, id string, isAdmin bool) error
+	// SetBanned bans the user at bannedAt; nil lifts the ban.
+	SetBanned(ctx context.Context, id string, bannedAt *time.Time) error
+
+	FindByTelegramChatID(ctx context.Context, chatID int64) (*entity.User, error)
+	// SetTelegramChatID links the chat to the user, detaching it from any
+	// other account; a zero chatID unlinks the user.
+	SetTelegramChatID(ctx context.Context, userID string, chatID int64) error
 }
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+type WatchAreaRepo interface {
+	Create(ctx context.Context, area *entity.WatchArea) error
+	FindByUser(ctx context.Context, userID string) ([]*entity.WatchArea, error)
+	CountByUser(ctx context.Context, userID string) (int, error)
+	Update(ctx context.Context, area *entity.WatchArea) error
+	Delete(ctx context.Context, userID, id string) error
+	// FindMatching returns the watch areas of other users that contain the
+	// card's point and match its category and status, at most one per user.
+	FindMatching(ctx context.Context, card *entity.Card) ([]*entity.WatchArea, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"LostAndFound/internal/domain/entity"
+)
+
+type WebhookRepo interface {
+	Create(ctx context.Context, webhook *entity.Webhook) error
+	FindAll(ctx context.Context) ([]*entity.Webhook, error)
+	GetByID(ctx context.Context, id string) (*entity.Webhook, error)
+	Update(ctx context.Context, webhook *entity.Webhook) error
+	Delete(ctx context.Context, id string) error
+
+	// Enqueue creates a pending delivery of the event for every active
+	// webhook subscribed to its type.
+	Enqueue(ctx context.Context, eventID string, eventType entity.CardEventType, payload []byte) error
+	// Replay creates a new pending delivery with the payload of an earlier
+	// one.
+	Replay(ctx context.Context, webhookID, deliveryID string) (*entity.WebhookDelivery, error)
+	FindDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*entity.WebhookDelivery, error)
+	// ClaimDeliveries returns up to limit due deliveries and postpones them
+	// until leaseUntil so that other instances skip them meanwhile.
+	ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+}
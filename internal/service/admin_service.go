@@ -0,0 +1,206 @@
+package service
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// AdminService holds the maintenance operations run from the command line.
+type AdminService struct {
+	userRepo  repository.UserRepo
+	cardRepo  repository.CardRepo
+	cacheRepo repository.CacheRepo
+	auth      *AuthService
+	// tokenTTL is how long a banned user's tokens have to be rejected.
+	tokenTTL time.Duration
+}
+
+// CreateAdmin registers a user with administrator rights.
+func (a *AdminService) CreateAdmin(ctx context.Context, user *entity.User) error {
+	if err := a.auth.Register(ctx, user); err != nil {
+		return err
+	}
+	return a.userRepo.SetAdmin(ctx, user.ID, true)
+}
+
+// SetAdmin grants or revokes administrator rights. The role is part of the
+// token, so it changes with the next login.
+func (a *AdminService) SetAdmin(ctx context.Context, email string, isAdmin bool) error {
+	user, err := a.findUser(ctx, email)
+	if err != nil {
+		return err
+	}
+	return a.userRepo.SetAdmin(ctx, user.ID, isAdmin)
+}
+
+// BanUser stops the user from logging in and rejects the tokens already
+// issued to them.
+func (a *AdminService) BanUser(ctx context.Context, email string) error {
+	user, err := a.findUser(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err = a.userRepo.SetBanned(ctx, user.ID, &now); err != nil {
+		return err
+	}
+	if err = a.cacheRepo.BlockUser(ctx, user.ID, a.tokenTTL); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return a.cacheRepo.DeleteUserData(ctx, user.ID)
+}
+
+func (a *AdminService) UnbanUser(ctx context.Context, email string) error {
+	user, err := a.findUser(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if err = a.userRepo.SetBanned(ctx, user.ID, nil); err != nil {
+		return err
+	}
+	return a.cacheRepo.UnblockUser(ctx, user.ID)
+}
+
+func (a *AdminService) findUser(ctx context.Context, email string) (*entity.User, error) {
+	user, err := a.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, e.ErrNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+type exportRecord struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+type exportUser struct {
+	ID        string     `json:"id"`
+	Email     string     `json:"email"`
+	Name      string     `json:"name"`
+	Surname   string     `json:"surname"`
+	Phone     string     `json:"phone"`
+	Telegram  string     `json:"telegram"`
+	IsAdmin   bool       `json:"is_admin"`
+	AvatarURL string     `json:"avatar_url,omitempty"`
+	BannedAt  *time.Time `json:"banned_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type exportCard struct {
+	ID          string              `json:"id"`
+	OwnerID     string              `json:"owner_id"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Status      entity.CardStatus   `json:"status"`
+	Category    entity.CardCategory `json:"category"`
+	City        string              `json:"city"`
+	Street      string              `json:"street"`
+	Latitude    *float64            `json:"latitude,omitempty"`
+	Longitude   *float64            `json:"longitude,omitempty"`
+	PlaceID     string              `json:"place_id,omitempty"`
+	Images      []exportCardImage   `json:"images"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+type exportCardImage struct {
+	URL      string `json:"url"`
+	Caption  string `json:"caption,omitempty"`
+	Position int    `json:"position"`
+	IsCover  bool   `json:"is_cover"`
+}
+
+// ExportData writes all users and cards as JSON lines, one record per line.
+// Password hashes are left out.
+func (a *AdminService) ExportData(ctx context.Context, w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	users, err := a.userRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if err = enc.Encode(exportRecord{Type: "user", Data: exportUser{
+			ID:        u.ID,
+			Email:     u.Email,
+			Name:      u.Name,
+			Surname:   u.Surname,
+			Phone:     u.Phone,
+			Telegram:  u.Telegram,
+			IsAdmin:   u.IsAdmin,
+			AvatarURL: u.AvatarURL,
+			BannedAt:  u.BannedAt,
+			CreatedAt: u.CreatedAt,
+		}}); err != nil {
+			return err
+		}
+	}
+
+	cards, err := a.cardRepo.FindAll(ctx, entity.CardFilter{})
+	if err != nil {
+		return err
+	}
+	for _, c := range cards {
+		card, err := a.cardRepo.GetByID(ctx, c.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get card %s: %w", c.ID, err)
+		}
+
+		record := exportCard{
+			ID:          card.ID,
+			OwnerID:     card.Owner.ID,
+			Title:       card.Title,
+			Description: card.Description,
+			Status:      card.Status,
+			Category:    card.Category,
+			City:        card.City,
+			Street:      card.Street,
+			PlaceID:     card.PlaceID,
+			Images:      make([]exportCardImage, 0, len(card.Images)),
+			CreatedAt:   card.CreatedAt,
+		}
+		if card.Location != nil {
+			record.Latitude = &card.Location.Latitude
+			record.Longitude = &card.Location.Longitude
+		}
+		for _, img := range card.Images {
+			record.Images = append(record.Images, exportCardImage{
+				URL:      img.URL,
+				Caption:  img.Caption,
+				Position: img.Position,
+				IsCover:  img.IsCover,
+			})
+		}
+
+		if err = enc.Encode(exportRecord{Type: "card", Data: record}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewAdminService(userRepo repository.UserRepo, cardRepo repository.CardRepo, cacheRepo repository.CacheRepo, auth *AuthService, tokenTTL time.Duration) *AdminService {
+	return &AdminService{
+		userRepo:  userRepo,
+		cardRepo:  cardRepo,
+		cacheRepo: cacheRepo,
+		auth:      auth,
+		tokenTTL:  tokenTTL,
+	}
+}
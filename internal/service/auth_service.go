@@ -2,10 +2,13 @@ package service
 
 import (
 	"LostAndFound/internal/auth"
+	e "LostAndFound/internal/common/errors"
 	"LostAndFound/internal/domain/entity"
 	"LostAndFound/internal/domain/repository"
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"time"
@@ -14,6 +17,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// streamTicketTTL is how long a stream ticket can be used.
+const streamTicketTTL = 30 * time.Second
+
 type AuthService struct {
 	userRepo     repository.UserRepo
 	cacheRepo    repository.CacheRepo
@@ -52,6 +58,9 @@ func (a AuthService) Login(c context.Context, email, password string) (string, e
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		return "", fmt.Errorf("invalid credentials")
 	}
+	if user.BannedAt != nil {
+		return "", e.ErrUserBanned
+	}
 
 	role := "user"
 	if user.IsAdmin {
@@ -93,6 +102,35 @@ func (a AuthService) Logout(c context.Context, token string) error {
 	return a.cacheRepo.BlacklistToken(ctx, token, ttl)
 }
 
+// isAdmin reports whether the request was made with an administrator's
+// token.
+func isAdmin(ctx context.Context) bool {
+	role, _ := ctx.Value("role").(string)
+	return role == "admin"
+}
+
+// CreateStreamTicket issues a single-use ticket for opening the card stream.
+func (a AuthService) CreateStreamTicket(c context.Context, userID, role string) (*entity.StreamTicket, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	ticket := &entity.StreamTicket{
+		Ticket:    base64.RawURLEncoding.EncodeToString(raw),
+		UserID:    userID,
+		Role:      role,
+		ExpiresAt: time.Now().Add(streamTicketTTL),
+	}
+
+	if err := a.cacheRepo.SaveStreamTicket(ctx, ticket, streamTicketTTL); err != nil {
+		return nil, fmt.Errorf("failed to save stream ticket: %w", err)
+	}
+	return ticket, nil
+}
+
 func NewAuthService(userRepo repository.UserRepo, cacheRepo repository.CacheRepo, tokenManager *auth.TokenManager) *AuthService {
 	return &AuthService{
 		userRepo:     userRepo,
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// feedBufferSize is how many events a subscriber may fall behind before
+	// it is dropped.
+	feedBufferSize = 64
+	// feedResubscribeDelay is the pause before subscribing to the event bus
+	// again after the subscription was lost.
+	feedResubscribeDelay = 5 * time.Second
+)
+
+// CardFeed fans card events out to the streaming clients of this instance.
+// It holds a single subscription to the event bus, so the number of clients
+// does not affect Redis.
+type CardFeed struct {
+	bus repository.CardEventBus
+
+	mu          sync.Mutex
+	subscribers map[*FeedSubscription]struct{}
+}
+
+// FeedSubscription receives the events that match its filter. Events is
+// closed when the subscriber falls too far behind; the client should then
+// reconnect and reload the cards.
+type FeedSubscription struct {
+	Events <-chan *entity.CardEvent
+
+	events chan *entity.CardEvent
+	filter entity.CardEventFilter
+	feed   *CardFeed
+}
+
+// Run relays events from the bus to the subscribers until ctx is done.
+func (f *CardFeed) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		events, err := f.bus.Subscribe(ctx)
+		if err != nil {
+			slog.Error("failed to subscribe to card events", "error", err)
+		} else {
+			for event := range events {
+				f.broadcast(event)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(feedResubscribeDelay):
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sub := range f.subscribers {
+		close(sub.events)
+		delete(f.subscribers, sub)
+	}
+}
+
+func (f *CardFeed) Subscribe(filter entity.CardEventFilter) *FeedSubscription {
+	events := make(chan *entity.CardEvent, feedBufferSize)
+	sub := &FeedSubscription{
+		Events: events,
+		events: events,
+		filter: filter,
+		feed:   f,
+	}
+
+	f.mu.Lock()
+	f.subscribers[sub] = struct{}{}
+	f.mu.Unlock()
+	return sub
+}
+
+// Close stops the delivery of events to the subscription.
+func (s *FeedSubscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	if _, ok := s.feed.subscribers[s]; ok {
+		close(s.events)
+		delete(s.feed.subscribers, s)
+	}
+}
+
+func (f *CardFeed) broadcast(event *entity.CardEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for sub := range f.subscribers {
+		if !sub.filter.Match(&event.Card) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// A slow client must not hold back the others.
+			close(sub.events)
+			delete(f.subscribers, sub)
+		}
+	}
+}
+
+// publish is the outbox consumer that broadcasts card events to the feeds of
+// all instances.
+func (f *CardFeed) publish(ctx context.Context, event *entity.DomainEvent) error {
+	return f.bus.Publish(ctx, &entity.CardEvent{
+		Type:       entity.CardEventType(event.Type),
+		Card:       *event.Card,
+		OccurredAt: event.OccurredAt,
+	})
+}
+
+func NewCardFeed(bus repository.CardEventBus) *CardFeed {
+	return &CardFeed{
+		bus:         bus,
+		subscribers: make(map[*FeedSubscription]struct{}),
+	}
+}
@@ -2,52 +2,173 @@ package service
 
 import (
 	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/common/imaging"
 	"LostAndFound/internal/domain/entity"
 	"LostAndFound/internal/domain/repository"
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// similarImageDistance is the default maximum Hamming distance between
+	// perceptual hashes for two images to be considered similar.
+	similarImageDistance = 10
+	// duplicateImageDistance is the maximum distance at which two images are
+	// treated as the same photo.
+	duplicateImageDistance = 3
+	// maxImagePixels bounds the images decoded for hashing and previews.
+	maxImagePixels = 40_000_000
+	// imageInspectionBudget bounds hashing the images of a new card before it
+	// is saved; the images left over are hashed by a job.
+	imageInspectionBudget = 2 * time.Second
+
+	// mapClusterMaxZoom is the last zoom level at which the map is clustered;
+	// closer in, individual cards are returned.
+	mapClusterMaxZoom = 15
+	// mapClusterCellPx is the side of a clustering grid cell in screen pixels
+	// of a 256px tile.
+	mapClusterCellPx = 64
+	// mapCardsLimit caps the number of individual cards in one viewport.
+	mapCardsLimit = 500
+	// mapClustersLimit caps the number of clusters in one viewport.
+	mapClustersLimit = 1000
+
+	// reverseGeocodeRadius is how far the nearest gazetteer entry may be
+	// from a card to be used as its address.
+	reverseGeocodeRadius = 300 * entity.Meter
+)
+
 type CardService struct {
 	userRepo  repository.UserRepo
 	repo      repository.CardRepo
 	cacheRepo repository.CacheRepo
 	fileRepo  repository.FileStorage
+	geocoder  repository.Geocoder
+	outbox    *OutboxRelay
+	jobs      *JobRunner
+	// notifications warns owners about duplicates of their photos.
+	notifications *NotificationService
+
+	maxSearchRadius entity.Distance
+	// trashRetention is how long a deleted card can be restored before it
+	// is purged.
+	trashRetention time.Duration
 }
 
-func (l *CardService) CreateCard(c context.Context, card *entity.Card) error {
+// CreateCard saves a new card and returns the cards that already show the
+// same photos. Images that cannot be hashed within imageInspectionBudget are
+// hashed afterwards by a job, which notifies the owner about duplicates.
+func (l *CardService) CreateCard(c context.Context, card *entity.Card) ([]*entity.SimilarCard, error) {
 	ctx, cancel := context.WithTimeout(c, 5*time.Second)
 	defer cancel()
 
 	userID, ok := c.Value("userID").(string)
 	if !ok || userID == "" {
-		return e.ErrUnauthorized
+		return nil, e.ErrUnauthorized
 	}
 	card.Owner.ID = userID
 
 	owner, err := l.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("error finding owner by id: %w", err)
+		return nil, fmt.Errorf("error finding owner by id: %w", err)
 	}
 	card.Owner.Name = owner.Name
 	card.Owner.Surname = owner.Surname
 	card.Owner.Phone = owner.Phone
 	card.Owner.Telegram = owner.Telegram
+	card.Owner.AvatarURL = owner.AvatarThumbURL
 	card.ID = uuid.New().String()
+	if card.Category == "" {
+		card.Category = entity.CategoryOther
+	}
+
+	if err = l.checkFileRefs(card); err != nil {
+		return nil, err
+	}
+
+	if card.Location == nil {
+		// An address the gazetteer doesn't know leaves the card without a point.
+		if err = l.locateAddress(ctx, card); err != nil && !errors.Is(err, e.ErrAddressNotFound) {
+			return nil, err
+		}
+	} else {
+		if !card.Location.Valid() {
+			return nil, e.ErrInvalidLocation
+		}
+		l.fillAddress(ctx, card, true, true)
+	}
+	if card.City == "" {
+		return nil, e.ErrAddressNotFound
+	}
+
+	card.PreviewURL = normalizeImages(card.Images)
+	l.inspectNewImages(ctx, card.Images)
+
+	if err = l.repo.Create(ctx, card, newCardEvent(entity.EventCardCreated, card)); err != nil {
+		return nil, err
+	}
+	l.outbox.Notify()
+
+	l.generateBlurredPreviews(ctx, card)
+
+	if !slices.ContainsFunc(card.Images, func(img entity.CardImage) bool { return img.Hash != nil }) {
+		return nil, nil
+	}
+
+	duplicates, err := l.repo.FindSimilarImages(ctx, card.ID, duplicateImageDistance)
+	if err != nil {
+		slog.Error("failed to check for duplicate images", "card_id", card.ID, "error", err)
+		return nil, nil
+	}
+	for _, d := range duplicates {
+		l.signSimilarCard(d, userID)
+	}
 
-	return l.repo.Create(ctx, card)
+	return duplicates, nil
 }
 
 func (l *CardService) GetCardByID(c context.Context, id string) (*entity.Card, error) {
 	ctx, cancel := context.WithTimeout(c, 50*time.Second)
 	defer cancel()
 
+	card, err := l.visibleCard(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	viewerID, _ := c.Value("userID").(string)
+	l.signFileURLs(card, viewerID)
+
+	return card, nil
+}
+
+// visibleCard hides the cards in the trash from everyone but their owner and
+// the administrators.
+func (l *CardService) visibleCard(ctx context.Context, id string) (*entity.Card, error) {
+	card, err := l.getCard(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	viewerID, _ := ctx.Value("userID").(string)
+	if card.DeletedAt != nil && viewerID != card.Owner.ID && !isAdmin(ctx) {
+		return nil, e.ErrNotFound
+	}
+	return card, nil
+}
+
+func (l *CardService) getCard(ctx context.Context, id string) (*entity.Card, error) {
 	card, err := l.cacheRepo.GetCardByID(ctx, id)
 	if err == nil && card != nil {
 		return card, nil
@@ -66,115 +187,1165 @@ func (l *CardService) GetCardByID(c context.Context, id string) (*entity.Card, e
 	return card, nil
 }
 
-func (l *CardService) GetAllCards(ctx context.Context, filter string) ([]*entity.Card, error) {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+func (l *CardService) GetAllCards(c context.Context, filter entity.CardFilter) ([]*entity.Card, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
 	defer cancel()
 
-	return l.repo.FindAll(ctx, filter)
+	if err := checkCardFilter(filter, l.maxSearchRadius); err != nil {
+		return nil, err
+	}
+	if filter.Deleted && !isAdmin(c) {
+		return nil, e.ErrPermissionDenied
+	}
+
+	cards, err := l.repo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	viewerID, _ := c.Value("userID").(string)
+	for _, card := range cards {
+		l.signFileURLs(card, viewerID)
+	}
+
+	return cards, nil
 }
 
+// UpdateCard changes the fields set in updated and keeps the rest; a zero
+// value means "unchanged".
 func (l *CardService) UpdateCard(c context.Context, updated *entity.Card) error {
 	ctx, cancel := context.WithTimeout(c, 5*time.Second)
 	defer cancel()
 
-	current, err := l.repo.GetByID(ctx, updated.ID)
+	current, err := l.editableCard(ctx, updated.ID, updated.Owner.ID, updated.Version)
 	if err != nil {
-		return e.ErrNotFound
+		return err
 	}
 
-	changed := false
+	next := *current
+	if updated.Title != "" {
+		next.Title = updated.Title
+	}
+	if updated.Description != "" {
+		next.Description = updated.Description
+	}
+	if updated.City != "" {
+		next.City = updated.City
+	}
+	if updated.Street != "" {
+		next.Street = updated.Street
+	}
+	if updated.Status != "" {
+		next.Status = updated.Status
+	}
+	if updated.Category != "" {
+		next.Category = updated.Category
+	}
+	if updated.Location != nil {
+		next.Location = updated.Location
+	}
+	if len(updated.Images) > 0 {
+		next.Images = updated.Images
+	}
 
-	if updated.Title != "" && updated.Title != current.Title {
-		current.Title = updated.Title
-		changed = true
+	return l.replaceCard(ctx, current, &next, updated.Owner.ID)
+}
+
+// PatchCard lets apply edit a copy of the card and saves what it turns the
+// card into, so unlike UpdateCard it can also clear fields. An error from
+// apply is returned as is.
+func (l *CardService) PatchCard(c context.Context, id, userID string, version int, apply func(card *entity.Card) error) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	current, err := l.editableCard(ctx, id, userID, version)
+	if err != nil {
+		return err
 	}
-	if updated.Description != "" && updated.Description != current.Description {
-		current.Description = updated.Description
-		changed = true
+
+	edited := *current
+	edited.Images = slices.Clone(current.Images)
+	if err = apply(&edited); err != nil {
+		return err
 	}
-	if updated.City != "" && updated.City != current.City {
-		current.City = updated.City
-		changed = true
+
+	next := *current
+	next.Title, next.Description = edited.Title, edited.Description
+	next.City, next.Street = edited.City, edited.Street
+	next.Status, next.Category = edited.Status, edited.Category
+	next.Location, next.Images = edited.Location, edited.Images
+
+	return l.replaceCard(ctx, current, &next, userID)
+}
+
+// editableCard loads a card for an update by its owner. A non-zero version
+// has to match the current one.
+func (l *CardService) editableCard(ctx context.Context, id, userID string, version int) (*entity.Card, error) {
+	current, err := l.repo.GetByID(ctx, id)
+	if err != nil || current.DeletedAt != nil {
+		return nil, e.ErrNotFound
 	}
-	if updated.Street != "" && updated.Street != current.Street {
-		current.Street = updated.Street
-		changed = true
+	if current.Owner.ID != userID {
+		return nil, e.ErrPermissionDenied
 	}
-	if updated.Status != "" && updated.Status != current.Status {
-		current.Status = updated.Status
-		changed = true
+	if version != 0 && version != current.Version {
+		return nil, e.ErrVersionMismatch
 	}
-	if updated.PreviewURL != "" && updated.PreviewURL != current.PreviewURL {
-		current.PreviewURL = updated.PreviewURL
-		changed = true
+	return current, nil
+}
+
+// replaceCard saves next in place of current and records the change as a
+// revision by authorID.
+func (l *CardService) replaceCard(ctx context.Context, current, next *entity.Card, authorID string) error {
+	if next.Category == "" {
+		next.Category = entity.CategoryOther
 	}
-	if updated.Latitude != 0 && updated.Latitude != current.Latitude {
-		current.Latitude = updated.Latitude
-		changed = true
+	changed := changedFields(entity.SnapshotOf(current), entity.SnapshotOf(next))
+	if len(changed) == 0 {
+		return e.ErrNoChanges
 	}
-	if updated.Longitude != 0 && updated.Longitude != current.Longitude {
-		current.Longitude = updated.Longitude
-		changed = true
+	moved := slices.Contains(changed, entity.CardFieldLocation)
+	cityChanged := slices.Contains(changed, entity.CardFieldCity)
+	streetChanged := slices.Contains(changed, entity.CardFieldStreet)
+
+	if next.Location != nil && !next.Location.Valid() {
+		return e.ErrInvalidLocation
 	}
-	if len(updated.Images) > 0 && !slices.Equal(updated.Images, current.Images) {
-		current.Images = updated.Images
-		changed = true
+	if slices.Contains(changed, entity.CardFieldImages) {
+		next.Images = slices.Clone(next.Images)
+		next.PreviewURL = normalizeImages(next.Images)
+	} else {
+		next.Images, next.PreviewURL = current.Images, current.PreviewURL
 	}
 
-	if !changed {
+	if err := l.checkFileRefs(next); err != nil {
+		return err
+	}
+
+	// A moved card gets the address of its new point, unless the request sets
+	// the address itself; an address change alone, or a removed point, moves
+	// the card to the address when the gazetteer knows it.
+	if moved && next.Location != nil {
+		l.fillAddress(ctx, next, !cityChanged, !streetChanged)
+	} else if cityChanged || streetChanged || moved {
+		if err := l.locateAddress(ctx, next); err != nil && !errors.Is(err, e.ErrAddressNotFound) {
+			return err
+		}
+	}
+	if next.City == "" {
+		l.fillAddress(ctx, next, true, false)
+		if next.City == "" {
+			return e.ErrAddressNotFound
+		}
+	}
+
+	// The address and the point may have been filled back to what they were.
+	revision := newRevision(current, next, authorID)
+	if len(revision.Fields) == 0 {
 		return e.ErrNoChanges
 	}
 
-	if err = l.repo.Update(ctx, current); err != nil {
+	event := newCardEvent(entity.EventCardUpdated, next)
+	event.PreviousCard = current
+	if err := l.repo.Update(ctx, next, revision, event); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrVersionMismatch
+		}
 		return fmt.Errorf("failed to update card: %w", err)
 	}
+	l.cardChanged(ctx, next.ID)
 
-	_ = l.cacheRepo.DeleteCard(ctx, current.ID)
+	l.generateBlurredPreviews(ctx, next)
 
 	return nil
 }
 
-func (l *CardService) DeleteCard(ctx context.Context, id string) error {
-	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+// GetCardHistory lists the revisions of a card, oldest first. Only the owner
+// and the administrators can see them.
+func (l *CardService) GetCardHistory(ctx context.Context, cardID string) ([]*entity.CardRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	userID, ok := ctx.Value("userID").(string)
+	if !ok || userID == "" {
+		return nil, e.ErrUnauthorized
+	}
+
+	card, err := l.repo.GetByID(ctx, cardID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, e.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get card from DB: %w", err)
+	}
+	if card.Owner.ID != userID && !isAdmin(ctx) {
+		return nil, e.ErrPermissionDenied
+	}
+
+	revisions, err := l.repo.FindRevisions(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The history is only shown to the owner and the administrators, so the
+	// images of document cards are not blurred.
+	for _, rev := range revisions {
+		for _, images := range [][]entity.CardImage{rev.Before.Images, rev.After.Images} {
+			for i, img := range images {
+				images[i].URL = l.downloadURL(img.URL, false)
+			}
+		}
+	}
+	return revisions, nil
+}
+
+// RevertCard brings the card back to its state after the given revision, or
+// to the state before the first one for revision 0. The revert is stored as
+// a new revision. Images whose files have been deleted since are left out,
+// and a revision without images takes all of them off the card.
+func (l *CardService) RevertCard(ctx context.Context, cardID string, number int) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	userID := ctx.Value("userID").(string)
+	userID, ok := ctx.Value("userID").(string)
+	if !ok || userID == "" {
+		return e.ErrUnauthorized
+	}
+	if !isAdmin(ctx) {
+		return e.ErrPermissionDenied
+	}
+
+	current, err := l.repo.GetByID(ctx, cardID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return fmt.Errorf("failed to get card from DB: %w", err)
+	}
+	if current.DeletedAt != nil {
+		return e.ErrNotFound
+	}
 
-	card, err := l.GetCardByID(ctx, id)
+	revisions, err := l.repo.FindRevisions(ctx, cardID)
 	if err != nil {
 		return err
 	}
+	var target entity.CardSnapshot
+	switch {
+	case number == 0 && len(revisions) > 0:
+		target = revisions[0].Before
+	case number > 0 && number <= len(revisions) && revisions[number-1].Number == number:
+		target = revisions[number-1].After
+	default:
+		return e.ErrNotFound
+	}
+
+	previous := *current
+	target.Images = l.existingImages(ctx, target.Images)
+	target.ApplyTo(current)
+	current.PreviewURL = normalizeImages(current.Images)
+
+	revision := newRevision(&previous, current, userID)
+	if len(revision.Fields) == 0 {
+		return e.ErrNoChanges
+	}
+	revision.RevertedTo = &number
+	if err = l.checkFileRefs(current); err != nil {
+		return err
+	}
+
+	event := newCardEvent(entity.EventCardUpdated, current)
+	event.PreviousCard = &previous
+	if err = l.repo.Update(ctx, current, revision, event); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrVersionMismatch
+		}
+		return fmt.Errorf("failed to revert card: %w", err)
+	}
+	l.cardChanged(ctx, current.ID)
+
+	l.generateBlurredPreviews(ctx, current)
+
+	return nil
+}
+
+// existingImages drops the images stored in our bucket whose files are gone.
+func (l *CardService) existingImages(ctx context.Context, images []entity.CardImage) []entity.CardImage {
+	var result []entity.CardImage
+	for _, img := range images {
+		if key, ok := objectKey(l.fileRepo, img.URL); ok {
+			if exists, err := l.fileRepo.FileExists(ctx, key); err == nil && !exists {
+				continue
+			}
+		}
+		result = append(result, img)
+	}
+	return result
+}
+
+// newRevision records the change of a card from previous to current.
+func newRevision(previous, current *entity.Card, authorID string) *entity.CardRevision {
+	before, after := entity.SnapshotOf(previous), entity.SnapshotOf(current)
+	return &entity.CardRevision{
+		ID:       uuid.NewString(),
+		CardID:   current.ID,
+		AuthorID: authorID,
+		Fields:   changedFields(before, after),
+		Before:   before,
+		After:    after,
+	}
+}
+
+func changedFields(before, after entity.CardSnapshot) []entity.CardField {
+	var fields []entity.CardField
+	add := func(field entity.CardField, changed bool) {
+		if changed {
+			fields = append(fields, field)
+		}
+	}
+	add(entity.CardFieldTitle, before.Title != after.Title)
+	add(entity.CardFieldDescription, before.Description != after.Description)
+	add(entity.CardFieldCity, before.City != after.City)
+	add(entity.CardFieldStreet, before.Street != after.Street)
+	add(entity.CardFieldStatus, before.Status != after.Status)
+	add(entity.CardFieldCategory, before.Category != after.Category)
+	add(entity.CardFieldLocation, (before.Location == nil) != (after.Location == nil) ||
+		before.Location != nil && *before.Location != *after.Location)
+	add(entity.CardFieldImages, !sameImages(before.Images, after.Images))
+	return fields
+}
+
+// DeleteCard moves the card to the trash, from where the owner can restore
+// it until it is purged. A non-zero version has to match the current one.
+func (l *CardService) DeleteCard(ctx context.Context, id string, version int) error {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+	defer cancel()
+
+	userID, ok := ctx.Value("userID").(string)
+	if !ok || userID == "" {
+		return e.ErrUnauthorized
+	}
+
+	card, err := l.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return fmt.Errorf("failed to get card from DB: %w", err)
+	}
 	if userID != card.Owner.ID {
 		return e.ErrPermissionDenied
 	}
-	if err = l.repo.Delete(ctx, id); err != nil {
+	if card.DeletedAt != nil {
+		return e.ErrNotFound
+	}
+	if version != 0 && version != card.Version {
+		return e.ErrVersionMismatch
+	}
+	if err = l.repo.Delete(ctx, id, card.Version, newCardEvent(entity.EventCardDeleted, card)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrVersionMismatch
+		}
 		return fmt.Errorf("failed to delete card: %w", err)
 	}
+	l.cardChanged(ctx, id)
+
+	return nil
+}
+
+// GetTrash lists the user's deleted cards that can still be restored, the
+// most recently deleted first.
+func (l *CardService) GetTrash(c context.Context, userID string) ([]*entity.Card, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	// Cards past the retention can no longer be restored and only wait for
+	// the purge.
+	cards, err := l.repo.FindAll(ctx, entity.CardFilter{
+		OwnerID:      userID,
+		Deleted:      true,
+		DeletedAfter: time.Now().Add(-l.trashRetention),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, card := range cards {
+		l.signFileURLs(card, userID)
+	}
+	return cards, nil
+}
+
+// RestoreCard takes a card out of the trash. The owner and the
+// administrators can do it until the card is purged.
+func (l *CardService) RestoreCard(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	userID, ok := ctx.Value("userID").(string)
+	if !ok || userID == "" {
+		return e.ErrUnauthorized
+	}
+
+	card, err := l.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return fmt.Errorf("failed to get card from DB: %w", err)
+	}
+	if card.Owner.ID != userID && !isAdmin(ctx) {
+		return e.ErrPermissionDenied
+	}
+	if card.DeletedAt == nil {
+		return e.ErrNoChanges
+	}
+
+	card.DeletedAt = nil
+	err = l.repo.Restore(ctx, id, time.Now().Add(-l.trashRetention), newCardEvent(entity.EventCardRestored, card))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return fmt.Errorf("failed to restore card: %w", err)
+	}
+	l.cardChanged(ctx, id)
+
+	return nil
+}
+
+// purgeTrash is the scheduled job that removes the cards kept in the trash
+// longer than trashRetention. Their files are deleted by the outbox once the
+// rows are gone.
+func (l *CardService) purgeTrash(ctx context.Context, _ *entity.Job) error {
+	before := time.Now().Add(-l.trashRetention)
+	cards, err := l.repo.FindAll(ctx, entity.CardFilter{Deleted: true, DeletedBefore: before})
+	if err != nil {
+		return err
+	}
+
+	purged := 0
+	for _, c := range cards {
+		card, err := l.repo.GetByID(ctx, c.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get card %s: %w", c.ID, err)
+		}
+
+		revisions, err := l.repo.FindRevisions(ctx, card.ID)
+		if err != nil {
+			return err
+		}
+
+		event := newCardEvent(entity.EventCardPurged, card)
+		event.DeletedFiles = l.imageFiles(card, historyImages(card, revisions)...)
+		err = l.repo.Purge(ctx, card.ID, before, event)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Restored in the meantime.
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to purge card %s: %w", card.ID, err)
+		}
+		purged++
+	}
+	if purged > 0 {
+		l.outbox.Notify()
+	}
+
+	slog.Info("purged deleted cards", "count", purged)
+	return nil
+}
 
-	_ = l.cacheRepo.DeleteCard(ctx, id)
+func (l *CardService) ReorderImages(ctx context.Context, cardID string, imageIDs []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 
-	for _, key := range card.Images {
-		if err := l.fileRepo.DeleteFile(ctx, key); err != nil {
+	card, err := l.ownedCard(ctx, cardID)
+	if err != nil {
+		return err
+	}
 
-			fmt.Printf("failed to delete file %s: %v\n", key, err)
+	if len(imageIDs) != len(card.Images) {
+		return e.ErrInvalidImageOrder
+	}
+	for _, img := range card.Images {
+		if !slices.Contains(imageIDs, img.ID) {
+			return e.ErrInvalidImageOrder
 		}
 	}
 
+	if err = l.repo.ReorderImages(ctx, cardID, imageIDs, newCardEvent(entity.EventCardImagesChanged, card)); err != nil {
+		return fmt.Errorf("failed to reorder images: %w", err)
+	}
+	l.cardChanged(ctx, cardID)
+
+	return nil
+}
+
+func (l *CardService) UpdateImage(ctx context.Context, cardID, imageID string, caption *string, makeCover bool) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	card, err := l.ownedCard(ctx, cardID)
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(card.Images, func(img entity.CardImage) bool { return img.ID == imageID })
+	if idx < 0 {
+		return e.ErrNotFound
+	}
+	img := card.Images[idx]
+
+	changed := false
+	if caption != nil && *caption != img.Caption {
+		img.Caption = *caption
+		changed = true
+	}
+	if makeCover && !img.IsCover {
+		img.IsCover = true
+		changed = true
+	}
+	if !changed {
+		return e.ErrNoChanges
+	}
+
+	if err = l.repo.UpdateImage(ctx, cardID, img, newCardEvent(entity.EventCardImagesChanged, card)); err != nil {
+		return fmt.Errorf("failed to update image: %w", err)
+	}
+	l.cardChanged(ctx, cardID)
+
 	return nil
 }
 
-func (l *CardService) GetCardsNear(ctx context.Context, lat, lon, radius float64, status string) ([]*entity.Card, error) {
+func (l *CardService) RemoveImage(ctx context.Context, cardID, imageID string) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	return l.repo.FindNearLocation(ctx, lat, lon, radius, status)
+	card, err := l.ownedCard(ctx, cardID)
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(card.Images, func(img entity.CardImage) bool { return img.ID == imageID })
+	if idx < 0 {
+		return e.ErrNotFound
+	}
+
+	event := newCardEvent(entity.EventCardImagesChanged, card)
+	event.DeletedFiles = l.imageFiles(card, card.Images[idx])
+	if err = l.repo.DeleteImage(ctx, cardID, imageID, event); err != nil {
+		return fmt.Errorf("failed to remove image: %w", err)
+	}
+	l.cardChanged(ctx, cardID)
+
+	return nil
+}
+
+// historyImages returns the current images of the card and those only kept
+// in its history, each URL once.
+func historyImages(card *entity.Card, revisions []*entity.CardRevision) []entity.CardImage {
+	seen := make(map[string]bool)
+	var images []entity.CardImage
+	add := func(imgs []entity.CardImage) {
+		for _, img := range imgs {
+			if !seen[img.URL] {
+				seen[img.URL] = true
+				images = append(images, img)
+			}
+		}
+	}
+	add(card.Images)
+	for _, rev := range revisions {
+		add(rev.Before.Images)
+		add(rev.After.Images)
+	}
+	return images
+}
+
+// ownedCard loads a card straight from the database and checks that it
+// belongs to the user making the request.
+func (l *CardService) ownedCard(ctx context.Context, cardID string) (*entity.Card, error) {
+	userID, ok := ctx.Value("userID").(string)
+	if !ok || userID == "" {
+		return nil, e.ErrUnauthorized
+	}
+
+	card, err := l.repo.GetByID(ctx, cardID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, e.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get card from DB: %w", err)
+	}
+	if card.Owner.ID != userID {
+		return nil, e.ErrPermissionDenied
+	}
+	if card.DeletedAt != nil {
+		return nil, e.ErrNotFound
+	}
+	return card, nil
+}
+
+// imageFiles returns the storage keys of the images and of their blurred
+// previews.
+func (l *CardService) imageFiles(card *entity.Card, images ...entity.CardImage) []string {
+	var keys []string
+	for _, img := range images {
+		key, ok := objectKey(l.fileRepo, img.URL)
+		if !ok {
+			continue
+		}
+		keys = append(keys, key)
+		if card.Category == entity.CategoryDocuments {
+			keys = append(keys, blurredKey(key))
+		}
+	}
+	return keys
+}
+
+// invalidateCache is the outbox consumer that drops the cached card and the
+// tiles it was or is shown on.
+// cardChanged drops the cached card right after a write, so that the next
+// read, and with it the ETag, shows the new version. The outbox consumer
+// drops it again and clears the map tiles, but only later.
+func (l *CardService) cardChanged(ctx context.Context, id string) {
+	if err := l.cacheRepo.DeleteCard(ctx, id); err != nil {
+		slog.Error("failed to invalidate card cache", "card_id", id, "error", err)
+	}
+	l.outbox.Notify()
+}
+
+func (l *CardService) invalidateCache(ctx context.Context, event *entity.DomainEvent) error {
+	if err := l.cacheRepo.DeleteCard(ctx, event.AggregateID); err != nil {
+		return fmt.Errorf("failed to invalidate card cache: %w", err)
+	}
+	if event.Type == entity.EventCardImagesChanged {
+		return nil
+	}
+
+	cards := []*entity.Card{event.Card}
+	if event.PreviousCard != nil {
+		cards = append(cards, event.PreviousCard)
+	}
+	return l.invalidateTiles(ctx, cards...)
+}
+
+// normalizeImages numbers the images in the given order and makes sure
+// exactly one of them is the cover. It returns the cover URL, which is used
+// as the card preview.
+func normalizeImages(images []entity.CardImage) string {
+	cover := slices.IndexFunc(images, func(img entity.CardImage) bool { return img.IsCover })
+	if cover < 0 {
+		cover = 0
+	}
+
+	for i := range images {
+		images[i].Position = i
+		images[i].IsCover = i == cover
+	}
+
+	if len(images) == 0 {
+		return ""
+	}
+	return images[cover].URL
+}
+
+func sameImages(a, b []entity.CardImage) bool {
+	return slices.EqualFunc(a, b, func(x, y entity.CardImage) bool {
+		return x.URL == y.URL && x.Caption == y.Caption && x.IsCover == y.IsCover
+	})
+}
+
+// GetCardsNear lists the cards in the filter's area, closest first.
+func (l *CardService) GetCardsNear(c context.Context, filter entity.CardFilter) ([]*entity.Card, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if filter.Area == nil {
+		return nil, fmt.Errorf("%w: an area is required", e.ErrInvalidRadius)
+	}
+	if err := checkCardFilter(filter, l.maxSearchRadius); err != nil {
+		return nil, err
+	}
+
+	cards, err := l.repo.FindNearLocation(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	viewerID, _ := c.Value("userID").(string)
+	for _, card := range cards {
+		l.signFileURLs(card, viewerID)
+	}
+
+	return cards, nil
+}
+
+func (l *CardService) GetSimilarImages(c context.Context, id string, maxDistance int) ([]*entity.SimilarCard, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if _, err := l.visibleCard(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if maxDistance <= 0 {
+		maxDistance = similarImageDistance
+	}
+
+	similar, err := l.repo.FindSimilarImages(ctx, id, maxDistance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar images: %w", err)
+	}
+
+	viewerID, _ := c.Value("userID").(string)
+	for _, s := range similar {
+		l.signSimilarCard(s, viewerID)
+	}
+
+	return similar, nil
+}
+
+// queueImageInspection queues the hashing of the card images that have no
+// hash yet. It consumes card.created and card.updated events; the event ID
+// keeps a redelivered event from being queued twice.
+func (l *CardService) queueImageInspection(ctx context.Context, event *entity.DomainEvent) error {
+	if !slices.ContainsFunc(event.Card.Images, l.needsInspection) {
+		return nil
+	}
+	return l.jobs.Enqueue(ctx, jobKindInspectImages, jobKindInspectImages+":"+event.ID, imageInspection{
+		CardID:  event.Card.ID,
+		Created: event.Type == entity.EventCardCreated,
+	})
+}
+
+// imageInspection is the payload of a cards.inspect_images job.
+type imageInspection struct {
+	CardID string `json:"card_id"`
+	// Created asks to warn the owner about duplicates of the new card.
+	Created bool `json:"created"`
+}
+
+// inspectNewImages hashes the images of a card about to be created, so that
+// CreateCard can report duplicates. It takes at most imageInspectionBudget;
+// the images left without a hash are picked up by the job.
+func (l *CardService) inspectNewImages(ctx context.Context, images []entity.CardImage) {
+	ctx, cancel := context.WithTimeout(ctx, imageInspectionBudget)
+	defer cancel()
+
+	for i := range images {
+		if !l.needsInspection(images[i]) {
+			continue
+		}
+		key, _ := objectKey(l.fileRepo, images[i].URL)
+		if err := l.inspectImage(ctx, key, &images[i]); err != nil {
+			slog.Error("failed to inspect image", "key", key, "error", err)
+		}
+	}
+}
+
+// inspectCardImages is the job that hashes the images of a card. For a new
+// card whose images were not all hashed by CreateCard, it then notifies the
+// owner when the same photo is already on another card.
+func (l *CardService) inspectCardImages(ctx context.Context, job *entity.Job) error {
+	var payload imageInspection
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to decode image inspection: %w", err)
+	}
+
+	card, err := l.repo.GetByID(ctx, payload.CardID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get card %s: %w", payload.CardID, err)
+	}
+
+	hashed, err := l.hashImages(ctx, card)
+	if err != nil {
+		return err
+	}
+	if hashed == 0 || !payload.Created || card.DeletedAt != nil {
+		return nil
+	}
+	return l.warnAboutDuplicates(ctx, card)
+}
+
+// warnAboutDuplicates notifies the owner of a new card whose photo is
+// already on another card: the item may be lost or found already.
+func (l *CardService) warnAboutDuplicates(ctx context.Context, card *entity.Card) error {
+	if !slices.ContainsFunc(card.Images, func(img entity.CardImage) bool { return img.Hash != nil }) {
+		return nil
+	}
+
+	duplicates, err := l.repo.FindSimilarImages(ctx, card.ID, duplicateImageDistance)
+	if err != nil {
+		return fmt.Errorf("failed to check for duplicate images: %w", err)
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	return l.notifications.Publish(ctx, &entity.Notification{
+		ID:     uuid.NewString(),
+		UserID: card.OwnerID,
+		Kind:   entity.NotificationDuplicateImages,
+		Title:  "Такие же фото уже есть в других объявлениях",
+		Body:   fmt.Sprintf("«%s»: совпадений — %d. Возможно, вещь уже ищут или нашли.", card.Title, len(duplicates)),
+		CardID: card.ID,
+	})
+}
+
+// hashImages records the dimensions and perceptual hashes of the card images
+// stored in our bucket that have no hash yet, and returns how many it hashed.
+// Images that cannot be fetched or decoded are left without them and simply
+// do not take part in similarity search.
+func (l *CardService) hashImages(ctx context.Context, card *entity.Card) (int, error) {
+	hashed := 0
+	for i := range card.Images {
+		img := &card.Images[i]
+		if !l.needsInspection(*img) {
+			continue
+		}
+
+		key, _ := objectKey(l.fileRepo, img.URL)
+		if err := l.inspectImage(ctx, key, img); err != nil {
+			slog.Error("failed to inspect image", "key", key, "error", err)
+			continue
+		}
+		if err := l.repo.UpdateImageDetails(ctx, *img); err != nil {
+			return hashed, err
+		}
+		hashed++
+	}
+
+	// The details do not change the version, so the cached card is dropped
+	// here rather than by a card event.
+	if hashed > 0 {
+		if err := l.cacheRepo.DeleteCard(ctx, card.ID); err != nil {
+			return hashed, fmt.Errorf("failed to invalidate card cache: %w", err)
+		}
+	}
+	return hashed, nil
+}
+
+func (l *CardService) needsInspection(img entity.CardImage) bool {
+	_, ok := objectKey(l.fileRepo, img.URL)
+	return ok && img.Hash == nil
+}
+
+func (l *CardService) inspectImage(ctx context.Context, key string, img *entity.CardImage) error {
+	body, err := l.fileRepo.GetFile(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	decoded, err := imaging.DecodeLimited(io.LimitReader(body, MaxUploadSize), maxImagePixels)
+	if err != nil {
+		return err
+	}
+
+	hash := imaging.DHash(decoded)
+	img.Hash = &hash
+	img.Width = decoded.Bounds().Dx()
+	img.Height = decoded.Bounds().Dy()
+	return nil
+}
+
+// ReindexCards rebuilds the search data derived from the cards: it hashes
+// the images that have no hash yet, so that they take part in the similar
+// image search, and drops the cached cards and map tiles. It returns the
+// number of cards and of newly hashed images.
+func (l *CardService) ReindexCards(ctx context.Context) (int, int, error) {
+	cards, err := l.repo.FindAll(ctx, entity.CardFilter{})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	hashed := 0
+	for _, c := range cards {
+		card, err := l.repo.GetByID(ctx, c.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, hashed, fmt.Errorf("failed to get card %s: %w", c.ID, err)
+		}
+
+		n, err := l.hashImages(ctx, card)
+		hashed += n
+		if err != nil {
+			return 0, hashed, err
+		}
+
+		if err = l.cacheRepo.DeleteCard(ctx, card.ID); err != nil {
+			return 0, hashed, fmt.Errorf("failed to invalidate card cache: %w", err)
+		}
+		if err = l.invalidateTiles(ctx, card); err != nil {
+			return 0, hashed, err
+		}
+	}
+	return len(cards), hashed, nil
+}
+
+func (l *CardService) signSimilarCard(s *entity.SimilarCard, viewerID string) {
+	blurred := s.Card.Category == entity.CategoryDocuments && viewerID != s.Card.Owner.ID
+	s.ImageURL = l.downloadURL(s.ImageURL, blurred)
+	l.signFileURLs(s.Card, viewerID)
+}
+
+// GetMapView returns the cards in the viewport. Zoomed out, cards are grouped
+// into clusters on a grid whose cells cover roughly the same screen area at
+// every zoom level.
+func (l *CardService) GetMapView(c context.Context, bbox entity.BoundingBox, zoom int, filter entity.CardFilter) (*entity.MapView, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := checkCardFilter(filter, l.maxSearchRadius); err != nil {
+		return nil, err
+	}
+
+	if zoom > mapClusterMaxZoom {
+		cards, err := l.repo.FindInBounds(ctx, bbox, filter, mapCardsLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cards in bounds: %w", err)
+		}
+
+		viewerID, _ := c.Value("userID").(string)
+		for _, card := range cards {
+			l.signFileURLs(card, viewerID)
+		}
+		return &entity.MapView{Cards: cards}, nil
+	}
+
+	cellSize := 360 / math.Exp2(float64(zoom)) * mapClusterCellPx / 256
+	clusters, err := l.repo.ClusterInBounds(ctx, bbox, filter, cellSize, mapClustersLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cluster cards: %w", err)
+	}
+	return &entity.MapView{Clustered: true, Clusters: clusters}, nil
+}
+
+// GetCardsTile returns the vector tile with card locations, rendering it in
+// PostGIS on a cache miss.
+func (l *CardService) GetCardsTile(c context.Context, tile entity.Tile) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	data, ok, err := l.cacheRepo.GetTile(ctx, tile)
+	if err == nil && ok {
+		return data, nil
+	}
+
+	data, err = l.repo.RenderTile(ctx, tile)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = l.cacheRepo.SaveTile(ctx, tile, data)
+
+	return data, nil
+}
+
+// invalidateTiles drops the cached tiles that contain any of the cards at
+// every zoom level.
+func (l *CardService) invalidateTiles(ctx context.Context, cards ...*entity.Card) error {
+	var tiles []entity.Tile
+	for _, card := range cards {
+		if card.Location == nil {
+			continue
+		}
+		for z := 0; z <= entity.MaxTileZoom; z++ {
+			tile := entity.TileForPoint(card.Location.Latitude, card.Location.Longitude, z)
+			if !slices.Contains(tiles, tile) {
+				tiles = append(tiles, tile)
+			}
+		}
+	}
+
+	if err := l.cacheRepo.DeleteTiles(ctx, tiles); err != nil {
+		return fmt.Errorf("failed to invalidate tiles: %w", err)
+	}
+	return nil
+}
+
+// newCardEvent prepares an outbox event for a change of the card. The card is
+// stored as it is when the change is committed.
+func newCardEvent(eventType entity.EventType, card *entity.Card) *entity.DomainEvent {
+	return &entity.DomainEvent{
+		ID:          uuid.NewString(),
+		Type:        eventType,
+		AggregateID: card.ID,
+		OccurredAt:  time.Now(),
+		Card:        card,
+	}
+}
+
+// checkFileRefs rejects cards that reference private files uploaded by
+// another user, since reading the card would hand out signed URLs to them.
+// Photos of documents have to be private files: the public prefix is
+// readable by anyone who knows the key, which would bypass the blurring.
+func (l *CardService) checkFileRefs(card *entity.Card) error {
+	for _, img := range card.Images {
+		ref := img.URL
+		key, ok := objectKey(l.fileRepo, ref)
+		if !ok {
+			continue
+		}
+		if isPrivateKey(key) && !ownsKey(card.Owner.ID, key) {
+			return e.ErrPermissionDenied
+		}
+		if !isPrivateKey(key) && card.Category == entity.CategoryDocuments {
+			return e.ErrPublicDocumentImage
+		}
+	}
+	return nil
+}
+
+// signFileURLs replaces stored file references with URLs the viewer can
+// open. Private files get short-lived presigned URLs, and non-owners of
+// document cards only ever see blurred previews.
+func (l *CardService) signFileURLs(card *entity.Card, viewerID string) {
+	blurred := card.Category == entity.CategoryDocuments && viewerID != card.Owner.ID
+
+	card.PreviewURL = l.downloadURL(card.PreviewURL, blurred)
+	for i, img := range card.Images {
+		card.Images[i].URL = l.downloadURL(img.URL, blurred)
+	}
+}
+
+func (l *CardService) downloadURL(ref string, blurred bool) string {
+	if ref == "" {
+		return ""
+	}
+
+	key, ok := objectKey(l.fileRepo, ref)
+	if !ok {
+		if blurred {
+			return ""
+		}
+		return ref
+	}
+
+	switch {
+	case blurred:
+		key = blurredKey(key)
+	case !isPrivateKey(key):
+		return publicURL(l.fileRepo, key)
+	}
+
+	url, err := l.fileRepo.GeneratePresignedGetURL(key, downloadURLExpiry)
+	if err != nil {
+		slog.Error("failed to sign file URL", "key", key, "error", err)
+		return ""
+	}
+	return url
+}
+
+func (l *CardService) generateBlurredPreviews(ctx context.Context, card *entity.Card) {
+	if card.Category != entity.CategoryDocuments {
+		return
+	}
+
+	for _, img := range card.Images {
+		key, ok := objectKey(l.fileRepo, img.URL)
+		if !ok {
+			continue
+		}
+		if err := l.blurImage(ctx, key); err != nil {
+			slog.Error("failed to generate blurred preview", "key", key, "error", err)
+		}
+	}
+}
+
+func (l *CardService) blurImage(ctx context.Context, key string) error {
+	body, err := l.fileRepo.GetFile(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	img, err := imaging.DecodeLimited(body, maxImagePixels)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = imaging.EncodeJPEG(&buf, imaging.Blur(imaging.Fit(img, 480), 12)); err != nil {
+		return fmt.Errorf("failed to encode blurred preview: %w", err)
+	}
+
+	return l.fileRepo.Put(ctx, blurredKey(key), "image/jpeg", &buf)
+}
+
+// checkCardFilter validates the search area of a listing filter.
+func checkCardFilter(filter entity.CardFilter, maxRadius entity.Distance) error {
+	if filter.Area == nil {
+		return nil
+	}
+	if !filter.Area.Center.Valid() {
+		return e.ErrInvalidLocation
+	}
+	if filter.Area.Radius <= 0 || filter.Area.Radius > maxRadius {
+		return fmt.Errorf("%w: must be positive and at most %g km", e.ErrInvalidRadius, maxRadius.Kilometers())
+	}
+	return nil
+}
+
+// fillAddress sets the card's city and street from the gazetteer entry nearest
+// to its point. Typed addresses often disagree with the pin, so the gazetteer
+// wins; when nothing is close enough the card keeps what the client sent.
+func (l *CardService) fillAddress(ctx context.Context, card *entity.Card, city, street bool) {
+	if card.Location == nil || (!city && !street) {
+		return
+	}
+
+	addr, err := l.geocoder.Reverse(ctx, *card.Location, reverseGeocodeRadius)
+	if err != nil {
+		slog.Error("failed to reverse geocode card", "card_id", card.ID, "error", err)
+		return
+	}
+	if addr == nil {
+		return
+	}
+
+	if city {
+		card.City = addr.City
+	}
+	if street && addr.Street != "" {
+		card.Street = addr.StreetLine()
+	}
 }
 
-func NewCardService(cardRepo repository.CardRepo, userRepo repository.UserRepo, cache repository.CacheRepo, fileRepo repository.FileStorage) *CardService {
+// locateAddress moves the card to the point of its city and street.
+func (l *CardService) locateAddress(ctx context.Context, card *entity.Card) error {
+	addr, err := resolveAddress(ctx, l.geocoder, card.City, card.Street)
+	if err != nil {
+		return err
+	}
+
+	card.Location = &entity.Location{Latitude: addr.Latitude, Longitude: addr.Longitude}
+	card.City = addr.City
+	return nil
+}
+
+func NewCardService(cardRepo repository.CardRepo, userRepo repository.UserRepo, cache repository.CacheRepo, fileRepo repository.FileStorage, geocoder repository.Geocoder, outbox *OutboxRelay, jobs *JobRunner, notifications *NotificationService, maxSearchRadius entity.Distance, trashRetention time.Duration) *CardService {
 	return &CardService{
-		repo:      cardRepo,
-		userRepo:  userRepo,
-		cacheRepo: cache,
-		fileRepo:  fileRepo,
+		repo:          cardRepo,
+		userRepo:      userRepo,
+		cacheRepo:     cache,
+		fileRepo:      fileRepo,
+		geocoder:      geocoder,
+		outbox:        outbox,
+		jobs:          jobs,
+		notifications: notifications,
+
+		maxSearchRadius: maxSearchRadius,
+		trashRetention:  trashRetention,
 	}
 }
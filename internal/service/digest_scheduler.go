@@ -0,0 +1,141 @@
+package service
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// digestSchedule is how often the digest job looks for due saved
+	// searches; digestTick, the same interval, is the delay of instant
+	// digests.
+	digestSchedule = "@every 1m"
+	digestTick     = time.Minute
+	// digestLag keeps a run from reading cards whose transactions may not
+	// have committed yet; they are picked up by the next run instead.
+	digestLag = 10 * time.Second
+	// digestBatchSize is how many saved searches are processed per query.
+	digestBatchSize = 100
+	// digestMaxTitles caps the number of card titles listed in one digest.
+	digestMaxTitles = 10
+)
+
+// digestIntervals is the minimal time between two runs of a saved search.
+var digestIntervals = map[entity.DigestFrequency]time.Duration{
+	entity.DigestInstant: digestTick,
+	entity.DigestDaily:   24 * time.Hour,
+}
+
+// DigestScheduler periodically re-runs saved searches and sends the cards
+// created since the previous run as a notification. It runs as a scheduled
+// job, so only one instance processes the searches at a time.
+type DigestScheduler struct {
+	searchRepo    repository.SavedSearchRepo
+	cardRepo      repository.CardRepo
+	notifications *NotificationService
+}
+
+// run is the periodic digest job.
+func (d *DigestScheduler) run(ctx context.Context, _ *entity.Job) error {
+	d.RunDue(ctx)
+	return nil
+}
+
+// RunDue runs every saved search whose interval has passed.
+func (d *DigestScheduler) RunDue(ctx context.Context) {
+	for frequency, interval := range digestIntervals {
+		runAt := time.Now().Add(-digestLag)
+		for ctx.Err() == nil {
+			searches, err := d.searchRepo.FindDue(ctx, frequency, runAt.Add(-interval), digestBatchSize)
+			if err != nil {
+				slog.Error("failed to find due saved searches", "frequency", frequency, "error", err)
+				break
+			}
+
+			processed := 0
+			for _, search := range searches {
+				if err = d.runSearch(ctx, search, runAt); err != nil {
+					slog.Error("failed to run saved search", "search_id", search.ID, "error", err)
+					continue
+				}
+				processed++
+			}
+			// Failed searches stay due, so stop instead of fetching them again.
+			if len(searches) < digestBatchSize || processed == 0 {
+				break
+			}
+		}
+	}
+}
+
+// runSearch looks for cards created between the previous run and runAt and
+// moves the search forward to runAt.
+func (d *DigestScheduler) runSearch(ctx context.Context, search *entity.SavedSearch, runAt time.Time) error {
+	filter := search.Filter
+	if filter.CreatedFrom.Before(search.LastRunAt) {
+		filter.CreatedFrom = search.LastRunAt
+	}
+	if filter.CreatedTo.IsZero() || filter.CreatedTo.After(runAt) {
+		filter.CreatedTo = runAt
+	}
+
+	if filter.CreatedFrom.Before(filter.CreatedTo) {
+		cards, err := d.cardRepo.FindAll(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		var titles []string
+		var cardID string
+		for _, card := range cards {
+			if card.Owner.ID == search.UserID {
+				continue
+			}
+			titles = append(titles, card.Title)
+			cardID = card.ID
+		}
+
+		if len(titles) > 0 {
+			if err = d.notifications.Publish(ctx, digestNotification(search, titles, cardID)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return d.searchRepo.MarkRun(ctx, search.ID, runAt)
+}
+
+func digestNotification(search *entity.SavedSearch, titles []string, cardID string) *entity.Notification {
+	n := &entity.Notification{
+		ID:     uuid.NewString(),
+		UserID: search.UserID,
+		Kind:   entity.NotificationSavedSearchDigest,
+		Title:  fmt.Sprintf("Новые объявления по поиску «%s»: %d", search.Name, len(titles)),
+	}
+	// A single card can be opened straight from the notification.
+	if len(titles) == 1 {
+		n.CardID = cardID
+	}
+
+	if len(titles) > digestMaxTitles {
+		n.Body = strings.Join(titles[:digestMaxTitles], "\n") + fmt.Sprintf("\nи еще %d", len(titles)-digestMaxTitles)
+	} else {
+		n.Body = strings.Join(titles, "\n")
+	}
+	return n
+}
+
+func NewDigestScheduler(searchRepo repository.SavedSearchRepo, cardRepo repository.CardRepo, notifications *NotificationService) *DigestScheduler {
+	return &DigestScheduler{
+		searchRepo:    searchRepo,
+		cardRepo:      cardRepo,
+		notifications: notifications,
+	}
+}
@@ -3,9 +3,14 @@ package service
 import (
 	e "LostAndFound/internal/common/errors"
 	"LostAndFound/internal/delivery/http/dto"
+	"LostAndFound/internal/domain/entity"
 	"LostAndFound/internal/domain/repository"
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
@@ -13,8 +18,33 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	visibilityPublic  = "public"
+	visibilityPrivate = "private"
+
+	privateKeyPrefix = "private/"
+	blurredKeyPrefix = "previews/blurred/"
+
+	// downloadURLExpiry is how long presigned GET URLs for private files
+	// and blurred previews stay valid.
+	downloadURLExpiry = 5 * time.Minute
+
+	// MaxUploadSize limits files uploaded directly through the API.
+	MaxUploadSize = 10 << 20
+)
+
+// uploadTypes lists the content types accepted for direct uploads, mapped to
+// the extension stored in the object key.
+var uploadTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
 type FileService struct {
-	repo repository.FileStorage
+	repo    repository.FileStorage
+	refRepo repository.FileRefRepo
 }
 
 func (f *FileService) GenerateUploadURL(ctx context.Context, userID string, req dto.FileRequest) (*dto.FileUploadResponse, error) {
@@ -22,25 +52,75 @@ func (f *FileService) GenerateUploadURL(ctx context.Context, userID string, req
 		return nil, fmt.Errorf("invalid file name")
 	}
 
-	ext := filepath.Ext(req.FileName)
-	key := fmt.Sprintf("users/%s/%s%s", userID, uuid.New().String(), ext)
+	visibility := normalizeVisibility(req.Visibility)
+	key := newFileKey(userID, filepath.Ext(req.FileName), visibility)
 
 	presignedURL, err := f.repo.GeneratePresignedPutURL(key, req.ContentType, 15*time.Minute)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate upload URL: %w", err)
 	}
 
-	publicURL := fmt.Sprintf("%s/%s/%s", f.repo.GetBaseURL(), f.repo.GetBucket(), key)
-
-	return &dto.FileUploadResponse{
+	resp := &dto.FileUploadResponse{
 		FileName:     req.FileName,
+		Key:          key,
+		Visibility:   visibility,
 		PresignedURL: presignedURL,
-		PublicURL:    publicURL,
-	}, nil
+	}
+	if visibility == visibilityPublic {
+		resp.PublicURL = publicURL(f.repo, key)
+	}
+
+	return resp, nil
+}
+
+// UploadFile streams a file sent through the API into storage. The content
+// type is sniffed from the first bytes instead of trusting the client.
+func (f *FileService) UploadFile(ctx context.Context, userID, fileName, visibility string, body io.Reader) (*dto.FileUploadResponse, error) {
+	if strings.Contains(fileName, "..") || strings.Contains(fileName, "/") {
+		return nil, fmt.Errorf("invalid file name")
+	}
+
+	limited := &limitedReader{r: body, n: MaxUploadSize}
+	buffered := bufio.NewReaderSize(limited, 512)
+
+	head, err := buffered.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(head) == 0 {
+		return nil, fmt.Errorf("empty file")
+	}
+
+	contentType := http.DetectContentType(head)
+	ext, ok := uploadTypes[contentType]
+	if !ok {
+		return nil, e.ErrUnsupportedFileType
+	}
+
+	visibility = normalizeVisibility(visibility)
+	key := newFileKey(userID, ext, visibility)
+
+	if err = f.repo.Put(ctx, key, contentType, buffered); err != nil {
+		if limited.exceeded {
+			return nil, e.ErrFileTooLarge
+		}
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	resp := &dto.FileUploadResponse{
+		FileName:   fileName,
+		Key:        key,
+		Visibility: visibility,
+	}
+	if visibility == visibilityPublic {
+		resp.PublicURL = publicURL(f.repo, key)
+	}
+
+	return resp, nil
 }
 
 func (f *FileService) DeleteFile(ctx context.Context, userID string, key string) error {
-	if !strings.HasPrefix(key, "users/"+userID+"/") {
+	if !ownsKey(userID, key) {
 		return fmt.Errorf("forbidden")
 	}
 
@@ -59,6 +139,125 @@ func (f *FileService) DeleteFile(ctx context.Context, userID string, key string)
 	return nil
 }
 
-func NewFileService(fileRepo repository.FileStorage) *FileService {
-	return &FileService{repo: fileRepo}
+// deleteFiles is the outbox consumer that removes the files a change left
+// unreferenced. Files that are already gone are skipped.
+func (f *FileService) deleteFiles(ctx context.Context, event *entity.DomainEvent) error {
+	for _, key := range event.DeletedFiles {
+		if err := f.repo.DeleteFile(ctx, key); err != nil && !errors.Is(err, e.ErrFileNotFound) {
+			return fmt.Errorf("failed to delete file %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// PurgeOrphanedFiles deletes the stored files that nothing in the database
+// refers to and returns their keys. Files younger than minAge are kept, since
+// they may have been uploaded for a card that is not saved yet. With dryRun
+// the files are only listed.
+func (f *FileService) PurgeOrphanedFiles(ctx context.Context, minAge time.Duration, dryRun bool) ([]string, error) {
+	refs, err := f.refRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool, 2*len(refs))
+	for _, ref := range refs {
+		if key, ok := objectKey(f.repo, ref); ok {
+			referenced[key] = true
+			referenced[blurredKey(key)] = true
+		}
+	}
+
+	files, err := f.repo.ListFiles(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-minAge)
+	var purged []string
+	for _, file := range files {
+		if referenced[file.Key] || file.LastModified.After(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err = f.repo.DeleteFile(ctx, file.Key); err != nil && !errors.Is(err, e.ErrFileNotFound) {
+				return purged, fmt.Errorf("failed to delete file %s: %w", file.Key, err)
+			}
+		}
+		purged = append(purged, file.Key)
+	}
+	return purged, nil
+}
+
+func NewFileService(fileRepo repository.FileStorage, refRepo repository.FileRefRepo) *FileService {
+	return &FileService{repo: fileRepo, refRepo: refRepo}
+}
+
+func normalizeVisibility(visibility string) string {
+	if visibility == visibilityPrivate {
+		return visibilityPrivate
+	}
+	return visibilityPublic
+}
+
+func newFileKey(userID, ext, visibility string) string {
+	key := fmt.Sprintf("users/%s/%s%s", userID, uuid.New().String(), ext)
+	if visibility == visibilityPrivate {
+		key = privateKeyPrefix + key
+	}
+	return key
+}
+
+// limitedReader fails the read once more than n bytes have been consumed,
+// unlike io.LimitReader which silently truncates.
+type limitedReader struct {
+	r        io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		l.exceeded = true
+		return 0, e.ErrFileTooLarge
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		l.exceeded = true
+		return n, e.ErrFileTooLarge
+	}
+	return n, err
+}
+
+func publicURL(repo repository.FileStorage, key string) string {
+	return fmt.Sprintf("%s/%s/%s", repo.GetBaseURL(), repo.GetBucket(), key)
+}
+
+// objectKey resolves a stored file reference, which is either a public URL
+// or a bare key of a private file, to its storage key. External URLs that
+// do not point to our bucket are reported with ok == false.
+func objectKey(repo repository.FileStorage, ref string) (key string, ok bool) {
+	if key, found := strings.CutPrefix(ref, publicURL(repo, "")); found {
+		return key, key != ""
+	}
+	if strings.HasPrefix(ref, "users/") || strings.HasPrefix(ref, privateKeyPrefix) {
+		return ref, true
+	}
+	return "", false
+}
+
+func isPrivateKey(key string) bool {
+	return strings.HasPrefix(key, privateKeyPrefix)
+}
+
+func ownsKey(userID, key string) bool {
+	key = strings.TrimPrefix(key, privateKeyPrefix)
+	return strings.HasPrefix(key, "users/"+userID+"/")
+}
+
+func blurredKey(key string) string {
+	return blurredKeyPrefix + strings.TrimSuffix(key, filepath.Ext(key)) + ".jpg"
 }
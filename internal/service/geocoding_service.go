@@ -0,0 +1,141 @@
+package service
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type GeocodingService struct {
+	geocoder repository.Geocoder
+}
+
+func (g *GeocodingService) Reverse(c context.Context, point entity.Location) (*entity.Address, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if !point.Valid() {
+		return nil, e.ErrInvalidLocation
+	}
+
+	addr, err := g.geocoder.Reverse(ctx, point, reverseGeocodeRadius)
+	if err != nil {
+		return nil, err
+	}
+	if addr == nil {
+		return nil, e.ErrAddressNotFound
+	}
+	return addr, nil
+}
+
+func (g *GeocodingService) Search(c context.Context, city, street string) (*entity.Address, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	return resolveAddress(ctx, g.geocoder, city, street)
+}
+
+// ImportGazetteer replaces the gazetteer with addresses read from CSV. The
+// header row names the columns: city, lat and lon are required, street and
+// house_number are optional. An OSM extract can be converted to this format
+// with ogr2ogr or osmium before importing.
+func (g *GeocodingService) ImportGazetteer(c context.Context, r io.Reader) (int, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Minute)
+	defer cancel()
+
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to read header: %w", e.ErrInvalidGazetteer, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"city", "lat", "lon"} {
+		if _, ok := columns[name]; !ok {
+			return 0, fmt.Errorf("%w: missing column %q", e.ErrInvalidGazetteer, name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var addresses []entity.Address
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", e.ErrInvalidGazetteer, err)
+		}
+
+		addr := entity.Address{
+			City:        field(record, "city"),
+			Street:      field(record, "street"),
+			HouseNumber: field(record, "house_number"),
+		}
+		lat, latErr := strconv.ParseFloat(field(record, "lat"), 64)
+		lon, lonErr := strconv.ParseFloat(field(record, "lon"), 64)
+		if addr.City == "" || latErr != nil || lonErr != nil ||
+			!(entity.Location{Latitude: lat, Longitude: lon}).Valid() {
+			return 0, fmt.Errorf("%w: invalid entry on line %d", e.ErrInvalidGazetteer, line)
+		}
+		addr.Latitude, addr.Longitude = lat, lon
+		addresses = append(addresses, addr)
+	}
+
+	if len(addresses) == 0 {
+		return 0, fmt.Errorf("%w: no entries", e.ErrInvalidGazetteer)
+	}
+	if err = g.geocoder.Import(ctx, addresses); err != nil {
+		return 0, err
+	}
+	return len(addresses), nil
+}
+
+// resolveAddress looks up the street line within the city and falls back to
+// the city itself when the street is unknown.
+func resolveAddress(ctx context.Context, geocoder repository.Geocoder, city, street string) (*entity.Address, error) {
+	if city == "" {
+		return nil, e.ErrAddressNotFound
+	}
+
+	if street != "" {
+		addr, err := geocoder.Forward(ctx, city, street)
+		if err != nil {
+			return nil, err
+		}
+		if addr != nil {
+			return addr, nil
+		}
+	}
+
+	addr, err := geocoder.Forward(ctx, city, "")
+	if err != nil {
+		return nil, err
+	}
+	if addr == nil {
+		return nil, e.ErrAddressNotFound
+	}
+	return addr, nil
+}
+
+func NewGeocodingService(geocoder repository.Geocoder) *GeocodingService {
+	return &GeocodingService{geocoder: geocoder}
+}
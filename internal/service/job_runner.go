@@ -0,0 +1,349 @@
+package service
+
+import (
+	"LostAndFound/internal/common/cron"
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// jobPollTick is how often an idle worker looks for due jobs.
+	jobPollTick = time.Second
+	// jobLeaseMargin is added to the job timeout, so that a job is not
+	// claimed again while its worker is still saving the result.
+	jobLeaseMargin = 30 * time.Second
+	// jobRetryBaseDelay is doubled after every failed attempt, up to
+	// jobMaxRetryDelay.
+	jobRetryBaseDelay = 10 * time.Second
+	jobMaxRetryDelay  = time.Hour
+	// scheduleLockTTL keeps the lock of a schedule slot long enough for the
+	// clocks of all instances to pass the slot.
+	scheduleLockTTL = 10 * time.Minute
+
+	defaultJobAttempts = 5
+	defaultJobTimeout  = 5 * time.Minute
+
+	// finishedJobRetention is how long done jobs, and with them their keys,
+	// are kept.
+	finishedJobRetention = 7 * 24 * time.Hour
+
+	defaultJobListLimit = 50
+	maxJobListLimit     = 500
+)
+
+const (
+	jobKindCleanup       = "jobs.cleanup"
+	jobKindDigest        = "saved_searches.digest"
+	jobKindWatchAreas    = "watch_areas.notify"
+	jobKindPurgeTrash    = "cards.purge_trash"
+	jobKindInspectImages = "cards.inspect_images"
+)
+
+// JobHandler does the work of a job. A job whose lease expired is run again,
+// so a handler has to be idempotent.
+type JobHandler func(ctx context.Context, job *entity.Job) error
+
+// JobOptions configures a kind of job. Zero values fall back to the
+// defaults.
+type JobOptions struct {
+	MaxAttempts int
+	Timeout     time.Duration
+}
+
+type jobKind struct {
+	handle JobHandler
+	opts   JobOptions
+}
+
+type jobSchedule struct {
+	kind     string
+	schedule cron.Schedule
+	next     time.Time
+}
+
+// JobRunner runs background jobs from the Postgres queue. Every instance
+// runs a pool of workers, while a periodic job is queued once per slot by
+// the instance that takes the slot lock.
+type JobRunner struct {
+	repo      repository.JobRepo
+	locker    repository.Locker
+	workers   int
+	kinds     map[string]jobKind
+	schedules []*jobSchedule
+}
+
+// Register adds a kind of job. All kinds have to be registered before Run.
+func (r *JobRunner) Register(kind string, handler JobHandler, opts JobOptions) {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultJobAttempts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultJobTimeout
+	}
+	r.kinds[kind] = jobKind{handle: handler, opts: opts}
+}
+
+// Schedule queues a registered kind of job on a cron schedule evaluated in
+// UTC.
+func (r *JobRunner) Schedule(kind, spec string) error {
+	if _, ok := r.kinds[kind]; !ok {
+		return fmt.Errorf("unknown job kind %q", kind)
+	}
+	schedule, err := cron.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule of %s: %w", kind, err)
+	}
+	r.schedules = append(r.schedules, &jobSchedule{kind: kind, schedule: schedule})
+	return nil
+}
+
+// Enqueue queues a job with the JSON encoded payload. A job with a key that
+// has already been queued is skipped.
+func (r *JobRunner) Enqueue(ctx context.Context, kind, key string, payload any) error {
+	k, ok := r.kinds[kind]
+	if !ok {
+		return fmt.Errorf("unknown job kind %q", kind)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	_, err = r.repo.Enqueue(ctx, &entity.Job{
+		ID:          uuid.NewString(),
+		Kind:        kind,
+		Key:         key,
+		Payload:     data,
+		MaxAttempts: k.opts.MaxAttempts,
+		RunAt:       time.Now(),
+	})
+	return err
+}
+
+// Run queues the scheduled jobs and runs the workers until ctx is done. It
+// returns once the jobs in progress have finished.
+func (r *JobRunner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.schedule(ctx)
+	}()
+
+	for range r.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (r *JobRunner) schedule(ctx context.Context) {
+	if len(r.schedules) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, s := range r.schedules {
+		s.next = s.schedule.Next(now)
+	}
+
+	for {
+		var wait time.Duration = -1
+		for _, s := range r.schedules {
+			if s.next.IsZero() {
+				continue
+			}
+			if d := time.Until(s.next); wait < 0 || d < wait {
+				wait = max(d, 0)
+			}
+		}
+		if wait < 0 {
+			return
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now = time.Now().UTC()
+		for _, s := range r.schedules {
+			if s.next.IsZero() || s.next.After(now) {
+				continue
+			}
+			r.enqueueSlot(ctx, s.kind, s.next)
+			// Slots missed while the process was busy are not caught up.
+			s.next = s.schedule.Next(now)
+		}
+	}
+}
+
+// enqueueSlot queues a periodic job unless another instance already took the
+// slot.
+func (r *JobRunner) enqueueSlot(ctx context.Context, kind string, slot time.Time) {
+	key := fmt.Sprintf("%s@%d", kind, slot.Unix())
+
+	ok, err := r.locker.TryLock(ctx, "jobs:"+key, scheduleLockTTL)
+	if err != nil {
+		slog.Error("failed to lock job schedule", "kind", kind, "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	if err = r.Enqueue(ctx, kind, key, nil); err != nil {
+		slog.Error("failed to enqueue scheduled job", "kind", kind, "error", err)
+	}
+}
+
+func (r *JobRunner) work(ctx context.Context) {
+	kinds := make([]string, 0, len(r.kinds))
+	var lease time.Duration
+	for kind, k := range r.kinds {
+		kinds = append(kinds, kind)
+		lease = max(lease, k.opts.Timeout)
+	}
+	lease += jobLeaseMargin
+
+	ticker := time.NewTicker(jobPollTick)
+	defer ticker.Stop()
+
+	for {
+		// Keep claiming while there is work, wait for the tick otherwise.
+		for ctx.Err() == nil {
+			now := time.Now()
+			jobs, err := r.repo.Claim(ctx, kinds, now, now.Add(lease), 1)
+			if err != nil {
+				slog.Error("failed to claim jobs", "error", err)
+				break
+			}
+			if len(jobs) == 0 {
+				break
+			}
+			r.run(ctx, jobs[0])
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run executes a claimed job. A started job is not interrupted by shutdown;
+// it runs until it finishes or times out.
+func (r *JobRunner) run(c context.Context, job *entity.Job) {
+	ctx := context.WithoutCancel(c)
+
+	k, ok := r.kinds[job.Kind]
+	if !ok {
+		job.LastError = "unknown job kind"
+		r.bury(ctx, job)
+		return
+	}
+
+	err := r.execute(ctx, k, job)
+	if err == nil {
+		if err = r.repo.Complete(ctx, job.ID); err != nil {
+			slog.Error("failed to complete job", "job_id", job.ID, "kind", job.Kind, "error", err)
+		}
+		return
+	}
+
+	job.LastError = err.Error()
+	if job.Attempts >= job.MaxAttempts {
+		r.bury(ctx, job)
+		return
+	}
+
+	delay := min(jobRetryBaseDelay<<min(job.Attempts-1, 20), jobMaxRetryDelay)
+	slog.Warn("job failed", "job_id", job.ID, "kind", job.Kind, "attempt", job.Attempts, "error", err)
+	if err = r.repo.Retry(ctx, job, time.Now().Add(delay)); err != nil {
+		slog.Error("failed to reschedule job", "job_id", job.ID, "kind", job.Kind, "error", err)
+	}
+}
+
+func (r *JobRunner) execute(c context.Context, k jobKind, job *entity.Job) (err error) {
+	ctx, cancel := context.WithTimeout(c, k.opts.Timeout)
+	defer cancel()
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+
+	return k.handle(ctx, job)
+}
+
+func (r *JobRunner) bury(ctx context.Context, job *entity.Job) {
+	slog.Error("job moved to dead letters", "job_id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", job.LastError)
+	if err := r.repo.Bury(ctx, job); err != nil {
+		slog.Error("failed to bury job", "job_id", job.ID, "kind", job.Kind, "error", err)
+	}
+}
+
+// ListJobs returns the queued jobs for inspection, newest first.
+func (r *JobRunner) ListJobs(ctx context.Context, filter entity.JobFilter) ([]*entity.Job, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultJobListLimit
+	}
+	filter.Limit = min(filter.Limit, maxJobListLimit)
+	return r.repo.FindAll(ctx, filter)
+}
+
+func (r *JobRunner) CountJobs(ctx context.Context) ([]entity.JobCount, error) {
+	return r.repo.Count(ctx)
+}
+
+// RequeueJob gives a dead job another full set of attempts.
+func (r *JobRunner) RequeueJob(ctx context.Context, id string) error {
+	if err := r.repo.Requeue(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// cleanup removes finished jobs after finishedJobRetention. Dead jobs are
+// kept for inspection.
+func (r *JobRunner) cleanup(ctx context.Context, _ *entity.Job) error {
+	n, err := r.repo.DeleteFinished(ctx, time.Now().Add(-finishedJobRetention))
+	if err != nil {
+		return err
+	}
+	slog.Info("deleted finished jobs", "count", n)
+	return nil
+}
+
+func NewJobRunner(repo repository.JobRepo, locker repository.Locker, workers int) *JobRunner {
+	return &JobRunner{
+		repo:    repo,
+		locker:  locker,
+		workers: workers,
+		kinds:   make(map[string]jobKind),
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+const (
+	// dispatchTick is how often pending deliveries are looked for.
+	dispatchTick = 5 * time.Second
+	// dispatchBatchSize is how many deliveries are claimed at once.
+	dispatchBatchSize = 20
+	// sendTimeout bounds a single attempt over one channel.
+	sendTimeout = 15 * time.Second
+	// maxDeliveryAttempts is how many times a delivery is tried before it is
+	// marked as failed.
+	maxDeliveryAttempts = 6
+	// retryBaseDelay is doubled after every failed attempt.
+	retryBaseDelay = time.Minute
+)
+
+// NotificationDispatcher sends pending deliveries over their channels,
+// retrying failures with exponential backoff and holding deliveries back
+// during the recipient's quiet hours.
+type NotificationDispatcher struct {
+	repo    repository.NotificationRepo
+	senders map[entity.NotificationChannel]repository.NotificationSender
+}
+
+// Run dispatches due deliveries every dispatchTick until ctx is done.
+func (d *NotificationDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(dispatchTick)
+	defer ticker.Stop()
+
+	for {
+		d.RunDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue dispatches every delivery whose next attempt is due.
+func (d *NotificationDispatcher) RunDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		// The claim outlives the worst case of sending the whole batch, so
+		// no other instance picks the same deliveries up meanwhile.
+		notifications, err := d.repo.ClaimDeliveries(ctx, now, now.Add(dispatchBatchSize*sendTimeout), dispatchBatchSize)
+		if err != nil {
+			slog.Error("failed to claim notification deliveries", "error", err)
+			return
+		}
+
+		recipients := make(map[string]*entity.Recipient)
+		for _, notification := range notifications {
+			delivery := &notification.Deliveries[0]
+			d.deliver(ctx, recipients, notification, delivery)
+			if err = d.repo.UpdateDelivery(ctx, delivery); err != nil {
+				slog.Error("failed to update notification delivery",
+					"notification_id", delivery.NotificationID, "channel", delivery.Channel, "error", err)
+			}
+		}
+
+		if len(notifications) < dispatchBatchSize {
+			return
+		}
+	}
+}
+
+// deliver makes one attempt and records its outcome in delivery.
+func (d *NotificationDispatcher) deliver(ctx context.Context, recipients map[string]*entity.Recipient, n *entity.Notification, delivery *entity.NotificationDelivery) {
+	now := time.Now()
+
+	recipient, ok := recipients[n.UserID]
+	if !ok {
+		var err error
+		recipient, err = d.repo.GetRecipient(ctx, n.UserID)
+		if errors.Is(err, sql.ErrNoRows) {
+			delivery.Status = entity.DeliveryFailed
+			delivery.LastError = "recipient not found"
+			return
+		}
+		if err != nil {
+			delivery.LastError = err.Error()
+			delivery.NextAttemptAt = now.Add(retryBaseDelay)
+			return
+		}
+		recipients[n.UserID] = recipient
+	}
+
+	if recipient.QuietHours != nil {
+		if until := recipient.QuietHours.Until(now); !until.IsZero() {
+			delivery.NextAttemptAt = until
+			return
+		}
+	}
+
+	sender, ok := d.senders[delivery.Channel]
+	if !ok {
+		delivery.Status = entity.DeliveryFailed
+		delivery.LastError = "channel is not configured"
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	delivery.Attempts++
+	err := sender.Send(sendCtx, recipient, n)
+	switch {
+	case err == nil:
+		delivery.Status = entity.DeliverySent
+		delivery.LastError = ""
+		delivery.SentAt = &now
+	case errors.Is(err, e.ErrRecipientUnreachable) || delivery.Attempts >= maxDeliveryAttempts:
+		delivery.Status = entity.DeliveryFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(retryBaseDelay << (delivery.Attempts - 1))
+	}
+	if err != nil {
+		slog.Warn("notification delivery failed", "notification_id", n.ID, "channel", delivery.Channel,
+			"attempt", delivery.Attempts, "error", err)
+	}
+}
+
+func NewNotificationDispatcher(repo repository.NotificationRepo, senders []repository.NotificationSender) *NotificationDispatcher {
+	byChannel := make(map[entity.NotificationChannel]repository.NotificationSender, len(senders))
+	for _, sender := range senders {
+		byChannel[sender.Channel()] = sender
+	}
+
+	return &NotificationDispatcher{
+		repo:    repo,
+		senders: byChannel,
+	}
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultNotificationsLimit = 50
+	maxNotificationsLimit     = 100
+	// maxPushSubscriptions is how many browsers a single user may register.
+	maxPushSubscriptions = 10
+)
+
+// defaultNotificationChannels are used for users who never changed their
+// preferences.
+var defaultNotificationChannels = []entity.NotificationChannel{entity.ChannelEmail}
+
+// NotificationService keeps the in-app inbox and fans notifications out to
+// the external channels each user has enabled.
+type NotificationService struct {
+	repo          repository.NotificationRepo
+	senders       map[entity.NotificationChannel]repository.NotificationSender
+	pushPublicKey string
+}
+
+// Publish stores the notifications in the inbox along with a pending
+// delivery for every enabled channel of their recipients.
+func (n *NotificationService) Publish(ctx context.Context, notifications ...*entity.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	userIDs := make([]string, 0, len(notifications))
+	for _, notification := range notifications {
+		if !slices.Contains(userIDs, notification.UserID) {
+			userIDs = append(userIDs, notification.UserID)
+		}
+	}
+	prefs, err := n.repo.FindPreferences(ctx, userIDs...)
+	if err != nil {
+		return err
+	}
+	channels := make(map[string][]entity.NotificationChannel, len(prefs))
+	for _, p := range prefs {
+		channels[p.UserID] = p.Channels
+	}
+
+	now := time.Now()
+	for _, notification := range notifications {
+		userChannels, ok := channels[notification.UserID]
+		if !ok {
+			userChannels = defaultNotificationChannels
+		}
+		notification.Deliveries = nil
+		for _, channel := range userChannels {
+			if _, ok = n.senders[channel]; !ok {
+				continue
+			}
+			notification.Deliveries = append(notification.Deliveries, entity.NotificationDelivery{
+				NotificationID: notification.ID,
+				Channel:        channel,
+				Status:         entity.DeliveryPending,
+				NextAttemptAt:  now,
+			})
+		}
+	}
+
+	return n.repo.Create(ctx, notifications...)
+}
+
+func (n *NotificationService) GetNotifications(c context.Context, userID string, unreadOnly bool, limit, offset int) ([]*entity.Notification, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if limit <= 0 {
+		limit = defaultNotificationsLimit
+	}
+	limit = min(limit, maxNotificationsLimit)
+	offset = max(offset, 0)
+
+	return n.repo.FindByUser(ctx, userID, unreadOnly, limit, offset)
+}
+
+func (n *NotificationService) CountUnread(c context.Context, userID string) (int, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	return n.repo.CountUnread(ctx, userID)
+}
+
+func (n *NotificationService) MarkRead(c context.Context, userID, id string) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := n.repo.MarkRead(ctx, userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (n *NotificationService) MarkAllRead(c context.Context, userID string) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	return n.repo.MarkAllRead(ctx, userID)
+}
+
+func (n *NotificationService) GetPreferences(c context.Context, userID string) (*entity.NotificationPreferences, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	prefs, err := n.repo.FindPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(prefs) == 0 {
+		return &entity.NotificationPreferences{
+			UserID:   userID,
+			Channels: slices.Clone(defaultNotificationChannels),
+		}, nil
+	}
+	return prefs[0], nil
+}
+
+func (n *NotificationService) UpdatePreferences(c context.Context, prefs *entity.NotificationPreferences) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	for i, channel := range prefs.Channels {
+		if _, ok := n.senders[channel]; !ok {
+			return fmt.Errorf("%w: channel %q is not available", e.ErrInvalidPreferences, channel)
+		}
+		if slices.Contains(prefs.Channels[:i], channel) {
+			return fmt.Errorf("%w: channel %q is listed twice", e.ErrInvalidPreferences, channel)
+		}
+	}
+
+	if q := prefs.QuietHours; q != nil {
+		if q.Start < 0 || q.Start >= 24*60 || q.End < 0 || q.End >= 24*60 || q.Start == q.End {
+			return fmt.Errorf("%w: quiet hours must start and end at different times of day", e.ErrInvalidPreferences)
+		}
+		if _, err := time.LoadLocation(q.TimeZone); err != nil || q.TimeZone == "" {
+			return fmt.Errorf("%w: unknown time zone %q", e.ErrInvalidPreferences, q.TimeZone)
+		}
+	}
+
+	return n.repo.SavePreferences(ctx, prefs)
+}
+
+// AvailableChannels lists the external channels configured on the server.
+func (n *NotificationService) AvailableChannels() []entity.NotificationChannel {
+	channels := make([]entity.NotificationChannel, 0, len(n.senders))
+	for channel := range n.senders {
+		channels = append(channels, channel)
+	}
+	slices.Sort(channels)
+	return channels
+}
+
+func (n *NotificationService) SubscribePush(c context.Context, sub *entity.PushSubscription) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if _, ok := n.senders[entity.ChannelWebPush]; !ok {
+		return fmt.Errorf("%w: web push is not enabled", e.ErrInvalidPushSubscription)
+	}
+	if err := checkPushSubscription(sub); err != nil {
+		return err
+	}
+
+	recipient, err := n.repo.GetRecipient(ctx, sub.UserID)
+	if err != nil {
+		return err
+	}
+	registered := false
+	for _, existing := range recipient.PushSubscriptions {
+		registered = registered || existing.Endpoint == sub.Endpoint
+	}
+	if !registered && len(recipient.PushSubscriptions) >= maxPushSubscriptions {
+		return fmt.Errorf("%w: at most %d push subscriptions are allowed", e.ErrInvalidPushSubscription, maxPushSubscriptions)
+	}
+
+	if sub.ID == "" {
+		sub.ID = uuid.NewString()
+	}
+	return n.repo.SavePushSubscription(ctx, sub)
+}
+
+func (n *NotificationService) UnsubscribePush(c context.Context, userID, id string) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := n.repo.DeletePushSubscription(ctx, userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// PushPublicKey is the VAPID key browsers subscribe with; it is empty when
+// web push is not configured.
+func (n *NotificationService) PushPublicKey() string {
+	return n.pushPublicKey
+}
+
+// checkPushSubscription validates a subscription the way the browser Push
+// API produces it: an https endpoint, an uncompressed P-256 key and a 16 byte
+// auth secret, both base64url encoded.
+func checkPushSubscription(sub *entity.PushSubscription) error {
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an https url", e.ErrInvalidPushSubscription)
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(sub.P256dh, "="))
+	if err != nil || len(key) != 65 || key[0] != 4 {
+		return fmt.Errorf("%w: invalid p256dh key", e.ErrInvalidPushSubscription)
+	}
+	auth, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(sub.Auth, "="))
+	if err != nil || len(auth) != 16 {
+		return fmt.Errorf("%w: invalid auth secret", e.ErrInvalidPushSubscription)
+	}
+	return nil
+}
+
+func NewNotificationService(repo repository.NotificationRepo, senders []repository.NotificationSender, pushPublicKey string) *NotificationService {
+	byChannel := make(map[entity.NotificationChannel]repository.NotificationSender, len(senders))
+	for _, sender := range senders {
+		byChannel[sender.Channel()] = sender
+	}
+
+	return &NotificationService{
+		repo:          repo,
+		senders:       byChannel,
+		pushPublicKey: pushPublicKey,
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// notifyTimeout bounds the evaluation of a new card.
+const notifyTimeout = 30 * time.Second
+
+// Notifier tells users about new cards in their watch areas.
+type Notifier struct {
+	watchAreaRepo repository.WatchAreaRepo
+	notifications *NotificationService
+	jobs          *JobRunner
+}
+
+// cardCreated queues the evaluation of a new card against the stored watch
+// areas. It consumes card.created events; the event ID keeps a redelivered
+// event from being evaluated twice.
+func (n *Notifier) cardCreated(ctx context.Context, event *entity.DomainEvent) error {
+	return n.jobs.Enqueue(ctx, jobKindWatchAreas, jobKindWatchAreas+":"+event.ID, event.Card)
+}
+
+// run is the job that stores a notification for every user whose watch
+// area matches the card.
+func (n *Notifier) run(ctx context.Context, job *entity.Job) error {
+	var card entity.Card
+	if err := json.Unmarshal(job.Payload, &card); err != nil {
+		return fmt.Errorf("failed to decode card: %w", err)
+	}
+	return n.notifyWatchers(ctx, &card)
+}
+
+func (n *Notifier) notifyWatchers(ctx context.Context, card *entity.Card) error {
+	areas, err := n.watchAreaRepo.FindMatching(ctx, card)
+	if err != nil {
+		return err
+	}
+	if len(areas) == 0 {
+		return nil
+	}
+
+	notifications := make([]*entity.Notification, 0, len(areas))
+	for _, area := range areas {
+		notifications = append(notifications, &entity.Notification{
+			ID:     uuid.NewString(),
+			UserID: area.UserID,
+			Kind:   entity.NotificationWatchAreaMatch,
+			Title:  fmt.Sprintf("Новое объявление в зоне «%s»", area.Name),
+			Body:   card.Title,
+			CardID: card.ID,
+		})
+	}
+
+	return n.notifications.Publish(ctx, notifications...)
+}
+
+func NewNotifier(watchAreaRepo repository.WatchAreaRepo, notifications *NotificationService, jobs *JobRunner) *Notifier {
+	return &Notifier{
+		watchAreaRepo: watchAreaRepo,
+		notifications: notifications,
+		jobs:          jobs,
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// relayTick is how often the outbox is polled when no change on this
+	// instance woke the relay up.
+	relayTick = 2 * time.Second
+	// relayBatchSize is how many events are claimed at once.
+	relayBatchSize = 50
+	// relayTimeout bounds handing one event to all consumers.
+	relayTimeout = 30 * time.Second
+	// relayRetryBaseDelay is doubled after every failed attempt, up to
+	// relayMaxRetryDelay. Events are never dropped.
+	relayRetryBaseDelay = 5 * time.Second
+	relayMaxRetryDelay  = time.Hour
+)
+
+// EventHandler consumes a domain event. Delivery is at least once, so a
+// handler may see the same event again and has to be idempotent.
+type EventHandler func(ctx context.Context, event *entity.DomainEvent) error
+
+// OutboxRelay hands the events written to the outbox over to the Redis event
+// stream and to the in-process consumers. An event is removed from the
+// outbox only after all of them succeeded; otherwise the whole event is
+// retried with backoff.
+type OutboxRelay struct {
+	repo     repository.OutboxRepo
+	stream   repository.EventStream
+	handlers map[entity.EventType][]EventHandler
+	wake     chan struct{}
+}
+
+// Handle registers a consumer of the event types. Consumers are called in
+// the order they were registered; all of them have to be registered before
+// Run.
+func (r *OutboxRelay) Handle(handler EventHandler, types ...entity.EventType) {
+	for _, t := range types {
+		r.handlers[t] = append(r.handlers[t], handler)
+	}
+}
+
+// Notify wakes the relay up after a change was committed, so that the
+// consumers do not wait for the next tick.
+func (r *OutboxRelay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run relays due events until ctx is done.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(relayTick)
+	defer ticker.Stop()
+
+	for {
+		r.RunDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// RunDue relays every event whose next attempt is due.
+func (r *OutboxRelay) RunDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		events, err := r.repo.ClaimEvents(ctx, now, now.Add(relayBatchSize*relayTimeout), relayBatchSize)
+		if err != nil {
+			slog.Error("failed to claim outbox events", "error", err)
+			return
+		}
+
+		for _, event := range events {
+			if err = r.relay(ctx, event); err != nil {
+				event.Attempts++
+				event.LastError = err.Error()
+				delay := min(relayRetryBaseDelay<<min(event.Attempts-1, 20), relayMaxRetryDelay)
+				slog.Warn("failed to relay domain event", "event_id", event.ID, "type", event.Type,
+					"attempt", event.Attempts, "error", err)
+
+				if err = r.repo.Retry(ctx, event, time.Now().Add(delay)); err != nil {
+					slog.Error("failed to reschedule domain event", "event_id", event.ID, "error", err)
+				}
+				continue
+			}
+
+			if err = r.repo.Complete(ctx, event.ID); err != nil {
+				slog.Error("failed to complete domain event", "event_id", event.ID, "error", err)
+			}
+		}
+
+		if len(events) < relayBatchSize {
+			return
+		}
+	}
+}
+
+func (r *OutboxRelay) relay(c context.Context, event *entity.DomainEvent) error {
+	ctx, cancel := context.WithTimeout(c, relayTimeout)
+	defer cancel()
+
+	if err := r.stream.Append(ctx, event); err != nil {
+		return fmt.Errorf("failed to append to event stream: %w", err)
+	}
+	for _, handle := range r.handlers[event.Type] {
+		if err := handle(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewOutboxRelay(repo repository.OutboxRepo, stream repository.EventStream) *OutboxRelay {
+	return &OutboxRelay{
+		repo:     repo,
+		stream:   stream,
+		handlers: make(map[entity.EventType][]EventHandler),
+		wake:     make(chan struct{}, 1),
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// placeParentKinds lists which kinds of place each kind may be nested in.
+// Zones such as a library or a canteen can be a part of a building or a
+// standalone area of a campus.
+var placeParentKinds = map[entity.PlaceKind][]entity.PlaceKind{
+	entity.PlaceBuilding: {entity.PlaceCampus},
+	entity.PlaceZone:     {entity.PlaceCampus, entity.PlaceBuilding},
+}
+
+var placeDepth = map[entity.PlaceKind]int{
+	entity.PlaceCampus:   0,
+	entity.PlaceBuilding: 1,
+	entity.PlaceZone:     2,
+}
+
+type PlaceService struct {
+	repo   repository.PlaceRepo
+	outbox *OutboxRelay
+}
+
+// GetPlaceTree returns the campuses with their buildings and zones nested
+// under them.
+func (p *PlaceService) GetPlaceTree(c context.Context, withGeometry bool) ([]*entity.Place, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	places, err := p.repo.FindAll(ctx, withGeometry)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*entity.Place, len(places))
+	for _, place := range places {
+		byID[place.ID] = place
+	}
+
+	var roots []*entity.Place
+	for _, place := range places {
+		if parent, ok := byID[place.ParentID]; ok {
+			parent.Children = append(parent.Children, place)
+			continue
+		}
+		roots = append(roots, place)
+	}
+
+	return roots, nil
+}
+
+func (p *PlaceService) CreatePlace(c context.Context, place *entity.Place) error {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	if err := p.checkPlace(ctx, place); err != nil {
+		return err
+	}
+	if err := p.repo.Create(ctx, place); err != nil {
+		return err
+	}
+	p.outbox.Notify()
+	return nil
+}
+
+func (p *PlaceService) UpdatePlace(c context.Context, place *entity.Place) error {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	if _, err := p.repo.GetByID(ctx, place.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return err
+	}
+	if place.ParentID == place.ID {
+		return fmt.Errorf("%w: place cannot be its own parent", e.ErrInvalidPlace)
+	}
+	if err := p.checkPlace(ctx, place); err != nil {
+		return err
+	}
+
+	if err := p.repo.Update(ctx, place); err != nil {
+		return err
+	}
+	p.outbox.Notify()
+	return nil
+}
+
+func (p *PlaceService) DeletePlace(c context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	if err := p.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return err
+	}
+	p.outbox.Notify()
+	return nil
+}
+
+// ImportPlaces creates places from a GeoJSON FeatureCollection. Every feature
+// needs "name" and "kind" properties; buildings and zones may reference their
+// parent by name in "parent", either among existing places or within the same
+// file. The whole collection is imported in one transaction.
+func (p *PlaceService) ImportPlaces(c context.Context, data []byte) ([]*entity.Place, error) {
+	ctx, cancel := context.WithTimeout(c, 30*time.Second)
+	defer cancel()
+
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry   json.RawMessage `json:"geometry"`
+			Properties struct {
+				Name   string `json:"name"`
+				Kind   string `json:"kind"`
+				Parent string `json:"parent"`
+			} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &collection); err != nil || collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%w: expected a GeoJSON FeatureCollection", e.ErrInvalidPlace)
+	}
+
+	existing, err := p.repo.FindAll(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*entity.Place, len(existing))
+	for _, place := range existing {
+		byName[place.Name] = place
+	}
+
+	places := make([]*entity.Place, 0, len(collection.Features))
+	parents := make(map[*entity.Place]string, len(collection.Features))
+	for i, f := range collection.Features {
+		place := &entity.Place{
+			ID:       uuid.NewString(),
+			Kind:     entity.PlaceKind(f.Properties.Kind),
+			Name:     f.Properties.Name,
+			Geometry: f.Geometry,
+		}
+		if place.Name == "" {
+			return nil, fmt.Errorf("%w: feature %d has no name", e.ErrInvalidPlace, i)
+		}
+		if _, ok := byName[place.Name]; ok {
+			return nil, fmt.Errorf("%w: place %q already exists", e.ErrInvalidPlace, place.Name)
+		}
+		byName[place.Name] = place
+		parents[place] = f.Properties.Parent
+		places = append(places, place)
+	}
+
+	for _, place := range places {
+		if name := parents[place]; name != "" {
+			parent, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: parent %q of %q not found", e.ErrInvalidPlace, name, place.Name)
+			}
+			place.ParentID = parent.ID
+		}
+		if err = validatePlace(place, byName[parents[place]]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Parents have to be inserted before the places nested in them.
+	sort.SliceStable(places, func(i, j int) bool {
+		return placeDepth[places[i].Kind] < placeDepth[places[j].Kind]
+	})
+	if err = p.repo.Create(ctx, places...); err != nil {
+		return nil, err
+	}
+	p.outbox.Notify()
+	return places, nil
+}
+
+func (p *PlaceService) checkPlace(ctx context.Context, place *entity.Place) error {
+	var parent *entity.Place
+	if place.ParentID != "" {
+		var err error
+		parent, err = p.repo.GetByID(ctx, place.ParentID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: parent place not found", e.ErrInvalidPlace)
+			}
+			return err
+		}
+	}
+	return validatePlace(place, parent)
+}
+
+// validatePlace checks the place kind, its nesting and that the geometry is a
+// GeoJSON polygon.
+func validatePlace(place, parent *entity.Place) error {
+	switch place.Kind {
+	case entity.PlaceCampus, entity.PlaceBuilding, entity.PlaceZone:
+	default:
+		return fmt.Errorf("%w: unknown kind %q", e.ErrInvalidPlace, place.Kind)
+	}
+
+	if parent == nil {
+		if place.Kind != entity.PlaceCampus {
+			return fmt.Errorf("%w: %s %q needs a parent", e.ErrInvalidPlace, place.Kind, place.Name)
+		}
+	} else if !slices.Contains(placeParentKinds[place.Kind], parent.Kind) {
+		return fmt.Errorf("%w: %s %q cannot be placed in %s %q", e.ErrInvalidPlace, place.Kind, place.Name, parent.Kind, parent.Name)
+	}
+
+	var geometry struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(place.Geometry, &geometry); err != nil ||
+		(geometry.Type != "Polygon" && geometry.Type != "MultiPolygon") {
+		return fmt.Errorf("%w: geometry of %q must be a Polygon or MultiPolygon", e.ErrInvalidPlace, place.Name)
+	}
+	return nil
+}
+
+func NewPlaceService(repo repository.PlaceRepo, outbox *OutboxRelay) *PlaceService {
+	return &PlaceService{repo: repo, outbox: outbox}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxSavedSearches is how many saved searches a single user may have.
+const maxSavedSearches = 20
+
+type SavedSearchService struct {
+	repo            repository.SavedSearchRepo
+	maxSearchRadius entity.Distance
+}
+
+func (s *SavedSearchService) GetSavedSearches(c context.Context, userID string) ([]*entity.SavedSearch, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	return s.repo.FindByUser(ctx, userID)
+}
+
+func (s *SavedSearchService) CreateSavedSearch(c context.Context, search *entity.SavedSearch) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := s.checkSavedSearch(search); err != nil {
+		return err
+	}
+
+	n, err := s.repo.CountByUser(ctx, search.UserID)
+	if err != nil {
+		return err
+	}
+	if n >= maxSavedSearches {
+		return fmt.Errorf("%w: at most %d saved searches are allowed", e.ErrInvalidSavedSearch, maxSavedSearches)
+	}
+
+	// Only cards created from now on are reported.
+	search.LastRunAt = time.Now()
+	return s.repo.Create(ctx, search)
+}
+
+func (s *SavedSearchService) UpdateSavedSearch(c context.Context, search *entity.SavedSearch) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := s.checkSavedSearch(search); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, search); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return fmt.Errorf("failed to update saved search: %w", err)
+	}
+	return nil
+}
+
+func (s *SavedSearchService) DeleteSavedSearch(c context.Context, userID, id string) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SavedSearchService) checkSavedSearch(search *entity.SavedSearch) error {
+	switch search.Frequency {
+	case entity.DigestInstant, entity.DigestDaily:
+	default:
+		return fmt.Errorf("%w: unknown frequency %q", e.ErrInvalidSavedSearch, search.Frequency)
+	}
+	return checkCardFilter(search.Filter, s.maxSearchRadius)
+}
+
+func NewSavedSearchService(repo repository.SavedSearchRepo, maxSearchRadius entity.Distance) *SavedSearchService {
+	return &SavedSearchService{repo: repo, maxSearchRadius: maxSearchRadius}
+}
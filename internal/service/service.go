@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"io"
+	"sync"
+	"time"
 
 	"LostAndFound/internal/auth"
 	"LostAndFound/internal/bootstrap"
+	server_config "LostAndFound/internal/config/server_config"
 	"LostAndFound/internal/delivery/http/dto"
 	"LostAndFound/internal/domain/entity"
 )
@@ -13,25 +17,108 @@ type Auth interface {
 	Register(ctx context.Context, u *entity.User) error
 	Login(ctx context.Context, email, password string) (string, error)
 	Logout(ctx context.Context, token string) error
+	CreateStreamTicket(ctx context.Context, userID, role string) (*entity.StreamTicket, error)
 }
 
 type Users interface {
 	GetProfile(ctx context.Context, userID string) (*entity.User, error)
 	UpdateProfile(ctx context.Context, u *entity.User) error
+	PatchProfile(ctx context.Context, userID string, version int, apply func(user *entity.User) error) error
+	SetAvatar(ctx context.Context, userID, key string, version int) (*entity.User, error)
+	DeleteAvatar(ctx context.Context, userID string, version int) error
 }
 
 type Cards interface {
-	CreateCard(ctx context.Context, l *entity.Card) error
+	CreateCard(ctx context.Context, l *entity.Card) ([]*entity.SimilarCard, error)
 	GetCardByID(ctx context.Context, id string) (*entity.Card, error)
-	GetAllCards(ctx context.Context, filter string) ([]*entity.Card, error)
+	GetAllCards(ctx context.Context, filter entity.CardFilter) ([]*entity.Card, error)
 	UpdateCard(ctx context.Context, l *entity.Card) error
-	DeleteCard(ctx context.Context, id string) error
-	GetCardsNear(ctx context.Context, lat, lon, radius float64, status string) ([]*entity.Card, error)
+	PatchCard(ctx context.Context, id, userID string, version int, apply func(card *entity.Card) error) error
+	GetCardHistory(ctx context.Context, cardID string) ([]*entity.CardRevision, error)
+	RevertCard(ctx context.Context, cardID string, number int) error
+	DeleteCard(ctx context.Context, id string, version int) error
+	GetTrash(ctx context.Context, userID string) ([]*entity.Card, error)
+	RestoreCard(ctx context.Context, id string) error
+	GetCardsNear(ctx context.Context, filter entity.CardFilter) ([]*entity.Card, error)
+	GetSimilarImages(ctx context.Context, id string, maxDistance int) ([]*entity.SimilarCard, error)
+	GetCardsTile(ctx context.Context, tile entity.Tile) ([]byte, error)
+	GetMapView(ctx context.Context, bbox entity.BoundingBox, zoom int, filter entity.CardFilter) (*entity.MapView, error)
+	ReorderImages(ctx context.Context, cardID string, imageIDs []string) error
+	UpdateImage(ctx context.Context, cardID, imageID string, caption *string, makeCover bool) error
+	RemoveImage(ctx context.Context, cardID, imageID string) error
+	ReindexCards(ctx context.Context) (int, int, error)
+}
+
+type Places interface {
+	GetPlaceTree(ctx context.Context, withGeometry bool) ([]*entity.Place, error)
+	CreatePlace(ctx context.Context, p *entity.Place) error
+	UpdatePlace(ctx context.Context, p *entity.Place) error
+	DeletePlace(ctx context.Context, id string) error
+	ImportPlaces(ctx context.Context, data []byte) ([]*entity.Place, error)
+}
+
+type Geocoding interface {
+	Reverse(ctx context.Context, point entity.Location) (*entity.Address, error)
+	Search(ctx context.Context, city, street string) (*entity.Address, error)
+	ImportGazetteer(ctx context.Context, r io.Reader) (int, error)
+}
+
+type WatchAreas interface {
+	GetWatchAreas(ctx context.Context, userID string) ([]*entity.WatchArea, error)
+	CreateWatchArea(ctx context.Context, area *entity.WatchArea) error
+	UpdateWatchArea(ctx context.Context, area *entity.WatchArea) error
+	DeleteWatchArea(ctx context.Context, userID, id string) error
+}
+
+type SavedSearches interface {
+	GetSavedSearches(ctx context.Context, userID string) ([]*entity.SavedSearch, error)
+	CreateSavedSearch(ctx context.Context, search *entity.SavedSearch) error
+	UpdateSavedSearch(ctx context.Context, search *entity.SavedSearch) error
+	DeleteSavedSearch(ctx context.Context, userID, id string) error
+}
+
+type Notifications interface {
+	GetNotifications(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*entity.Notification, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID, id string) error
+	MarkAllRead(ctx context.Context, userID string) error
+	GetPreferences(ctx context.Context, userID string) (*entity.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, prefs *entity.NotificationPreferences) error
+	AvailableChannels() []entity.NotificationChannel
+	SubscribePush(ctx context.Context, sub *entity.PushSubscription) error
+	UnsubscribePush(ctx context.Context, userID, id string) error
+	PushPublicKey() string
+}
+
+type Webhooks interface {
+	CreateWebhook(ctx context.Context, webhook *entity.Webhook) error
+	GetWebhooks(ctx context.Context) ([]*entity.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *entity.Webhook) error
+	DeleteWebhook(ctx context.Context, id string) error
+	GetDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*entity.WebhookDelivery, error)
+	ReplayDelivery(ctx context.Context, webhookID, deliveryID string) (*entity.WebhookDelivery, error)
+}
+
+type Telegram interface {
+	CreateLink(ctx context.Context, userID string) (*entity.TelegramLink, error)
+	Link(ctx context.Context, code string, chatID int64) (*entity.User, error)
+	Unlink(ctx context.Context, userID string) error
+	GetLinkedUser(ctx context.Context, chatID int64) (*entity.User, error)
 }
 
 type Files interface {
 	GenerateUploadURL(ctx context.Context, userID string, req dto.FileRequest) (*dto.FileUploadResponse, error)
+	UploadFile(ctx context.Context, userID, fileName, visibility string, body io.Reader) (*dto.FileUploadResponse, error)
 	DeleteFile(ctx context.Context, userID, key string) error
+	PurgeOrphanedFiles(ctx context.Context, minAge time.Duration, dryRun bool) ([]string, error)
+}
+
+type Admin interface {
+	CreateAdmin(ctx context.Context, user *entity.User) error
+	SetAdmin(ctx context.Context, email string, isAdmin bool) error
+	BanUser(ctx context.Context, email string) error
+	UnbanUser(ctx context.Context, email string) error
+	ExportData(ctx context.Context, w io.Writer) error
 }
 
 type Cache interface {
@@ -44,16 +131,104 @@ type Service struct {
 	Auth
 	Users
 	Cards
+	Places
+	Geocoding
+	WatchAreas
+	SavedSearches
+	Notifications
+	Webhooks
+	Telegram
 	Files
+	Admin
 	Cache
+
+	Jobs              *JobRunner
+	Dispatcher        *NotificationDispatcher
+	Feed              *CardFeed
+	WebhookDispatcher *WebhookDispatcher
+	Outbox            *OutboxRelay
 }
 
-func NewService(deps *bootstrap.Deps, tm *auth.TokenManager) *Service {
+func NewService(deps *bootstrap.Deps, tm *auth.TokenManager, cfg *server_config.Config) *Service {
+	maxSearchRadius := entity.Distance(cfg.Search.MaxRadiusKm) * entity.Kilometer
+
+	notifications := NewNotificationService(deps.NotificationRepo, deps.NotificationSenders, deps.PushPublicKey)
+	jobs := NewJobRunner(deps.JobRepo, deps.Locker, cfg.Jobs.Workers)
+	notifier := NewNotifier(deps.WatchAreaRepo, notifications, jobs)
+	digests := NewDigestScheduler(deps.SavedSearchRepo, deps.CardRepo, notifications)
+	outbox := NewOutboxRelay(deps.OutboxRepo, deps.EventStream)
+
+	cards := NewCardService(deps.CardRepo, deps.UserRepo, deps.CacheRepo, deps.FileStore, deps.Geocoder, outbox, jobs, notifications, maxSearchRadius, cfg.Trash.Retention)
+	files := NewFileService(deps.FileStore, deps.FileRefRepo)
+	webhooks := NewWebhookService(deps.WebhookRepo)
+	feed := NewCardFeed(deps.CardEvents)
+
+	cardEvents := []entity.EventType{entity.EventCardCreated, entity.EventCardUpdated, entity.EventCardDeleted, entity.EventCardRestored}
+	outbox.Handle(cards.invalidateCache, append(cardEvents, entity.EventCardImagesChanged, entity.EventCardPurged)...)
+	outbox.Handle(files.deleteFiles, entity.EventCardPurged, entity.EventCardImagesChanged, entity.EventUserUpdated)
+	outbox.Handle(webhooks.Enqueue, cardEvents...)
+	outbox.Handle(feed.publish, cardEvents...)
+	outbox.Handle(notifier.cardCreated, entity.EventCardCreated)
+	outbox.Handle(cards.queueImageInspection, entity.EventCardCreated, entity.EventCardUpdated)
+
+	jobs.Register(jobKindWatchAreas, notifier.run, JobOptions{Timeout: notifyTimeout})
+	jobs.Register(jobKindDigest, digests.run, JobOptions{MaxAttempts: 1})
+	jobs.Register(jobKindCleanup, jobs.cleanup, JobOptions{})
+	jobs.Register(jobKindPurgeTrash, cards.purgeTrash, JobOptions{})
+	jobs.Register(jobKindInspectImages, cards.inspectCardImages, JobOptions{})
+	mustSchedule(jobs, jobKindDigest, digestSchedule)
+	mustSchedule(jobs, jobKindCleanup, "0 3 * * *")
+	mustSchedule(jobs, jobKindPurgeTrash, "@hourly")
+
+	authService := NewAuthService(deps.UserRepo, deps.CacheRepo, tm)
+
 	return &Service{
-		Auth:  NewAuthService(deps.UserRepo, deps.CacheRepo, tm),
-		Users: NewUserService(deps.UserRepo),
-		Cards: NewCardService(deps.CardRepo, deps.UserRepo, deps.CacheRepo, deps.FileStore),
-		Files: NewFileService(deps.FileStore),
-		Cache: NewCacheService(deps.CacheRepo),
+		Auth:          authService,
+		Users:         NewUserService(deps.UserRepo, deps.FileStore, outbox),
+		Cards:         cards,
+		Places:        NewPlaceService(deps.PlaceRepo, outbox),
+		Geocoding:     NewGeocodingService(deps.Geocoder),
+		WatchAreas:    NewWatchAreaService(deps.WatchAreaRepo, deps.PlaceRepo),
+		SavedSearches: NewSavedSearchService(deps.SavedSearchRepo, maxSearchRadius),
+		Notifications: notifications,
+		Webhooks:      webhooks,
+		Telegram:      NewTelegramService(deps.UserRepo, deps.CacheRepo, cfg.Notifications.Telegram.BotUsername),
+		Files:         files,
+		Admin:         NewAdminService(deps.UserRepo, deps.CardRepo, deps.CacheRepo, authService, tm.TokenTTL),
+		Cache:         NewCacheService(deps.CacheRepo),
+
+		Jobs:              jobs,
+		Dispatcher:        NewNotificationDispatcher(deps.NotificationRepo, deps.NotificationSenders),
+		Feed:              feed,
+		Outbox:            outbox,
+		WebhookDispatcher: NewWebhookDispatcher(deps.WebhookRepo),
+	}
+}
+
+// RunBackground runs the job runner, the notification and webhook
+// dispatchers and the outbox relay until ctx is done. It returns once the
+// jobs in progress have finished.
+func (s *Service) RunBackground(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, run := range []func(context.Context){
+		s.Jobs.Run,
+		s.Dispatcher.Run,
+		s.Outbox.Run,
+		s.WebhookDispatcher.Run,
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// mustSchedule panics on a schedule that does not parse, which is a
+// programming error.
+func mustSchedule(jobs *JobRunner, kind, spec string) {
+	if err := jobs.Schedule(kind, spec); err != nil {
+		panic(err)
 	}
 }
@@ -0,0 +1,109 @@
+package service
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// telegramLinkTTL is how long a link code can be used.
+const telegramLinkTTL = 15 * time.Minute
+
+// TelegramService links Telegram chats to accounts, so that the bot can act
+// on behalf of the user and notifications can be delivered to the chat.
+type TelegramService struct {
+	userRepo    repository.UserRepo
+	cache       repository.CacheRepo
+	botUsername string
+}
+
+// CreateLink issues a code the user passes to the bot with /start.
+func (t *TelegramService) CreateLink(c context.Context, userID string) (*entity.TelegramLink, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	// Deep-link payloads allow only [A-Za-z0-9_-].
+	code := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := t.cache.SaveTelegramLinkCode(ctx, code, userID, telegramLinkTTL); err != nil {
+		return nil, fmt.Errorf("failed to save link code: %w", err)
+	}
+
+	link := &entity.TelegramLink{
+		Code:      code,
+		ExpiresAt: time.Now().Add(telegramLinkTTL),
+	}
+	if t.botUsername != "" {
+		link.URL = fmt.Sprintf("https://t.me/%s?start=%s", t.botUsername, code)
+	}
+	return link, nil
+}
+
+// Link attaches the chat to the account the code was issued for.
+func (t *TelegramService) Link(c context.Context, code string, chatID int64) (*entity.User, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	userID, err := t.cache.TakeTelegramLinkCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, e.ErrInvalidLinkCode
+	}
+
+	if err = t.userRepo.SetTelegramChatID(ctx, userID, chatID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, e.ErrInvalidLinkCode
+		}
+		return nil, err
+	}
+	return t.userRepo.FindByID(ctx, userID)
+}
+
+func (t *TelegramService) Unlink(c context.Context, userID string) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := t.userRepo.SetTelegramChatID(ctx, userID, 0); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetLinkedUser returns the account linked to the chat.
+func (t *TelegramService) GetLinkedUser(c context.Context, chatID int64) (*entity.User, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	user, err := t.userRepo.FindByTelegramChatID(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, e.ErrNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func NewTelegramService(userRepo repository.UserRepo, cache repository.CacheRepo, botUsername string) *TelegramService {
+	return &TelegramService{
+		userRepo:    userRepo,
+		cache:       cache,
+		botUsername: botUsername,
+	}
+}
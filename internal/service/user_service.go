@@ -2,17 +2,30 @@ package service
 
 import (
 	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/common/imaging"
 	"LostAndFound/internal/domain/entity"
 	"LostAndFound/internal/domain/repository"
+	"bytes"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"image"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	avatarSize      = 256
+	avatarThumbSize = 64
+)
+
 type UserService struct {
-	repo repository.UserRepo
+	repo     repository.UserRepo
+	fileRepo repository.FileStorage
+	outbox   *OutboxRelay
 }
 
 func (u *UserService) GetProfile(c context.Context, userID string) (*entity.User, error) {
@@ -30,56 +43,238 @@ func (u *UserService) GetProfile(c context.Context, userID string) (*entity.User
 		Telegram:  user.Telegram,
 		CreatedAt: user.CreatedAt,
 		IsAdmin:   user.IsAdmin,
+		Version:   user.Version,
+
+		AvatarURL:      user.AvatarURL,
+		AvatarThumbURL: user.AvatarThumbURL,
 	}, nil
 }
 
+// UpdateProfile changes the fields set in updated and keeps the rest; an
+// empty value means "unchanged".
 func (u *UserService) UpdateProfile(ctx context.Context, updated *entity.User) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	currentUser, err := u.repo.FindByID(ctx, updated.ID)
+	current, err := u.editableProfile(ctx, updated.ID, updated.Version)
 	if err != nil {
-		return e.ErrNotFound
+		return err
+	}
+
+	next := *current
+	next.Password = updated.Password
+	if updated.Email != "" {
+		next.Email = updated.Email
+	}
+	if updated.Name != "" {
+		next.Name = updated.Name
+	}
+	if updated.Surname != "" {
+		next.Surname = updated.Surname
+	}
+	if updated.Phone != "" {
+		next.Phone = updated.Phone
+	}
+	if updated.Telegram != "" {
+		next.Telegram = updated.Telegram
 	}
 
-	changed := false
+	return u.replaceProfile(ctx, current, &next)
+}
 
-	if len(updated.Email) != 0 && currentUser.Email != updated.Email {
-		currentUser.Email = updated.Email
-		changed = true
+// PatchProfile lets apply edit a copy of the profile and saves what it turns
+// the profile into. The copy has no password; one set by apply becomes the
+// new password. An error from apply is returned as is.
+func (u *UserService) PatchProfile(ctx context.Context, userID string, version int, apply func(user *entity.User) error) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	current, err := u.editableProfile(ctx, userID, version)
+	if err != nil {
+		return err
 	}
-	if len(updated.Name) != 0 && currentUser.Name != updated.Name {
-		currentUser.Name = updated.Name
-		changed = true
+
+	edited := *current
+	edited.Password = ""
+	if err = apply(&edited); err != nil {
+		return err
 	}
-	if len(updated.Surname) != 0 && currentUser.Surname != updated.Surname {
-		currentUser.Surname = updated.Surname
-		changed = true
+
+	next := *current
+	next.Email, next.Password = edited.Email, edited.Password
+	next.Name, next.Surname = edited.Name, edited.Surname
+	next.Phone, next.Telegram = edited.Phone, edited.Telegram
+
+	return u.replaceProfile(ctx, current, &next)
+}
+
+// editableProfile loads a profile for an update. A non-zero version has to
+// match the current one.
+func (u *UserService) editableProfile(ctx context.Context, userID string, version int) (*entity.User, error) {
+	current, err := u.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, e.ErrNotFound
 	}
-	if len(updated.Phone) != 0 && currentUser.Phone != updated.Phone {
-		currentUser.Phone = updated.Phone
-		changed = true
+	if version != 0 && version != current.Version {
+		return nil, e.ErrVersionMismatch
 	}
-	if len(updated.Telegram) != 0 && currentUser.Telegram != updated.Telegram {
-		currentUser.Telegram = updated.Telegram
-		changed = true
+	return current, nil
+}
+
+// replaceProfile saves next in place of current. next.Password is a new
+// password in plain text, or empty to keep the current one.
+func (u *UserService) replaceProfile(ctx context.Context, current, next *entity.User) error {
+	changed := next.Email != current.Email ||
+		next.Name != current.Name ||
+		next.Surname != current.Surname ||
+		next.Phone != current.Phone ||
+		next.Telegram != current.Telegram ||
+		next.Password != ""
+	if !changed {
+		return e.ErrNoChanges
 	}
-	if updated.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updated.Password), bcrypt.DefaultCost)
+
+	if next.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(next.Password), bcrypt.DefaultCost)
 		if err != nil {
 			return fmt.Errorf("bcrypt hashing failed: %w", err)
 		}
-		currentUser.Password = string(hashedPassword)
-		changed = true
+		next.Password = string(hashedPassword)
+	} else {
+		next.Password = current.Password
 	}
 
-	if !changed {
+	if err := u.repo.Update(ctx, next); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrVersionMismatch
+		}
+		return err
+	}
+	return nil
+}
+
+// SetAvatar turns a previously uploaded file into the user's avatar: the
+// image is cropped to a square and stored in two sizes, after which the
+// previous avatar is removed from storage. The source upload is kept, since
+// a card may show it too; once nothing refers to it, the orphaned file purge
+// removes it. A non-zero version has to match the current one.
+func (u *UserService) SetAvatar(c context.Context, userID, key string, version int) (*entity.User, error) {
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	if !ownsKey(userID, key) {
+		return nil, e.ErrPermissionDenied
+	}
+
+	user, err := u.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, e.ErrNotFound
+	}
+	if version != 0 && version != user.Version {
+		return nil, e.ErrVersionMismatch
+	}
+
+	body, err := u.fileRepo.GetFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	img, err := imaging.Decode(body)
+	body.Close()
+	if err != nil {
+		return nil, e.ErrUnsupportedFileType
+	}
+
+	square := imaging.CropSquare(img)
+	base := fmt.Sprintf("users/%s/avatars/%s", userID, uuid.New().String())
+
+	largeKey, err := u.putAvatar(ctx, square, base, avatarSize)
+	if err != nil {
+		return nil, err
+	}
+	thumbKey, err := u.putAvatar(ctx, square, base, avatarThumbSize)
+	if err != nil {
+		_ = u.fileRepo.DeleteFile(ctx, largeKey)
+		return nil, err
+	}
+
+	oldURLs := []string{user.AvatarURL, user.AvatarThumbURL}
+	user.AvatarURL = publicURL(u.fileRepo, largeKey)
+	user.AvatarThumbURL = publicURL(u.fileRepo, thumbKey)
+
+	if err = u.repo.Update(ctx, user, u.newFilesEvent(userID, oldURLs...)); err != nil {
+		_ = u.fileRepo.DeleteFile(ctx, largeKey)
+		_ = u.fileRepo.DeleteFile(ctx, thumbKey)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, e.ErrVersionMismatch
+		}
+		return nil, fmt.Errorf("failed to update avatar: %w", err)
+	}
+	u.outbox.Notify()
+
+	return user, nil
+}
+
+func (u *UserService) DeleteAvatar(c context.Context, userID string, version int) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	user, err := u.repo.FindByID(ctx, userID)
+	if err != nil {
+		return e.ErrNotFound
+	}
+	if version != 0 && version != user.Version {
+		return e.ErrVersionMismatch
+	}
+	if user.AvatarURL == "" && user.AvatarThumbURL == "" {
 		return e.ErrNoChanges
 	}
 
-	return u.repo.Update(ctx, currentUser)
+	oldURLs := []string{user.AvatarURL, user.AvatarThumbURL}
+	user.AvatarURL = ""
+	user.AvatarThumbURL = ""
+
+	if err = u.repo.Update(ctx, user, u.newFilesEvent(userID, oldURLs...)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrVersionMismatch
+		}
+		return fmt.Errorf("failed to delete avatar: %w", err)
+	}
+	u.outbox.Notify()
+
+	return nil
+}
+
+func (u *UserService) putAvatar(ctx context.Context, square image.Image, base string, size int) (string, error) {
+	var buf bytes.Buffer
+	if err := imaging.EncodeJPEG(&buf, imaging.Resize(square, size, size)); err != nil {
+		return "", fmt.Errorf("failed to encode avatar: %w", err)
+	}
+
+	key := fmt.Sprintf("%s_%d.jpg", base, size)
+	if err := u.fileRepo.Put(ctx, key, "image/jpeg", &buf); err != nil {
+		return "", fmt.Errorf("failed to upload avatar: %w", err)
+	}
+	return key, nil
+}
+
+// newFilesEvent prepares an outbox event that removes the files the update
+// of the user leaves unreferenced. The files are deleted by a consumer
+// once the update is committed.
+func (u *UserService) newFilesEvent(userID string, refs ...string) *entity.DomainEvent {
+	event := &entity.DomainEvent{
+		ID:          uuid.NewString(),
+		Type:        entity.EventUserUpdated,
+		AggregateID: userID,
+		OccurredAt:  time.Now(),
+	}
+	for _, ref := range refs {
+		if key, ok := objectKey(u.fileRepo, ref); ok {
+			event.DeletedFiles = append(event.DeletedFiles, key)
+		}
+	}
+	return event
 }
 
-func NewUserService(userRepo repository.UserRepo) *UserService {
-	return &UserService{repo: userRepo}
+func NewUserService(userRepo repository.UserRepo, fileRepo repository.FileStorage, outbox *OutboxRelay) *UserService {
+	return &UserService{repo: userRepo, fileRepo: fileRepo, outbox: outbox}
 }
@@ -0,0 +1,113 @@
+package service
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// maxWatchAreas is how many watch areas a single user may have.
+	maxWatchAreas = 20
+	// maxWatchAreaRadius bounds circular watch areas.
+	maxWatchAreaRadius = 10 * entity.Kilometer
+)
+
+type WatchAreaService struct {
+	repo      repository.WatchAreaRepo
+	placeRepo repository.PlaceRepo
+}
+
+func (w *WatchAreaService) GetWatchAreas(c context.Context, userID string) ([]*entity.WatchArea, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	return w.repo.FindByUser(ctx, userID)
+}
+
+func (w *WatchAreaService) CreateWatchArea(c context.Context, area *entity.WatchArea) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := w.checkWatchArea(ctx, area); err != nil {
+		return err
+	}
+
+	n, err := w.repo.CountByUser(ctx, area.UserID)
+	if err != nil {
+		return err
+	}
+	if n >= maxWatchAreas {
+		return fmt.Errorf("%w: at most %d watch areas are allowed", e.ErrInvalidWatchArea, maxWatchAreas)
+	}
+
+	return w.repo.Create(ctx, area)
+}
+
+func (w *WatchAreaService) UpdateWatchArea(c context.Context, area *entity.WatchArea) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := w.checkWatchArea(ctx, area); err != nil {
+		return err
+	}
+
+	if err := w.repo.Update(ctx, area); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return fmt.Errorf("failed to update watch area: %w", err)
+	}
+	return nil
+}
+
+func (w *WatchAreaService) DeleteWatchArea(c context.Context, userID, id string) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := w.repo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// checkWatchArea makes sure the area is either a valid circle or an existing
+// place.
+func (w *WatchAreaService) checkWatchArea(ctx context.Context, area *entity.WatchArea) error {
+	if (area.Center == nil) == (area.PlaceID == "") {
+		return fmt.Errorf("%w: specify either a point with a radius or a place", e.ErrInvalidWatchArea)
+	}
+
+	if area.Center != nil {
+		if !area.Center.Valid() {
+			return e.ErrInvalidLocation
+		}
+		if area.Radius <= 0 || area.Radius > maxWatchAreaRadius {
+			return fmt.Errorf("%w: radius must be positive and at most %g km", e.ErrInvalidWatchArea, maxWatchAreaRadius.Kilometers())
+		}
+		return nil
+	}
+
+	place, err := w.placeRepo.GetByID(ctx, area.PlaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: place not found", e.ErrInvalidWatchArea)
+		}
+		return err
+	}
+	area.PlaceName = place.Name
+	area.Radius = 0
+	return nil
+}
+
+func NewWatchAreaService(repo repository.WatchAreaRepo, placeRepo repository.PlaceRepo) *WatchAreaService {
+	return &WatchAreaService{repo: repo, placeRepo: placeRepo}
+}
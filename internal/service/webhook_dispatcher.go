@@ -0,0 +1,174 @@
+package service
+
+import (
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// webhookTimeout bounds a single request to a webhook.
+	webhookTimeout = 10 * time.Second
+	// maxWebhookAttempts is how many times a delivery is tried before it is
+	// marked as failed; with retryBaseDelay the last retry comes about two
+	// hours after the event.
+	maxWebhookAttempts = 8
+	// maxWebhookErrorBody is how much of an error response is kept in the
+	// delivery log.
+	maxWebhookErrorBody = 512
+)
+
+// WebhookDispatcher posts pending webhook deliveries, retrying failures with
+// exponential backoff. Every request is signed with the webhook secret:
+//
+//	X-Webhook-Signature: t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+type WebhookDispatcher struct {
+	repo   repository.WebhookRepo
+	client *http.Client
+}
+
+// Run dispatches due deliveries every dispatchTick until ctx is done.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(dispatchTick)
+	defer ticker.Stop()
+
+	for {
+		d.RunDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue dispatches every delivery whose next attempt is due.
+func (d *WebhookDispatcher) RunDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		deliveries, err := d.repo.ClaimDeliveries(ctx, now, now.Add(dispatchBatchSize*webhookTimeout), dispatchBatchSize)
+		if err != nil {
+			slog.Error("failed to claim webhook deliveries", "error", err)
+			return
+		}
+
+		webhooks := make(map[string]*entity.Webhook)
+		for _, delivery := range deliveries {
+			d.deliver(ctx, webhooks, delivery)
+			if err = d.repo.UpdateDelivery(ctx, delivery); err != nil {
+				slog.Error("failed to update webhook delivery", "delivery_id", delivery.ID, "error", err)
+			}
+		}
+
+		if len(deliveries) < dispatchBatchSize {
+			return
+		}
+	}
+}
+
+// deliver makes one attempt and records its outcome in delivery.
+func (d *WebhookDispatcher) deliver(ctx context.Context, webhooks map[string]*entity.Webhook, delivery *entity.WebhookDelivery) {
+	now := time.Now()
+
+	webhook, ok := webhooks[delivery.WebhookID]
+	if !ok {
+		var err error
+		webhook, err = d.repo.GetByID(ctx, delivery.WebhookID)
+		if errors.Is(err, sql.ErrNoRows) {
+			delivery.Status = entity.DeliveryFailed
+			delivery.LastError = "webhook not found"
+			return
+		}
+		if err != nil {
+			delivery.LastError = err.Error()
+			delivery.NextAttemptAt = now.Add(retryBaseDelay)
+			return
+		}
+		webhooks[delivery.WebhookID] = webhook
+	}
+	if !webhook.Active {
+		delivery.Status = entity.DeliveryFailed
+		delivery.LastError = "webhook is disabled"
+		return
+	}
+
+	delivery.Attempts++
+	status, err := d.post(ctx, webhook, delivery)
+	delivery.ResponseStatus = status
+	switch {
+	case err == nil:
+		delivery.Status = entity.DeliverySent
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case status == http.StatusGone || delivery.Attempts >= maxWebhookAttempts:
+		delivery.Status = entity.DeliveryFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(retryBaseDelay << (delivery.Attempts - 1))
+	}
+	if err != nil {
+		slog.Warn("webhook delivery failed", "delivery_id", delivery.ID, "webhook_id", webhook.ID,
+			"attempt", delivery.Attempts, "error", err)
+	}
+}
+
+// post sends the payload and returns the response status, 0 if there was no
+// response.
+func (d *WebhookDispatcher) post(c context.Context, webhook *entity.Webhook, delivery *entity.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(c, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LostAndFound-Webhooks")
+	req.Header.Set("X-Webhook-Delivery", delivery.ID)
+	req.Header.Set("X-Webhook-Event", string(delivery.EventType))
+	req.Header.Set("X-Webhook-Signature", "t="+timestamp+",v1="+signWebhook(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorBody))
+	return resp.StatusCode, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+func signWebhook(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func NewWebhookDispatcher(repo repository.WebhookRepo) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:   repo,
+		client: &http.Client{},
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	e "LostAndFound/internal/common/errors"
+	"LostAndFound/internal/domain/entity"
+	"LostAndFound/internal/domain/repository"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 100
+)
+
+var webhookEventTypes = []entity.CardEventType{entity.CardCreated, entity.CardUpdated, entity.CardDeleted, entity.CardRestored}
+
+// webhookPayload is the body posted to webhooks. Images and owner contacts
+// are left out: receivers are external systems and fetch the card through
+// the API if they need more.
+type webhookPayload struct {
+	ID         string               `json:"id"`
+	Type       entity.CardEventType `json:"type"`
+	OccurredAt time.Time            `json:"occurred_at"`
+	Card       webhookCard          `json:"card"`
+}
+
+type webhookCard struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	Category    string    `json:"category"`
+	Latitude    *float64  `json:"latitude"`
+	Longitude   *float64  `json:"longitude"`
+	City        string    `json:"city"`
+	Street      string    `json:"street"`
+	PlaceID     string    `json:"place_id,omitempty"`
+	PlaceName   string    `json:"place_name,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WebhookService manages the webhooks registered by admins and queues card
+// events for them. Deliveries are sent by WebhookDispatcher.
+type WebhookService struct {
+	repo repository.WebhookRepo
+}
+
+// CreateWebhook registers the webhook with a new signing secret.
+func (s *WebhookService) CreateWebhook(c context.Context, webhook *entity.Webhook) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	webhook.ID = uuid.NewString()
+	webhook.Secret = hex.EncodeToString(raw)
+
+	return s.repo.Create(ctx, webhook)
+}
+
+func (s *WebhookService) GetWebhooks(c context.Context) ([]*entity.Webhook, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	return s.repo.FindAll(ctx)
+}
+
+func (s *WebhookService) UpdateWebhook(c context.Context, webhook *entity.Webhook) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, webhook); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *WebhookService) DeleteWebhook(c context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetDeliveries returns the delivery log of the webhook, newest first.
+func (s *WebhookService) GetDeliveries(c context.Context, webhookID string, limit, offset int) ([]*entity.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if _, err := s.repo.GetByID(ctx, webhookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, e.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultWebhookDeliveriesLimit
+	}
+	limit = min(limit, maxWebhookDeliveriesLimit)
+	offset = max(offset, 0)
+
+	return s.repo.FindDeliveries(ctx, webhookID, limit, offset)
+}
+
+// ReplayDelivery sends the payload of an earlier delivery once more as a new
+// delivery, keeping the original in the log.
+func (s *WebhookService) ReplayDelivery(c context.Context, webhookID, deliveryID string) (*entity.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	delivery, err := s.repo.Replay(ctx, webhookID, deliveryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, e.ErrNotFound
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Enqueue is the outbox consumer that stores a delivery of a card event for
+// every subscribed webhook. The outbox event id becomes the event id of the
+// deliveries, so an event relayed twice is queued only once.
+func (s *WebhookService) Enqueue(ctx context.Context, event *entity.DomainEvent) error {
+	card := event.Card
+	payload := webhookPayload{
+		ID:         event.ID,
+		Type:       entity.CardEventType(event.Type),
+		OccurredAt: event.OccurredAt,
+		Card: webhookCard{
+			ID:          card.ID,
+			Title:       card.Title,
+			Description: card.Description,
+			Status:      string(card.Status),
+			Category:    string(card.Category),
+			City:        card.City,
+			Street:      card.Street,
+			PlaceID:     card.PlaceID,
+			PlaceName:   card.PlaceName,
+			CreatedAt:   card.CreatedAt,
+		},
+	}
+	if card.Location != nil {
+		payload.Card.Latitude = &card.Location.Latitude
+		payload.Card.Longitude = &card.Location.Longitude
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return s.repo.Enqueue(ctx, payload.ID, payload.Type, data)
+}
+
+func validateWebhook(webhook *entity.Webhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", e.ErrInvalidWebhook)
+	}
+	for _, event := range webhook.Events {
+		if !slices.Contains(webhookEventTypes, event) {
+			return fmt.Errorf("%w: unknown event %q", e.ErrInvalidWebhook, event)
+		}
+	}
+	return nil
+}
+
+func NewWebhookService(repo repository.WebhookRepo) *WebhookService {
+	return &WebhookService{repo: repo}
+}
@@ -0,0 +1,3 @@
+ALTER TABLE cards
+    ADD COLUMN IF NOT EXISTS category TEXT NOT NULL DEFAULT 'other'
+        CHECK (category IN ('documents', 'electronics', 'clothing', 'accessories', 'keys', 'bags', 'other'));
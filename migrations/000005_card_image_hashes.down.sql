@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_card_images_card_id;
+
+ALTER TABLE card_images DROP COLUMN IF EXISTS phash;
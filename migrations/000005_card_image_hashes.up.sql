@@ -0,0 +1,3 @@
+ALTER TABLE card_images ADD COLUMN IF NOT EXISTS phash BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_card_images_card_id ON card_images (card_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_card_images_cover;
+
+ALTER TABLE card_images
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS caption,
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS is_cover;
@@ -0,0 +1,25 @@
+ALTER TABLE card_images
+    ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS caption  TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS width    INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS height   INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS is_cover BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE card_images ci
+SET position = numbered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY card_id ORDER BY id) - 1 AS position
+    FROM card_images
+) numbered
+WHERE numbered.id = ci.id;
+
+UPDATE card_images
+SET is_cover = TRUE
+WHERE id IN (
+    SELECT DISTINCT ON (ci.card_id) ci.id
+    FROM card_images ci
+    JOIN cards c ON c.id = ci.card_id
+    ORDER BY ci.card_id, (ci.url = c.preview_url) DESC, ci.position
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_card_images_cover ON card_images (card_id) WHERE is_cover;
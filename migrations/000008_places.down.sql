@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_cards_place_id;
+
+ALTER TABLE cards DROP COLUMN IF EXISTS place_id;
+
+DROP TABLE IF EXISTS places CASCADE;
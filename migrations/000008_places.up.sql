@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS places
+(
+    id              UUID                           PRIMARY KEY,
+    parent_id       UUID                           REFERENCES places (id) ON DELETE CASCADE,
+    kind            TEXT                           NOT NULL CHECK (kind IN ('campus', 'building', 'zone')),
+    name            TEXT                           NOT NULL CHECK (trim(name) <> ''),
+    geom            geography(MultiPolygon, 4326)  NOT NULL,
+    created_at      TIMESTAMP                      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_places_geom ON places USING GIST (geom);
+CREATE INDEX IF NOT EXISTS idx_places_parent_id ON places (parent_id);
+
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS place_id UUID REFERENCES places (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_cards_place_id ON cards (place_id);
@@ -0,0 +1,14 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS gazetteer
+(
+    id              BIGSERIAL                 PRIMARY KEY,
+    city            TEXT                      NOT NULL CHECK (trim(city) <> ''),
+    street          TEXT                      NOT NULL DEFAULT '',
+    house_number    TEXT                      NOT NULL DEFAULT '',
+    location        geography(Point, 4326)    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_gazetteer_location ON gazetteer USING GIST (location);
+CREATE INDEX IF NOT EXISTS idx_gazetteer_city ON gazetteer (lower(city));
+CREATE INDEX IF NOT EXISTS idx_gazetteer_street_trgm ON gazetteer USING GIN (lower(street || ' ' || house_number) gin_trgm_ops);
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS watch_areas
+(
+    id              UUID                      PRIMARY KEY,
+    user_id         UUID                      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name            TEXT                      NOT NULL CHECK (trim(name) <> ''),
+    center          geography(Point, 4326),
+    radius_m        DOUBLE PRECISION,
+    place_id        UUID                      REFERENCES places (id) ON DELETE CASCADE,
+    category        TEXT,
+    status          TEXT                      CHECK (status IN ('lost', 'found')),
+    created_at      TIMESTAMP                 NOT NULL DEFAULT NOW(),
+    CHECK (
+        (center IS NOT NULL AND radius_m > 0 AND place_id IS NULL) OR
+        (center IS NULL AND radius_m IS NULL AND place_id IS NOT NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_watch_areas_user_id ON watch_areas (user_id);
+CREATE INDEX IF NOT EXISTS idx_watch_areas_center ON watch_areas USING GIST (center);
+CREATE INDEX IF NOT EXISTS idx_watch_areas_place_id ON watch_areas (place_id);
+
+CREATE TABLE IF NOT EXISTS notifications
+(
+    id              UUID                      PRIMARY KEY,
+    user_id         UUID                      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind            TEXT                      NOT NULL,
+    title           TEXT                      NOT NULL,
+    body            TEXT                      NOT NULL DEFAULT '',
+    card_id         UUID                      REFERENCES cards (id) ON DELETE CASCADE,
+    created_at      TIMESTAMP                 NOT NULL DEFAULT NOW(),
+    read_at         TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id, created_at DESC);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS saved_searches
+(
+    id              UUID                      PRIMARY KEY,
+    user_id         UUID                      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name            TEXT                      NOT NULL CHECK (trim(name) <> ''),
+    query           JSONB                     NOT NULL,
+    frequency       TEXT                      NOT NULL CHECK (frequency IN ('instant', 'daily')),
+    last_run_at     TIMESTAMP                 NOT NULL,
+    created_at      TIMESTAMP                 NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches (user_id);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_due ON saved_searches (frequency, last_run_at);
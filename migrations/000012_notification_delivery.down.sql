@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS push_subscriptions;
+
+DROP TABLE IF EXISTS notification_deliveries;
+
+DROP TABLE IF EXISTS notification_preferences;
+
+DROP INDEX IF EXISTS idx_notifications_unread;
+
+ALTER TABLE users DROP COLUMN IF EXISTS telegram_chat_id;
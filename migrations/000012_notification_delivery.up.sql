@@ -0,0 +1,43 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS telegram_chat_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notification_preferences
+(
+    user_id         UUID                      PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    channels        TEXT[]                    NOT NULL DEFAULT '{}',
+    quiet_start     SMALLINT                  CHECK (quiet_start BETWEEN 0 AND 1439),
+    quiet_end       SMALLINT                  CHECK (quiet_end BETWEEN 0 AND 1439),
+    time_zone       TEXT,
+    updated_at      TIMESTAMP                 NOT NULL DEFAULT NOW(),
+    CHECK (
+        (quiet_start IS NULL AND quiet_end IS NULL AND time_zone IS NULL) OR
+        (quiet_start IS NOT NULL AND quiet_end IS NOT NULL AND time_zone IS NOT NULL)
+    )
+);
+
+CREATE TABLE IF NOT EXISTS notification_deliveries
+(
+    notification_id UUID                      NOT NULL REFERENCES notifications (id) ON DELETE CASCADE,
+    channel         TEXT                      NOT NULL CHECK (channel IN ('email', 'telegram', 'webpush')),
+    status          TEXT                      NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts        INT                       NOT NULL DEFAULT 0,
+    last_error      TEXT                      NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP                 NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMP,
+    PRIMARY KEY (notification_id, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due ON notification_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS push_subscriptions
+(
+    id              UUID                      PRIMARY KEY,
+    user_id         UUID                      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    endpoint        TEXT                      NOT NULL UNIQUE,
+    p256dh          TEXT                      NOT NULL,
+    auth            TEXT                      NOT NULL,
+    created_at      TIMESTAMP                 NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user_id ON push_subscriptions (user_id);
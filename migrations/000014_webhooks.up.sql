@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    id              UUID                      PRIMARY KEY,
+    url             TEXT                      NOT NULL,
+    secret          TEXT                      NOT NULL,
+    events          TEXT[]                    NOT NULL DEFAULT '{}',
+    description     TEXT                      NOT NULL DEFAULT '',
+    active          BOOLEAN                   NOT NULL DEFAULT TRUE,
+    created_at      TIMESTAMP                 NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              UUID                      PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id      UUID                      NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        UUID                      NOT NULL,
+    event_type      TEXT                      NOT NULL,
+    payload         JSONB                     NOT NULL,
+    status          TEXT                      NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts        INT                       NOT NULL DEFAULT 0,
+    response_status INT                       NOT NULL DEFAULT 0,
+    last_error      TEXT                      NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP                 NOT NULL DEFAULT NOW(),
+    delivered_at    TIMESTAMP,
+    created_at      TIMESTAMP                 NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
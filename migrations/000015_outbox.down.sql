@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_event;
+
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS replay_of;
+
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS outbox_events
+(
+    id              UUID                      PRIMARY KEY,
+    type            TEXT                      NOT NULL,
+    aggregate_id    TEXT                      NOT NULL,
+    payload         JSONB                     NOT NULL,
+    occurred_at     TIMESTAMP                 NOT NULL,
+    attempts        INT                       NOT NULL DEFAULT 0,
+    last_error      TEXT                      NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP                 NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_due ON outbox_events (next_attempt_at, occurred_at);
+
+-- The relay may hand an event to the webhooks more than once; the original
+-- deliveries are unique per event, replays are not.
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS replay_of UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_event ON webhook_deliveries (webhook_id, event_id) WHERE replay_of IS NULL;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS jobs
+(
+    id              UUID                      PRIMARY KEY,
+    kind            TEXT                      NOT NULL,
+    key             TEXT,
+    payload         JSONB                     NOT NULL DEFAULT 'null',
+    status          TEXT                      NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'dead')),
+    attempts        INT                       NOT NULL DEFAULT 0,
+    max_attempts    INT                       NOT NULL,
+    last_error      TEXT                      NOT NULL DEFAULT '',
+    run_at          TIMESTAMP                 NOT NULL DEFAULT NOW(),
+    locked_until    TIMESTAMP,
+    created_at      TIMESTAMP                 NOT NULL DEFAULT NOW(),
+    finished_at     TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs (run_at) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_jobs_running ON jobs (locked_until) WHERE status = 'running';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_key ON jobs (key);
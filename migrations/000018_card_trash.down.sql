@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_cards_deleted_at;
+
+ALTER TABLE cards DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_cards_deleted_at ON cards (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS card_revisions
+(
+    id          UUID        PRIMARY KEY,
+    card_id     UUID        NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+    number      INT         NOT NULL,
+    author_id   UUID        NOT NULL REFERENCES users (id),
+    fields      TEXT[]      NOT NULL,
+    before      JSONB       NOT NULL,
+    after       JSONB       NOT NULL,
+    reverted_to INT,
+    created_at  TIMESTAMP   NOT NULL DEFAULT NOW(),
+    UNIQUE (card_id, number)
+);
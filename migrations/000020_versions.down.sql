@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS version;
+
+ALTER TABLE cards DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Viewport and tile queries compare locations as geometry: a geography box
+-- spanning all longitudes degenerates, as both of its edges are the same
+-- meridian.
+CREATE INDEX IF NOT EXISTS idx_cards_location_geometry ON cards USING GIST ((location::geometry));
@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations into the binaries, so they
+// can migrate the database without the source tree.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS